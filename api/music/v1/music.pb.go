@@ -0,0 +1,1158 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: music/v1/music.proto
+
+package musicv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Song struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	GroupName string                 `protobuf:"bytes,2,opt,name=group_name,json=groupName,proto3" json:"group_name,omitempty"`
+	SongName  string                 `protobuf:"bytes,3,opt,name=song_name,json=songName,proto3" json:"song_name,omitempty"`
+	// release date in ISO 8601 form: 2006-07-16, 2006-07 or 2006
+	ReleaseDate *string                `protobuf:"bytes,4,opt,name=release_date,json=releaseDate,proto3,oneof" json:"release_date,omitempty"`
+	Link        string                 `protobuf:"bytes,5,opt,name=link,proto3" json:"link,omitempty"`
+	Version     int32                  `protobuf:"varint,6,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt   *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// filled only when include_verses is requested
+	Verses        []*Verse `protobuf:"bytes,9,rep,name=verses,proto3" json:"verses,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Song) Reset() {
+	*x = Song{}
+	mi := &file_music_v1_music_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Song) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Song) ProtoMessage() {}
+
+func (x *Song) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Song.ProtoReflect.Descriptor instead.
+func (*Song) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Song) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Song) GetGroupName() string {
+	if x != nil {
+		return x.GroupName
+	}
+	return ""
+}
+
+func (x *Song) GetSongName() string {
+	if x != nil {
+		return x.SongName
+	}
+	return ""
+}
+
+func (x *Song) GetReleaseDate() string {
+	if x != nil && x.ReleaseDate != nil {
+		return *x.ReleaseDate
+	}
+	return ""
+}
+
+func (x *Song) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *Song) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Song) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Song) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Song) GetVerses() []*Verse {
+	if x != nil {
+		return x.Verses
+	}
+	return nil
+}
+
+type Verse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Number        int32                  `protobuf:"varint,1,opt,name=number,proto3" json:"number,omitempty"`
+	Text          string                 `protobuf:"bytes,2,opt,name=text,proto3" json:"text,omitempty"`
+	Version       int32                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Verse) Reset() {
+	*x = Verse{}
+	mi := &file_music_v1_music_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Verse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Verse) ProtoMessage() {}
+
+func (x *Verse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Verse.ProtoReflect.Descriptor instead.
+func (*Verse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Verse) GetNumber() int32 {
+	if x != nil {
+		return x.Number
+	}
+	return 0
+}
+
+func (x *Verse) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *Verse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Verse) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Verse) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type FetchSongsRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	GroupName *string                `protobuf:"bytes,1,opt,name=group_name,json=groupName,proto3,oneof" json:"group_name,omitempty"`
+	SongName  *string                `protobuf:"bytes,2,opt,name=song_name,json=songName,proto3,oneof" json:"song_name,omitempty"`
+	// part of song text
+	Text *string `protobuf:"bytes,3,opt,name=text,proto3,oneof" json:"text,omitempty"`
+	// exact release date: 2006-07-16
+	ReleaseDate  *string                `protobuf:"bytes,4,opt,name=release_date,json=releaseDate,proto3,oneof" json:"release_date,omitempty"`
+	UpdatedSince *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=updated_since,json=updatedSince,proto3" json:"updated_since,omitempty"`
+	// released on or after date, month or year: 2006-07-16, 2006-07 or 2006
+	ReleasedFrom *string `protobuf:"bytes,6,opt,name=released_from,json=releasedFrom,proto3,oneof" json:"released_from,omitempty"`
+	// released on or before date, month or year is included whole
+	ReleasedTo *string `protobuf:"bytes,7,opt,name=released_to,json=releasedTo,proto3,oneof" json:"released_to,omitempty"`
+	Year       *int32  `protobuf:"varint,8,opt,name=year,proto3,oneof" json:"year,omitempty"`
+	// first year of release decade: 1990
+	Decade *int32 `protobuf:"varint,9,opt,name=decade,proto3,oneof" json:"decade,omitempty"`
+	// sort fields, - for descending: id, group, song, releaseDate, createdAt, updatedAt
+	Sort []string `protobuf:"bytes,10,rep,name=sort,proto3" json:"sort,omitempty"`
+	// next_cursor of previous page, can't be combined with offset
+	Cursor        string `protobuf:"bytes,11,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	IncludeVerses bool   `protobuf:"varint,12,opt,name=include_verses,json=includeVerses,proto3" json:"include_verses,omitempty"`
+	Limit         uint64 `protobuf:"varint,13,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        uint64 `protobuf:"varint,14,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchSongsRequest) Reset() {
+	*x = FetchSongsRequest{}
+	mi := &file_music_v1_music_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchSongsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchSongsRequest) ProtoMessage() {}
+
+func (x *FetchSongsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchSongsRequest.ProtoReflect.Descriptor instead.
+func (*FetchSongsRequest) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *FetchSongsRequest) GetGroupName() string {
+	if x != nil && x.GroupName != nil {
+		return *x.GroupName
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetSongName() string {
+	if x != nil && x.SongName != nil {
+		return *x.SongName
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetText() string {
+	if x != nil && x.Text != nil {
+		return *x.Text
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetReleaseDate() string {
+	if x != nil && x.ReleaseDate != nil {
+		return *x.ReleaseDate
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetUpdatedSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedSince
+	}
+	return nil
+}
+
+func (x *FetchSongsRequest) GetReleasedFrom() string {
+	if x != nil && x.ReleasedFrom != nil {
+		return *x.ReleasedFrom
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetReleasedTo() string {
+	if x != nil && x.ReleasedTo != nil {
+		return *x.ReleasedTo
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetYear() int32 {
+	if x != nil && x.Year != nil {
+		return *x.Year
+	}
+	return 0
+}
+
+func (x *FetchSongsRequest) GetDecade() int32 {
+	if x != nil && x.Decade != nil {
+		return *x.Decade
+	}
+	return 0
+}
+
+func (x *FetchSongsRequest) GetSort() []string {
+	if x != nil {
+		return x.Sort
+	}
+	return nil
+}
+
+func (x *FetchSongsRequest) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *FetchSongsRequest) GetIncludeVerses() bool {
+	if x != nil {
+		return x.IncludeVerses
+	}
+	return false
+}
+
+func (x *FetchSongsRequest) GetLimit() uint64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FetchSongsRequest) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type FetchSongsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Songs         []*Song                `protobuf:"bytes,1,rep,name=songs,proto3" json:"songs,omitempty"`
+	TotalCount    int64                  `protobuf:"varint,2,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
+	NextCursor    string                 `protobuf:"bytes,3,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchSongsResponse) Reset() {
+	*x = FetchSongsResponse{}
+	mi := &file_music_v1_music_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchSongsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchSongsResponse) ProtoMessage() {}
+
+func (x *FetchSongsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchSongsResponse.ProtoReflect.Descriptor instead.
+func (*FetchSongsResponse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *FetchSongsResponse) GetSongs() []*Song {
+	if x != nil {
+		return x.Songs
+	}
+	return nil
+}
+
+func (x *FetchSongsResponse) GetTotalCount() int64 {
+	if x != nil {
+		return x.TotalCount
+	}
+	return 0
+}
+
+func (x *FetchSongsResponse) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type FetchVersesRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	SongId int64                  `protobuf:"varint,1,opt,name=song_id,json=songId,proto3" json:"song_id,omitempty"`
+	// max verses to stream, all verses when zero
+	Limit         int32 `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32 `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchVersesRequest) Reset() {
+	*x = FetchVersesRequest{}
+	mi := &file_music_v1_music_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchVersesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchVersesRequest) ProtoMessage() {}
+
+func (x *FetchVersesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchVersesRequest.ProtoReflect.Descriptor instead.
+func (*FetchVersesRequest) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *FetchVersesRequest) GetSongId() int64 {
+	if x != nil {
+		return x.SongId
+	}
+	return 0
+}
+
+func (x *FetchVersesRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *FetchVersesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type FetchVersesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Verse         *Verse                 `protobuf:"bytes,1,opt,name=verse,proto3" json:"verse,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FetchVersesResponse) Reset() {
+	*x = FetchVersesResponse{}
+	mi := &file_music_v1_music_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FetchVersesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FetchVersesResponse) ProtoMessage() {}
+
+func (x *FetchVersesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FetchVersesResponse.ProtoReflect.Descriptor instead.
+func (*FetchVersesResponse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *FetchVersesResponse) GetVerse() *Verse {
+	if x != nil {
+		return x.Verse
+	}
+	return nil
+}
+
+type NewSongRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupName     string                 `protobuf:"bytes,1,opt,name=group_name,json=groupName,proto3" json:"group_name,omitempty"`
+	SongName      string                 `protobuf:"bytes,2,opt,name=song_name,json=songName,proto3" json:"song_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewSongRequest) Reset() {
+	*x = NewSongRequest{}
+	mi := &file_music_v1_music_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewSongRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewSongRequest) ProtoMessage() {}
+
+func (x *NewSongRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewSongRequest.ProtoReflect.Descriptor instead.
+func (*NewSongRequest) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NewSongRequest) GetGroupName() string {
+	if x != nil {
+		return x.GroupName
+	}
+	return ""
+}
+
+func (x *NewSongRequest) GetSongName() string {
+	if x != nil {
+		return x.SongName
+	}
+	return ""
+}
+
+type NewSongResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Song          *Song                  `protobuf:"bytes,1,opt,name=song,proto3" json:"song,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NewSongResponse) Reset() {
+	*x = NewSongResponse{}
+	mi := &file_music_v1_music_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NewSongResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NewSongResponse) ProtoMessage() {}
+
+func (x *NewSongResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NewSongResponse.ProtoReflect.Descriptor instead.
+func (*NewSongResponse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NewSongResponse) GetSong() *Song {
+	if x != nil {
+		return x.Song
+	}
+	return nil
+}
+
+type UpdateSongRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SongId    int64                  `protobuf:"varint,1,opt,name=song_id,json=songId,proto3" json:"song_id,omitempty"`
+	GroupName *string                `protobuf:"bytes,2,opt,name=group_name,json=groupName,proto3,oneof" json:"group_name,omitempty"`
+	SongName  *string                `protobuf:"bytes,3,opt,name=song_name,json=songName,proto3,oneof" json:"song_name,omitempty"`
+	// release date in ISO 8601 form: 2006-07-16, 2006-07 or 2006
+	ReleaseDate *string `protobuf:"bytes,4,opt,name=release_date,json=releaseDate,proto3,oneof" json:"release_date,omitempty"`
+	Link        *string `protobuf:"bytes,5,opt,name=link,proto3,oneof" json:"link,omitempty"`
+	// expected song version, FAILED_PRECONDITION when song was changed
+	Version       *int32 `protobuf:"varint,6,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSongRequest) Reset() {
+	*x = UpdateSongRequest{}
+	mi := &file_music_v1_music_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSongRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSongRequest) ProtoMessage() {}
+
+func (x *UpdateSongRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSongRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSongRequest) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *UpdateSongRequest) GetSongId() int64 {
+	if x != nil {
+		return x.SongId
+	}
+	return 0
+}
+
+func (x *UpdateSongRequest) GetGroupName() string {
+	if x != nil && x.GroupName != nil {
+		return *x.GroupName
+	}
+	return ""
+}
+
+func (x *UpdateSongRequest) GetSongName() string {
+	if x != nil && x.SongName != nil {
+		return *x.SongName
+	}
+	return ""
+}
+
+func (x *UpdateSongRequest) GetReleaseDate() string {
+	if x != nil && x.ReleaseDate != nil {
+		return *x.ReleaseDate
+	}
+	return ""
+}
+
+func (x *UpdateSongRequest) GetLink() string {
+	if x != nil && x.Link != nil {
+		return *x.Link
+	}
+	return ""
+}
+
+func (x *UpdateSongRequest) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type UpdateSongResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSongResponse) Reset() {
+	*x = UpdateSongResponse{}
+	mi := &file_music_v1_music_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSongResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSongResponse) ProtoMessage() {}
+
+func (x *UpdateSongResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSongResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSongResponse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *UpdateSongResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type UpdateVerseRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SongId      int64                  `protobuf:"varint,1,opt,name=song_id,json=songId,proto3" json:"song_id,omitempty"`
+	VerseNumber int32                  `protobuf:"varint,2,opt,name=verse_number,json=verseNumber,proto3" json:"verse_number,omitempty"`
+	VerseText   string                 `protobuf:"bytes,3,opt,name=verse_text,json=verseText,proto3" json:"verse_text,omitempty"`
+	// expected verse version, FAILED_PRECONDITION when verse was changed
+	Version       *int32 `protobuf:"varint,4,opt,name=version,proto3,oneof" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateVerseRequest) Reset() {
+	*x = UpdateVerseRequest{}
+	mi := &file_music_v1_music_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateVerseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVerseRequest) ProtoMessage() {}
+
+func (x *UpdateVerseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVerseRequest.ProtoReflect.Descriptor instead.
+func (*UpdateVerseRequest) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *UpdateVerseRequest) GetSongId() int64 {
+	if x != nil {
+		return x.SongId
+	}
+	return 0
+}
+
+func (x *UpdateVerseRequest) GetVerseNumber() int32 {
+	if x != nil {
+		return x.VerseNumber
+	}
+	return 0
+}
+
+func (x *UpdateVerseRequest) GetVerseText() string {
+	if x != nil {
+		return x.VerseText
+	}
+	return ""
+}
+
+func (x *UpdateVerseRequest) GetVersion() int32 {
+	if x != nil && x.Version != nil {
+		return *x.Version
+	}
+	return 0
+}
+
+type UpdateVerseResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       int32                  `protobuf:"varint,1,opt,name=version,proto3" json:"version,omitempty"`
+	Verse         *Verse                 `protobuf:"bytes,2,opt,name=verse,proto3" json:"verse,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateVerseResponse) Reset() {
+	*x = UpdateVerseResponse{}
+	mi := &file_music_v1_music_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateVerseResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateVerseResponse) ProtoMessage() {}
+
+func (x *UpdateVerseResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateVerseResponse.ProtoReflect.Descriptor instead.
+func (*UpdateVerseResponse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *UpdateVerseResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateVerseResponse) GetVerse() *Verse {
+	if x != nil {
+		return x.Verse
+	}
+	return nil
+}
+
+type DeleteSongRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SongId        int64                  `protobuf:"varint,1,opt,name=song_id,json=songId,proto3" json:"song_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSongRequest) Reset() {
+	*x = DeleteSongRequest{}
+	mi := &file_music_v1_music_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSongRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSongRequest) ProtoMessage() {}
+
+func (x *DeleteSongRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSongRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSongRequest) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DeleteSongRequest) GetSongId() int64 {
+	if x != nil {
+		return x.SongId
+	}
+	return 0
+}
+
+type DeleteSongResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSongResponse) Reset() {
+	*x = DeleteSongResponse{}
+	mi := &file_music_v1_music_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSongResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSongResponse) ProtoMessage() {}
+
+func (x *DeleteSongResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_music_v1_music_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSongResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSongResponse) Descriptor() ([]byte, []int) {
+	return file_music_v1_music_proto_rawDescGZIP(), []int{13}
+}
+
+var File_music_v1_music_proto protoreflect.FileDescriptor
+
+const file_music_v1_music_proto_rawDesc = "" +
+	"\n" +
+	"\x14music/v1/music.proto\x12\bmusic.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd8\x02\n" +
+	"\x04Song\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1d\n" +
+	"\n" +
+	"group_name\x18\x02 \x01(\tR\tgroupName\x12\x1b\n" +
+	"\tsong_name\x18\x03 \x01(\tR\bsongName\x12&\n" +
+	"\frelease_date\x18\x04 \x01(\tH\x00R\vreleaseDate\x88\x01\x01\x12\x12\n" +
+	"\x04link\x18\x05 \x01(\tR\x04link\x12\x18\n" +
+	"\aversion\x18\x06 \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12'\n" +
+	"\x06verses\x18\t \x03(\v2\x0f.music.v1.VerseR\x06versesB\x0f\n" +
+	"\r_release_date\"\xc3\x01\n" +
+	"\x05Verse\x12\x16\n" +
+	"\x06number\x18\x01 \x01(\x05R\x06number\x12\x12\n" +
+	"\x04text\x18\x02 \x01(\tR\x04text\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x05R\aversion\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xcf\x04\n" +
+	"\x11FetchSongsRequest\x12\"\n" +
+	"\n" +
+	"group_name\x18\x01 \x01(\tH\x00R\tgroupName\x88\x01\x01\x12 \n" +
+	"\tsong_name\x18\x02 \x01(\tH\x01R\bsongName\x88\x01\x01\x12\x17\n" +
+	"\x04text\x18\x03 \x01(\tH\x02R\x04text\x88\x01\x01\x12&\n" +
+	"\frelease_date\x18\x04 \x01(\tH\x03R\vreleaseDate\x88\x01\x01\x12?\n" +
+	"\rupdated_since\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\fupdatedSince\x12(\n" +
+	"\rreleased_from\x18\x06 \x01(\tH\x04R\freleasedFrom\x88\x01\x01\x12$\n" +
+	"\vreleased_to\x18\a \x01(\tH\x05R\n" +
+	"releasedTo\x88\x01\x01\x12\x17\n" +
+	"\x04year\x18\b \x01(\x05H\x06R\x04year\x88\x01\x01\x12\x1b\n" +
+	"\x06decade\x18\t \x01(\x05H\aR\x06decade\x88\x01\x01\x12\x12\n" +
+	"\x04sort\x18\n" +
+	" \x03(\tR\x04sort\x12\x16\n" +
+	"\x06cursor\x18\v \x01(\tR\x06cursor\x12%\n" +
+	"\x0einclude_verses\x18\f \x01(\bR\rincludeVerses\x12\x14\n" +
+	"\x05limit\x18\r \x01(\x04R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x0e \x01(\x04R\x06offsetB\r\n" +
+	"\v_group_nameB\f\n" +
+	"\n" +
+	"_song_nameB\a\n" +
+	"\x05_textB\x0f\n" +
+	"\r_release_dateB\x10\n" +
+	"\x0e_released_fromB\x0e\n" +
+	"\f_released_toB\a\n" +
+	"\x05_yearB\t\n" +
+	"\a_decade\"|\n" +
+	"\x12FetchSongsResponse\x12$\n" +
+	"\x05songs\x18\x01 \x03(\v2\x0e.music.v1.SongR\x05songs\x12\x1f\n" +
+	"\vtotal_count\x18\x02 \x01(\x03R\n" +
+	"totalCount\x12\x1f\n" +
+	"\vnext_cursor\x18\x03 \x01(\tR\n" +
+	"nextCursor\"[\n" +
+	"\x12FetchVersesRequest\x12\x17\n" +
+	"\asong_id\x18\x01 \x01(\x03R\x06songId\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"<\n" +
+	"\x13FetchVersesResponse\x12%\n" +
+	"\x05verse\x18\x01 \x01(\v2\x0f.music.v1.VerseR\x05verse\"L\n" +
+	"\x0eNewSongRequest\x12\x1d\n" +
+	"\n" +
+	"group_name\x18\x01 \x01(\tR\tgroupName\x12\x1b\n" +
+	"\tsong_name\x18\x02 \x01(\tR\bsongName\"5\n" +
+	"\x0fNewSongResponse\x12\"\n" +
+	"\x04song\x18\x01 \x01(\v2\x0e.music.v1.SongR\x04song\"\x95\x02\n" +
+	"\x11UpdateSongRequest\x12\x17\n" +
+	"\asong_id\x18\x01 \x01(\x03R\x06songId\x12\"\n" +
+	"\n" +
+	"group_name\x18\x02 \x01(\tH\x00R\tgroupName\x88\x01\x01\x12 \n" +
+	"\tsong_name\x18\x03 \x01(\tH\x01R\bsongName\x88\x01\x01\x12&\n" +
+	"\frelease_date\x18\x04 \x01(\tH\x02R\vreleaseDate\x88\x01\x01\x12\x17\n" +
+	"\x04link\x18\x05 \x01(\tH\x03R\x04link\x88\x01\x01\x12\x1d\n" +
+	"\aversion\x18\x06 \x01(\x05H\x04R\aversion\x88\x01\x01B\r\n" +
+	"\v_group_nameB\f\n" +
+	"\n" +
+	"_song_nameB\x0f\n" +
+	"\r_release_dateB\a\n" +
+	"\x05_linkB\n" +
+	"\n" +
+	"\b_version\".\n" +
+	"\x12UpdateSongResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\"\x9a\x01\n" +
+	"\x12UpdateVerseRequest\x12\x17\n" +
+	"\asong_id\x18\x01 \x01(\x03R\x06songId\x12!\n" +
+	"\fverse_number\x18\x02 \x01(\x05R\vverseNumber\x12\x1d\n" +
+	"\n" +
+	"verse_text\x18\x03 \x01(\tR\tverseText\x12\x1d\n" +
+	"\aversion\x18\x04 \x01(\x05H\x00R\aversion\x88\x01\x01B\n" +
+	"\n" +
+	"\b_version\"V\n" +
+	"\x13UpdateVerseResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\x05R\aversion\x12%\n" +
+	"\x05verse\x18\x02 \x01(\v2\x0f.music.v1.VerseR\x05verse\",\n" +
+	"\x11DeleteSongRequest\x12\x17\n" +
+	"\asong_id\x18\x01 \x01(\x03R\x06songId\"\x14\n" +
+	"\x12DeleteSongResponse2\xc3\x03\n" +
+	"\fMusicService\x12G\n" +
+	"\n" +
+	"FetchSongs\x12\x1b.music.v1.FetchSongsRequest\x1a\x1c.music.v1.FetchSongsResponse\x12L\n" +
+	"\vFetchVerses\x12\x1c.music.v1.FetchVersesRequest\x1a\x1d.music.v1.FetchVersesResponse0\x01\x12>\n" +
+	"\aNewSong\x12\x18.music.v1.NewSongRequest\x1a\x19.music.v1.NewSongResponse\x12G\n" +
+	"\n" +
+	"UpdateSong\x12\x1b.music.v1.UpdateSongRequest\x1a\x1c.music.v1.UpdateSongResponse\x12J\n" +
+	"\vUpdateVerse\x12\x1c.music.v1.UpdateVerseRequest\x1a\x1d.music.v1.UpdateVerseResponse\x12G\n" +
+	"\n" +
+	"DeleteSong\x12\x1b.music.v1.DeleteSongRequest\x1a\x1c.music.v1.DeleteSongResponseB:Z8github.com/Vic07Region/musicLibrary/api/music/v1;musicv1b\x06proto3"
+
+var (
+	file_music_v1_music_proto_rawDescOnce sync.Once
+	file_music_v1_music_proto_rawDescData []byte
+)
+
+func file_music_v1_music_proto_rawDescGZIP() []byte {
+	file_music_v1_music_proto_rawDescOnce.Do(func() {
+		file_music_v1_music_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_music_v1_music_proto_rawDesc), len(file_music_v1_music_proto_rawDesc)))
+	})
+	return file_music_v1_music_proto_rawDescData
+}
+
+var file_music_v1_music_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_music_v1_music_proto_goTypes = []any{
+	(*Song)(nil),                  // 0: music.v1.Song
+	(*Verse)(nil),                 // 1: music.v1.Verse
+	(*FetchSongsRequest)(nil),     // 2: music.v1.FetchSongsRequest
+	(*FetchSongsResponse)(nil),    // 3: music.v1.FetchSongsResponse
+	(*FetchVersesRequest)(nil),    // 4: music.v1.FetchVersesRequest
+	(*FetchVersesResponse)(nil),   // 5: music.v1.FetchVersesResponse
+	(*NewSongRequest)(nil),        // 6: music.v1.NewSongRequest
+	(*NewSongResponse)(nil),       // 7: music.v1.NewSongResponse
+	(*UpdateSongRequest)(nil),     // 8: music.v1.UpdateSongRequest
+	(*UpdateSongResponse)(nil),    // 9: music.v1.UpdateSongResponse
+	(*UpdateVerseRequest)(nil),    // 10: music.v1.UpdateVerseRequest
+	(*UpdateVerseResponse)(nil),   // 11: music.v1.UpdateVerseResponse
+	(*DeleteSongRequest)(nil),     // 12: music.v1.DeleteSongRequest
+	(*DeleteSongResponse)(nil),    // 13: music.v1.DeleteSongResponse
+	(*timestamppb.Timestamp)(nil), // 14: google.protobuf.Timestamp
+}
+var file_music_v1_music_proto_depIdxs = []int32{
+	14, // 0: music.v1.Song.created_at:type_name -> google.protobuf.Timestamp
+	14, // 1: music.v1.Song.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 2: music.v1.Song.verses:type_name -> music.v1.Verse
+	14, // 3: music.v1.Verse.created_at:type_name -> google.protobuf.Timestamp
+	14, // 4: music.v1.Verse.updated_at:type_name -> google.protobuf.Timestamp
+	14, // 5: music.v1.FetchSongsRequest.updated_since:type_name -> google.protobuf.Timestamp
+	0,  // 6: music.v1.FetchSongsResponse.songs:type_name -> music.v1.Song
+	1,  // 7: music.v1.FetchVersesResponse.verse:type_name -> music.v1.Verse
+	0,  // 8: music.v1.NewSongResponse.song:type_name -> music.v1.Song
+	1,  // 9: music.v1.UpdateVerseResponse.verse:type_name -> music.v1.Verse
+	2,  // 10: music.v1.MusicService.FetchSongs:input_type -> music.v1.FetchSongsRequest
+	4,  // 11: music.v1.MusicService.FetchVerses:input_type -> music.v1.FetchVersesRequest
+	6,  // 12: music.v1.MusicService.NewSong:input_type -> music.v1.NewSongRequest
+	8,  // 13: music.v1.MusicService.UpdateSong:input_type -> music.v1.UpdateSongRequest
+	10, // 14: music.v1.MusicService.UpdateVerse:input_type -> music.v1.UpdateVerseRequest
+	12, // 15: music.v1.MusicService.DeleteSong:input_type -> music.v1.DeleteSongRequest
+	3,  // 16: music.v1.MusicService.FetchSongs:output_type -> music.v1.FetchSongsResponse
+	5,  // 17: music.v1.MusicService.FetchVerses:output_type -> music.v1.FetchVersesResponse
+	7,  // 18: music.v1.MusicService.NewSong:output_type -> music.v1.NewSongResponse
+	9,  // 19: music.v1.MusicService.UpdateSong:output_type -> music.v1.UpdateSongResponse
+	11, // 20: music.v1.MusicService.UpdateVerse:output_type -> music.v1.UpdateVerseResponse
+	13, // 21: music.v1.MusicService.DeleteSong:output_type -> music.v1.DeleteSongResponse
+	16, // [16:22] is the sub-list for method output_type
+	10, // [10:16] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_music_v1_music_proto_init() }
+func file_music_v1_music_proto_init() {
+	if File_music_v1_music_proto != nil {
+		return
+	}
+	file_music_v1_music_proto_msgTypes[0].OneofWrappers = []any{}
+	file_music_v1_music_proto_msgTypes[2].OneofWrappers = []any{}
+	file_music_v1_music_proto_msgTypes[8].OneofWrappers = []any{}
+	file_music_v1_music_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_music_v1_music_proto_rawDesc), len(file_music_v1_music_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_music_v1_music_proto_goTypes,
+		DependencyIndexes: file_music_v1_music_proto_depIdxs,
+		MessageInfos:      file_music_v1_music_proto_msgTypes,
+	}.Build()
+	File_music_v1_music_proto = out.File
+	file_music_v1_music_proto_goTypes = nil
+	file_music_v1_music_proto_depIdxs = nil
+}
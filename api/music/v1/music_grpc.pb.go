@@ -0,0 +1,335 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: music/v1/music.proto
+
+package musicv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	MusicService_FetchSongs_FullMethodName  = "/music.v1.MusicService/FetchSongs"
+	MusicService_FetchVerses_FullMethodName = "/music.v1.MusicService/FetchVerses"
+	MusicService_NewSong_FullMethodName     = "/music.v1.MusicService/NewSong"
+	MusicService_UpdateSong_FullMethodName  = "/music.v1.MusicService/UpdateSong"
+	MusicService_UpdateVerse_FullMethodName = "/music.v1.MusicService/UpdateVerse"
+	MusicService_DeleteSong_FullMethodName  = "/music.v1.MusicService/DeleteSong"
+)
+
+// MusicServiceClient is the client API for MusicService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// MusicService mirrors song library operations of the http api.
+// Errors are returned with grpc status codes, validation errors
+// carry google.rpc.BadRequest details with failed fields
+type MusicServiceClient interface {
+	// FetchSongs returns one page of the filtered song list
+	FetchSongs(ctx context.Context, in *FetchSongsRequest, opts ...grpc.CallOption) (*FetchSongsResponse, error)
+	// FetchVerses streams song verses ordered by number
+	FetchVerses(ctx context.Context, in *FetchVersesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FetchVersesResponse], error)
+	// NewSong creates song with details from song info provider
+	NewSong(ctx context.Context, in *NewSongRequest, opts ...grpc.CallOption) (*NewSongResponse, error)
+	// UpdateSong changes song attributes, only set fields are changed
+	UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*UpdateSongResponse, error)
+	// UpdateVerse changes text of one verse
+	UpdateVerse(ctx context.Context, in *UpdateVerseRequest, opts ...grpc.CallOption) (*UpdateVerseResponse, error)
+	// DeleteSong deletes song with its verses
+	DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*DeleteSongResponse, error)
+}
+
+type musicServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMusicServiceClient(cc grpc.ClientConnInterface) MusicServiceClient {
+	return &musicServiceClient{cc}
+}
+
+func (c *musicServiceClient) FetchSongs(ctx context.Context, in *FetchSongsRequest, opts ...grpc.CallOption) (*FetchSongsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FetchSongsResponse)
+	err := c.cc.Invoke(ctx, MusicService_FetchSongs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) FetchVerses(ctx context.Context, in *FetchVersesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[FetchVersesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MusicService_ServiceDesc.Streams[0], MusicService_FetchVerses_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[FetchVersesRequest, FetchVersesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MusicService_FetchVersesClient = grpc.ServerStreamingClient[FetchVersesResponse]
+
+func (c *musicServiceClient) NewSong(ctx context.Context, in *NewSongRequest, opts ...grpc.CallOption) (*NewSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NewSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_NewSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) UpdateSong(ctx context.Context, in *UpdateSongRequest, opts ...grpc.CallOption) (*UpdateSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_UpdateSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) UpdateVerse(ctx context.Context, in *UpdateVerseRequest, opts ...grpc.CallOption) (*UpdateVerseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateVerseResponse)
+	err := c.cc.Invoke(ctx, MusicService_UpdateVerse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *musicServiceClient) DeleteSong(ctx context.Context, in *DeleteSongRequest, opts ...grpc.CallOption) (*DeleteSongResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSongResponse)
+	err := c.cc.Invoke(ctx, MusicService_DeleteSong_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// MusicServiceServer is the server API for MusicService service.
+// All implementations must embed UnimplementedMusicServiceServer
+// for forward compatibility.
+//
+// MusicService mirrors song library operations of the http api.
+// Errors are returned with grpc status codes, validation errors
+// carry google.rpc.BadRequest details with failed fields
+type MusicServiceServer interface {
+	// FetchSongs returns one page of the filtered song list
+	FetchSongs(context.Context, *FetchSongsRequest) (*FetchSongsResponse, error)
+	// FetchVerses streams song verses ordered by number
+	FetchVerses(*FetchVersesRequest, grpc.ServerStreamingServer[FetchVersesResponse]) error
+	// NewSong creates song with details from song info provider
+	NewSong(context.Context, *NewSongRequest) (*NewSongResponse, error)
+	// UpdateSong changes song attributes, only set fields are changed
+	UpdateSong(context.Context, *UpdateSongRequest) (*UpdateSongResponse, error)
+	// UpdateVerse changes text of one verse
+	UpdateVerse(context.Context, *UpdateVerseRequest) (*UpdateVerseResponse, error)
+	// DeleteSong deletes song with its verses
+	DeleteSong(context.Context, *DeleteSongRequest) (*DeleteSongResponse, error)
+	mustEmbedUnimplementedMusicServiceServer()
+}
+
+// UnimplementedMusicServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMusicServiceServer struct{}
+
+func (UnimplementedMusicServiceServer) FetchSongs(context.Context, *FetchSongsRequest) (*FetchSongsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchSongs not implemented")
+}
+func (UnimplementedMusicServiceServer) FetchVerses(*FetchVersesRequest, grpc.ServerStreamingServer[FetchVersesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method FetchVerses not implemented")
+}
+func (UnimplementedMusicServiceServer) NewSong(context.Context, *NewSongRequest) (*NewSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method NewSong not implemented")
+}
+func (UnimplementedMusicServiceServer) UpdateSong(context.Context, *UpdateSongRequest) (*UpdateSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSong not implemented")
+}
+func (UnimplementedMusicServiceServer) UpdateVerse(context.Context, *UpdateVerseRequest) (*UpdateVerseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateVerse not implemented")
+}
+func (UnimplementedMusicServiceServer) DeleteSong(context.Context, *DeleteSongRequest) (*DeleteSongResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSong not implemented")
+}
+func (UnimplementedMusicServiceServer) mustEmbedUnimplementedMusicServiceServer() {}
+func (UnimplementedMusicServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeMusicServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MusicServiceServer will
+// result in compilation errors.
+type UnsafeMusicServiceServer interface {
+	mustEmbedUnimplementedMusicServiceServer()
+}
+
+func RegisterMusicServiceServer(s grpc.ServiceRegistrar, srv MusicServiceServer) {
+	// If the following call pancis, it indicates UnimplementedMusicServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&MusicService_ServiceDesc, srv)
+}
+
+func _MusicService_FetchSongs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchSongsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).FetchSongs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_FetchSongs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).FetchSongs(ctx, req.(*FetchSongsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_FetchVerses_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(FetchVersesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MusicServiceServer).FetchVerses(m, &grpc.GenericServerStream[FetchVersesRequest, FetchVersesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MusicService_FetchVersesServer = grpc.ServerStreamingServer[FetchVersesResponse]
+
+func _MusicService_NewSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NewSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).NewSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_NewSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).NewSong(ctx, req.(*NewSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_UpdateSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).UpdateSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_UpdateSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).UpdateSong(ctx, req.(*UpdateSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_UpdateVerse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateVerseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).UpdateVerse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_UpdateVerse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).UpdateVerse(ctx, req.(*UpdateVerseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MusicService_DeleteSong_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSongRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MusicServiceServer).DeleteSong(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MusicService_DeleteSong_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MusicServiceServer).DeleteSong(ctx, req.(*DeleteSongRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// MusicService_ServiceDesc is the grpc.ServiceDesc for MusicService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MusicService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "music.v1.MusicService",
+	HandlerType: (*MusicServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "FetchSongs",
+			Handler:    _MusicService_FetchSongs_Handler,
+		},
+		{
+			MethodName: "NewSong",
+			Handler:    _MusicService_NewSong_Handler,
+		},
+		{
+			MethodName: "UpdateSong",
+			Handler:    _MusicService_UpdateSong_Handler,
+		},
+		{
+			MethodName: "UpdateVerse",
+			Handler:    _MusicService_UpdateVerse_Handler,
+		},
+		{
+			MethodName: "DeleteSong",
+			Handler:    _MusicService_DeleteSong_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "FetchVerses",
+			Handler:       _MusicService_FetchVerses_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "music/v1/music.proto",
+}
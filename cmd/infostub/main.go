@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/infostub"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+)
+
+func main() {
+	addr := flag.String("addr", ":8081", "listen address")
+	fixtures := flag.String("fixtures", "./fixtures/songinfo", "directory with fixture json files")
+	latency := flag.Duration("latency", 0, "delay before every response")
+	jitter := flag.Duration("jitter", 0, "random extra delay up to this value")
+	errorRate := flag.Float64("error-rate", 0, "share of requests answered with 500 (0..1)")
+	flag.Parse()
+
+	l := logger.New()
+	//init stub
+	stub, err := infostub.New(infostub.Config{
+		FixturesDir: *fixtures,
+		Latency:     *latency,
+		Jitter:      *jitter,
+		ErrorRate:   *errorRate,
+	}, l)
+	if err != nil {
+		log.Fatal(err)
+	}
+	//run stub
+	l.Info("Start song info stub", "host", *addr)
+	if err := stub.Router().Run(*addr); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -3,14 +3,32 @@ package main
 import (
 	"github.com/Vic07Region/musicLibrary/internal/pkg/app"
 	"log"
+	"os"
 )
 
+// @title			Music Library API
+// @version		1.0
+// @description	Онлайн библиотека песен
+// @BasePath		/api
+//
+// @securityDefinitions.apikey	ApiKeyAuth
+// @in							header
+// @name						X-API-Key
+// @description				api key, Authorization: Bearer <key> header is accepted too
 func main() {
 	//init app
 	a, err := app.New()
 	if err != nil {
 		log.Fatal(err)
 	}
+	//run cli command
+	if len(os.Args) > 1 {
+		err = a.RunCommand(os.Args[1:])
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	//run app
 	err = a.Run()
 	if err != nil {
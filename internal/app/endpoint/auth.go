@@ -0,0 +1,72 @@
+package endpoint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	apiKeyHeader = "X-API-Key"
+
+	// APIKeyIDKey is gin context key of authenticated api key id, used in log lines
+	APIKeyIDKey = "api_key_id"
+)
+
+// RequireScope authenticates request by api key sent as Authorization: Bearer <key>
+// or X-API-Key header and rejects keys without scope. Authenticated key is put
+// to request context for handlers authorizing single operations
+func (e *Endpoint) RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(apiKeyHeader)
+		if bearer, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer "); ok {
+			token = strings.TrimSpace(bearer)
+		}
+		if token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="musicLibrary"`)
+			e.writeError(c, service.ErrUnauthorized)
+			return
+		}
+
+		key, err := e.s.Authenticate(c.Request.Context(), token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="musicLibrary"`)
+			e.writeError(c, err)
+			return
+		}
+		c.Set(APIKeyIDKey, key.ID)
+
+		if !key.HasScope(scope) {
+			e.log.Warn("endpoint.RequireScope | scope denied",
+				"key_id", key.ID,
+				"scope", scope,
+				"method", c.Request.Method,
+				"path", c.Request.URL.Path)
+			e.writeError(c, service.ErrForbidden)
+			return
+		}
+
+		c.Request = c.Request.WithContext(service.WithAPIKey(c.Request.Context(), key))
+		c.Next()
+	}
+}
+
+// AccessLog formats gin access log line like gin default logger with id of api key
+func AccessLog(param gin.LogFormatterParams) string {
+	keyID, _ := param.Keys[APIKeyIDKey].(string)
+	if keyID == "" {
+		keyID = "-"
+	}
+	return fmt.Sprintf("[GIN] %v | %3d | %13v | %15s | %-7s %#v | key_id=%s\n%s",
+		param.TimeStamp.Format("2006/01/02 - 15:04:05"),
+		param.StatusCode,
+		param.Latency,
+		param.ClientIP,
+		param.Method,
+		param.Path,
+		keyID,
+		param.ErrorMessage,
+	)
+}
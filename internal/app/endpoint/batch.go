@@ -0,0 +1,138 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Batch changes
+// @Schemes
+// @Description runs operations (create_song, update_song, update_verse, delete_song) in one transaction, all of them are applied or none. When operation is rejected batch is answered with 422 and results show failed, rolled back and skipped operations
+// @Tags Songs
+// @Accept json
+// @Produce json
+// @Param request body endpoint.BatchRequest true "operations"
+// @Success 200 {object} service.BatchResponse
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      422  {object}  service.BatchResponse
+// @Failure      500  {object}  problem.Problem
+// @Failure      503  {object}  problem.Problem
+// @Failure      504  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/batch [post]
+func (e *Endpoint) BatchHandler(c *gin.Context) {
+	var batchData BatchRequest
+	if err := c.ShouldBindJSON(&batchData); err != nil {
+		e.writeError(c, badRequest(err))
+		return
+	}
+
+	request := service.BatchRequest{Operations: make([]service.BatchOperation, 0, len(batchData.Operations))}
+	var fields []problem.FieldError
+	for i, op := range batchData.Operations {
+		operation, opFields := batchOperation(op)
+		for _, field := range opFields {
+			field.Field = fmt.Sprintf("operations[%d].%s", i, field.Field)
+			fields = append(fields, field)
+		}
+		request.Operations = append(request.Operations, operation)
+	}
+	if len(fields) > 0 {
+		e.writeError(c, problem.Validation(fields...))
+		return
+	}
+
+	resp, err := e.s.Batch(c.Request.Context(), request)
+	if err != nil {
+		//rejected operation is reported in results, server failures are answered with problem
+		if resp != nil && StatusCode(err) < http.StatusInternalServerError {
+			for i, result := range resp.Results {
+				if result.Err != nil {
+					p := problemOf(result.Err, "")
+					resp.Results[i].Error = &p
+				}
+			}
+			c.JSON(http.StatusUnprocessableEntity, resp)
+			return
+		}
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// batchOperation converts operation to service request, returns details of invalid fields
+func batchOperation(op BatchOperation) (service.BatchOperation, []problem.FieldError) {
+	operation := service.BatchOperation{Op: op.Op}
+	var fields []problem.FieldError
+
+	if op.Op != service.BatchCreateSong && op.SongID < 1 {
+		fields = append(fields, problem.FieldError{Field: "songId", Message: "songId must be a positive integer"})
+	}
+
+	var releaseDate *dateparse.Date
+	if op.ReleaseDate != nil && *op.ReleaseDate != "" {
+		date, err := dateparse.Parse(*op.ReleaseDate)
+		if err != nil {
+			fields = append(fields, problem.FieldError{Field: "releaseDate",
+				Message: "Invalid date format. Use (DD.MM.YYYY), (YYYY-MM-DD), (MM.YYYY) or (YYYY)"})
+		} else {
+			releaseDate = &date
+		}
+	}
+
+	switch op.Op {
+	case service.BatchCreateSong:
+		request := &service.CreateSongRequest{
+			ReleaseDate: releaseDate,
+			Text:        op.Text,
+			Verses:      op.Verses,
+		}
+		if op.Group != nil {
+			request.GroupName = *op.Group
+		}
+		if op.Song != nil {
+			request.SongName = *op.Song
+		}
+		if op.Link != nil {
+			request.Link = *op.Link
+		}
+		operation.CreateSong = request
+	case service.BatchUpdateSong:
+		operation.UpdateSong = &service.UpdateSongRequest{
+			SongID:      op.SongID,
+			GroupName:   op.Group,
+			SongName:    op.Song,
+			ReleaseDate: releaseDate,
+			Link:        op.Link,
+			Version:     op.Version,
+		}
+	case service.BatchUpdateVerse:
+		if op.VerseNumber < 1 {
+			fields = append(fields, problem.FieldError{Field: "verseNumber", Message: "verseNumber must be a positive integer"})
+		}
+		if op.VerseText == nil {
+			fields = append(fields, problem.FieldError{Field: "verseText", Message: "verseText is required"})
+		}
+		request := &service.UpdateVerseRequest{
+			SongID:      op.SongID,
+			VerseNumber: op.VerseNumber,
+			Version:     op.Version,
+		}
+		if op.VerseText != nil {
+			request.VerseText = *op.VerseText
+		}
+		operation.UpdateVerse = request
+	case service.BatchDeleteSong:
+		operation.DeleteSong = &service.DeleteSongRequest{SongID: op.SongID}
+	}
+
+	return operation, fields
+}
@@ -1,12 +1,12 @@
 package endpoint
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
 	"time" //nolint:gci
 
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
 	"github.com/Vic07Region/musicLibrary/internal/lib/logger" //nolint:gci
 	"github.com/Vic07Region/musicLibrary/internal/service"
 	"github.com/gin-gonic/gin"
@@ -14,99 +14,111 @@ import (
 )
 
 type Endpoint struct {
-	s   service.MusicService
-	log *logger.Logger
+	s        service.MusicService
+	log      *logger.Logger
+	validate *validator.Validate
 }
 
 func New(s service.MusicService, log *logger.Logger) *Endpoint {
 	return &Endpoint{
-		s:   s,
-		log: log,
+		s:        s,
+		log:      log,
+		validate: newValidator(),
 	}
 }
 
-// @BasePath /api/v1
+// @BasePath /api
 
 // @Summary List songs
 // @Schemes
-// @Description fetching song list
+// @Description fetching song list, answers 304 when If-None-Match matches ETag of the page
 // @Param   group      query     string     false  "group name"	example(Muse)
 // @Param   song      query     string     false  "song name"	example(Supermassive Black Hole)
+// @Param   text      query     string     false  "part of song text"	example(baby)
+// @Param   releaseDate      query     string     false  "release date"	example(16.07.2006)
+// @Param   updated_since      query     string     false  "songs changed since time (RFC 3339)"	example(2024-07-03T10:00:00Z)
+// @Param   released_from      query     string     false  "released on or after date, day, month or year"	example(2006)
+// @Param   released_to      query     string     false  "released on or before date, month or year is included whole"	example(12.2009)
+// @Param   year      query     int     false  "release year"	example(2006)
+// @Param   decade      query     int     false  "first year of release decade"	example(2000)
+// @Param        If-None-Match   header      string  false  "ETag of cached page"
+// @Param   sort      query     string     false  "comma separated sort fields, - for descending: id, group, song, releaseDate, createdAt, updatedAt"	example(group,-releaseDate)
 // @Param   limit      query     int     false  "items limit"	example(10)
 // @Param   offset      query     int     false "offset items"	example(2)
+// @Param   cursor      query     string     false "next_cursor of previous page, can't be combined with offset"
+// @Param   include      query     string     false "verses to embed song lyrics"	example(verses)
+// @Param   fields      query     string     false "comma separated song attributes to return, id is always returned"	example(group,song)
 // @Tags Songs
 // @Accept json
 // @Produce json
-// @Success 200 {array} endpoint.Song
-// @Failure      400  {object}  endpoint.MessageError
-// @Failure      500
-// @Router /songs [get]
+// @Success 200 {object} service.FetchSongsResponse
+// @Header 200 {string} ETag "page entity tag"
+// @Header 200 {string} Link "pagination links: self, first, prev, next, last (RFC 8288)"
+// @Success 304 "Not Modified"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs [get]
 func (e *Endpoint) FetchSongsHandler(c *gin.Context) {
-	var fetchParams service.FetchSongsRequest
-
-	if val, ok := c.GetQuery("group"); ok {
-		fetchParams.GroupName = &val
-	}
-
-	if val, ok := c.GetQuery("song"); ok {
-		fetchParams.SongName = &val
-	}
-
-	if val, ok := c.GetQuery("text"); ok {
-		fetchParams.SongText = &val
+	fetchParams, fields, err := songListRequest(c, false, songListFields)
+	if err != nil {
+		e.writeError(c, err)
+		return
 	}
 
-	if val, ok := c.GetQuery("releaseDate"); ok {
-		rd, err := time.Parse(val, "02.01.2006")
-		if err != nil {
-			c.JSON(http.StatusBadRequest, MessageError{"wrong format releaseDate: example 02.01.2006 "})
-		}
-		fetchParams.ReleaseDate = &rd
+	songsResp, err := e.s.FetchSongs(c.Request.Context(), fetchParams)
+	if err != nil {
+		e.writeError(c, err)
+		return
 	}
 
-	if val, ok := c.GetQuery("offset"); ok {
-		if intval, err := strconv.Atoi(val); err == nil {
-			fetchParams.Offset = uint64(intval)
-		}
-
+	if notModified(c, listETag(songsResp), time.Time{}) {
+		return
 	}
 
-	if val, ok := c.GetQuery("limit"); ok {
-		if intval, err := strconv.Atoi(val); err == nil {
-			fetchParams.Limit = uint64(intval)
-		}
+	songListLinks(c.Request.URL, fetchParams, songsResp)
+	setLinkHeader(c, songsResp.Links)
 
-	}
-	songsResp, err := e.s.FetchSongs(c.Request.Context(), fetchParams)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
+	if fields != nil {
+		c.JSON(http.StatusOK, trimSongs(songsResp, fields))
 		return
 	}
-
 	c.JSON(http.StatusOK, songsResp)
 }
 
 // @Summary Song text
 // @Schemes
-// @Description fetching song text
+// @Description fetching song text, ETag header holds song version for If-Match of PATCH requests, answers 304 when If-None-Match or If-Modified-Since show that song was not changed
 // @Param        id   path      int  true  "Song ID"
+// @Param        If-None-Match   header      string  false  "cached song ETag"
+// @Param        If-Modified-Since   header      string  false  "cached song Last-Modified"
 // @Param   limit      query     int     false  "items limit"	example(10)
 // @Param   offset      query     int     false "offset items"	example(2)
 // @Tags Songs
 // @Accept json
 // @Produce json
 // @Success 200 {object} service.FetchVersesResponse
-// @Failure      400  {object}  endpoint.MessageError
-// @Failure      404  {object}  endpoint.MessageError
-// @Failure      500
-// @Router /songs/{id} [get]
+// @Header 200 {string} ETag "song version"
+// @Header 200 {string} Last-Modified "song update time"
+// @Header 200 {string} Link "verse pagination links: self, first, prev, next, last (RFC 8288)"
+// @Success 304 "Not Modified"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/{id} [get]
 func (e *Endpoint) FetchSongTextHandler(c *gin.Context) {
 	var fetchParams service.FetchVersesRequest
 
 	paramID := c.Param("id")
 	songId, err := strconv.Atoi(paramID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{"wrong format id"})
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
 	}
 
 	fetchParams.SongID = songId
@@ -127,10 +139,16 @@ func (e *Endpoint) FetchSongTextHandler(c *gin.Context) {
 
 	verseResp, err := e.s.FetchVerses(c.Request.Context(), fetchParams)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
+		e.writeError(c, err)
+		return
+	}
+
+	if notModified(c, etag(verseResp.Version), verseResp.UpdatedAt) {
 		return
 	}
 
+	verseListLinks(c.Request.URL, fetchParams, verseResp)
+	setLinkHeader(c, verseResp.Links)
 	c.JSON(http.StatusOK, verseResp)
 }
 
@@ -142,24 +160,24 @@ func (e *Endpoint) FetchSongTextHandler(c *gin.Context) {
 // @Produce json
 // @Param        id   path      int  true  "Song ID"
 // @Success 	 200  {object}  service.DeleteSongResponse
-// @Failure      400  {object}  endpoint.MessageError
-// @Failure      404  {object}  endpoint.MessageError
-// @Failure      500
-// @Router /songs/{id} [delete]
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/{id} [delete]
 func (e *Endpoint) DeleteSongHandler(c *gin.Context) {
 	paramID := c.Param("id")
 
 	songID, err := strconv.Atoi(paramID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{"wrong format id"})
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
 	}
 	resp, err := e.s.DeleteSong(c.Request.Context(), service.DeleteSongRequest{SongID: songID})
 	if err != nil {
-		if errors.Is(err, service.ErrSongNotFound) {
-			c.JSON(http.StatusNotFound, MessageError{err.Error()})
-			return
-		}
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
+		e.writeError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, resp)
@@ -168,31 +186,44 @@ func (e *Endpoint) DeleteSongHandler(c *gin.Context) {
 
 // @Summary Edit Song
 // @Schemes
-// @Description edit song
+// @Description edit song, with If-Match header song is changed only when its version equals ETag, otherwise 412 with current song is returned
 // @Tags Songs
 // @Accept json
 // @Produce json
 // @Param        id   path      int  true  "Song ID"
+// @Param        If-Match   header      string  false  "song ETag"
 // @Param request body endpoint.UpdateSong true "query params"
 // @Success 200 {object} service.UpdateSongResponse
-// @Failure      400  {object}  endpoint.MessageError
-// @Failure      404  {object}  endpoint.MessageError
-// @Failure      500
-// @Router /songs/{id} [patch]
+// @Header 200 {string} ETag "new song version"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      412  {object}  endpoint.Song
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/{id} [patch]
 func (e *Endpoint) UpdateSongHandler(c *gin.Context) {
 
 	paramID := c.Param("id")
 	songID, err := strconv.Atoi(paramID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{"wrong format id"})
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
 	}
 
 	var request service.UpdateSongRequest
 	request.SongID = songID
 
+	request.Version, err = ifMatchVersion(c)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
 	var inputData map[string]interface{}
 	if err := c.ShouldBindJSON(&inputData); err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
+		e.writeError(c, badRequest(err))
 		return
 	}
 
@@ -203,16 +234,8 @@ func (e *Endpoint) UpdateSongHandler(c *gin.Context) {
 		"link":        true,
 	}
 
-	var wrongKey []string
-
-	for key := range inputData {
-		if _, ok := allowedKeys[key]; !ok {
-			wrongKey = append(wrongKey, key)
-		}
-	}
-
-	if len(wrongKey) > 0 {
-		c.JSON(http.StatusBadRequest, MessageError{fmt.Sprintf("invalid fields %s", wrongKey)})
+	if err := unknownFields(inputData, allowedKeys); err != nil {
+		e.writeError(c, err)
 		return
 	}
 
@@ -225,9 +248,9 @@ func (e *Endpoint) UpdateSongHandler(c *gin.Context) {
 	}
 
 	if releaseDateStr, ok := inputData["releaseDate"].(string); ok {
-		releaseDate, err := time.Parse("02.01.2006", releaseDateStr)
+		releaseDate, err := dateparse.Parse(releaseDateStr)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, MessageError{"Invalid date format. Use (DD.MM.YYYY)"})
+			e.writeError(c, invalidField("releaseDate", "Invalid date format. Use (DD.MM.YYYY), (YYYY-MM-DD), (MM.YYYY) or (YYYY)"))
 			return
 		}
 		request.ReleaseDate = &releaseDate
@@ -239,47 +262,56 @@ func (e *Endpoint) UpdateSongHandler(c *gin.Context) {
 
 	resp, err := e.s.UpdateSong(c.Request.Context(), request)
 	if err != nil {
-		if errors.Is(err, service.ErrSongNotFound) {
-			c.JSON(http.StatusNotFound, MessageError{err.Error()})
-			return
-		}
-		if errors.Is(err, service.ErrGroupNotFound) {
-			c.JSON(http.StatusNotFound, MessageError{err.Error()})
-			return
+		if !e.writeVersionMismatch(c, err, v1Representation) {
+			e.writeError(c, err)
 		}
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
 		return
 	}
+	c.Header("ETag", etag(resp.Version))
 	c.JSON(http.StatusOK, resp)
 }
 
 // @Summary Edit Song Verse
 // @Schemes
-// @Description edit song verse
+// @Description edit song verse, with If-Match header verse is changed only when song version equals ETag of song text, otherwise 412 with current song is returned
 // @Tags Songs
 // @Accept json
 // @Produce json
 // @Param        id   path      int  true  "Song ID"
+// @Param        If-Match   header      string  false  "song text ETag"
 // @Param request body endpoint.UpdateVerseRequest true "query params"
 // @Success 200 {object} service.UpdateVerseResponse
-// @Failure      400  {object}  endpoint.MessageError
-// @Failure      404  {object}  endpoint.MessageError
-// @Failure      500
-// @Router /songs/{id}/verse [patch]
+// @Header 200 {string} ETag "new song version"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      412  {object}  endpoint.Song
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/{id}/verse [patch]
 func (e *Endpoint) UpdateSongVerseHandler(c *gin.Context) {
 
 	paramID := c.Param("id")
 	songID, err := strconv.Atoi(paramID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{"wrong format id"})
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
 	}
 
 	var request service.UpdateVerseRequest
 	request.SongID = songID
 
+	//ETag of song text is song version
+	request.SongVersion, err = ifMatchVersion(c)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
 	var inputData map[string]interface{}
 	if err := c.ShouldBindJSON(&inputData); err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
+		e.writeError(c, badRequest(err))
 		return
 	}
 
@@ -288,21 +320,18 @@ func (e *Endpoint) UpdateSongVerseHandler(c *gin.Context) {
 		"verseText":   true,
 	}
 
-	var wrongKey []string
-
-	for key := range inputData {
-		if _, ok := allowedKeys[key]; !ok {
-			wrongKey = append(wrongKey, key)
-		}
-	}
-
-	if len(wrongKey) > 0 {
-		c.JSON(http.StatusBadRequest, MessageError{fmt.Sprintf("invalid fields %s", wrongKey)})
+	if err := unknownFields(inputData, allowedKeys); err != nil {
+		e.writeError(c, err)
 		return
 	}
 
-	if verseNumber, ok := inputData["verseNumber"].(int); ok {
-		request.VerseNumber = verseNumber
+	//json numbers are decoded as float64
+	if verseNumber, ok := inputData["verseNumber"].(float64); ok {
+		if verseNumber != float64(int(verseNumber)) || verseNumber < 1 {
+			e.writeError(c, invalidField("verseNumber", "verseNumber must be a positive integer"))
+			return
+		}
+		request.VerseNumber = int(verseNumber)
 	}
 
 	if verseText, ok := inputData["verseText"].(string); ok {
@@ -311,68 +340,164 @@ func (e *Endpoint) UpdateSongVerseHandler(c *gin.Context) {
 
 	resp, err := e.s.UpdateVerse(c.Request.Context(), request)
 	if err != nil {
-		if errors.Is(err, service.ErrSongNotFound) {
-			c.JSON(http.StatusNotFound, MessageError{err.Error()})
-			return
+		if !e.writeVersionMismatch(c, err, v1Representation) {
+			e.writeError(c, err)
 		}
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
 		return
 	}
+	c.Header("ETag", etag(resp.SongVersion))
 	c.JSON(http.StatusOK, resp)
 }
 
-// NewSongHandler @Summary New song
+// NewSongHandler
+// @Summary New song
 // @Schemes
-// @Description create new song
+// @Description create new song, with async=true song is created in background and job is returned
 // @Tags Songs
 // @Accept json
 // @Produce json
 // @Param request body endpoint.NewSong true "query params"
+// @Param   async      query     bool     false  "create song in background"	example(true)
+// @Param        Idempotency-Key   header      string  false  "unique key of request, retry with the same key and body gets response of the first request"
 // @Success 201 {object} endpoint.Song
-// @Failure      400  {object}  endpoint.MessageError
-// @Failure      500
-// @Router /songs/new [post]
+// @Header 201 {string} Idempotent-Replayed "true when stored response of the key is replayed"
+// @Success 202 {object} service.Job
+// @Header 202 {string} Idempotent-Replayed "true when stored response of the key is replayed"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      409  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Failure      502  {object}  problem.Problem
+// @Failure      504  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/new [post]
 func (e *Endpoint) NewSongHandler(c *gin.Context) {
 	var songData NewSong
 
-	validate := validator.New()
+	if err := c.ShouldBindJSON(&songData); err != nil {
+		e.writeError(c, badRequest(err))
+		return
+	}
+
+	if err := e.validate.Struct(songData); err != nil {
+		e.writeError(c, validationError(err))
+		return
+	}
+
+	request := service.NewSongRequest{
+		GroupName: songData.GroupName,
+		SongName:  songData.SongName,
+	}
+
+	if val, ok := c.GetQuery("async"); ok {
+		async, err := strconv.ParseBool(val)
+		if err != nil {
+			e.writeError(c, invalidField("async", "wrong format async: example true"))
+			return
+		}
+		if async {
+			job, err := e.s.NewSongAsync(c.Request.Context(), request)
+			if err != nil {
+				e.writeError(c, err)
+				return
+			}
+			c.Header("Location", fmt.Sprintf("/api/v1/jobs/%d", job.ID))
+			c.JSON(http.StatusAccepted, job)
+			return
+		}
+	}
+
+	song, err := e.s.NewSong(c.Request.Context(), request)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, songFromService(*song))
+}
+
+// @Summary Create song
+// @Schemes
+// @Description create song with lyrics without song info api, lyrics are passed as text with verses separated by empty line or as verse array
+// @Tags Songs
+// @Accept json
+// @Produce json
+// @Param request body endpoint.CreateSong true "song with lyrics"
+// @Success 201 {object} endpoint.Song
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      409  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs [post]
+func (e *Endpoint) CreateSongHandler(c *gin.Context) {
+	var songData CreateSong
 
 	if err := c.ShouldBindJSON(&songData); err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{"invalid data"})
+		e.writeError(c, badRequest(err))
 		return
 	}
 
-	if err := validate.Struct(songData); err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{"invalid fields"})
+	if err := e.validate.Struct(songData); err != nil {
+		e.writeError(c, validationError(err))
 		return
 	}
 
-	song, err := e.s.NewSong(c.Request.Context(), service.NewSongRequest{
+	request := service.CreateSongRequest{
 		GroupName: songData.GroupName,
 		SongName:  songData.SongName,
-	})
+		Link:      songData.Link,
+		Text:      songData.Text,
+		Verses:    songData.Verses,
+	}
+
+	if songData.ReleaseDate != "" {
+		releaseDate, err := dateparse.Parse(songData.ReleaseDate)
+		if err != nil {
+			e.writeError(c, invalidField("releaseDate", "Invalid date format. Use (DD.MM.YYYY), (YYYY-MM-DD), (MM.YYYY) or (YYYY)"))
+			return
+		}
+		request.ReleaseDate = &releaseDate
+	}
+
+	song, err := e.s.CreateSong(c.Request.Context(), request)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, MessageError{err.Error()})
+		e.writeError(c, err)
 		return
 	}
-	c.JSON(http.StatusCreated, Song{
-		ID:          song.ID,
-		GroupName:   song.GroupName,
-		SongName:    song.SongName,
-		ReleaseDate: song.ReleaseDate,
-		Link:        song.Link,
-	})
+
+	c.Header("Location", fmt.Sprintf("/api/v1/songs/%d", song.ID))
+	c.JSON(http.StatusCreated, songFromService(*song))
 }
 
-func (e *Endpoint) TestHandler(c *gin.Context) {
-	group := c.Query("group")
-	song := c.Query("song")
-	if group == "" && song == "" {
-		c.Status(http.StatusBadRequest)
-	}
-	c.JSON(http.StatusOK, gin.H{
-		"releaseDate": "16.07.2006",
-		"text":        "Ooh baby, don't you know I suffer?\\nOoh baby, can you hear me moan?\\nYou caught me under false pretenses\\nHow long before you let me go?\\n\\nOoh\\nYou set my soul alight\\nOoh\\nYou set my soul alight",
-		"link":        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
-	})
+// @Summary Job status
+// @Schemes
+// @Description fetching status of async song creation
+// @Param        id   path      int  true  "Job ID"
+// @Tags Jobs
+// @Accept json
+// @Produce json
+// @Success 200 {object} service.Job
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/jobs/{id} [get]
+func (e *Endpoint) FetchJobHandler(c *gin.Context) {
+	paramID := c.Param("id")
+	jobID, err := strconv.ParseInt(paramID, 10, 64)
+	if err != nil {
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
+	}
+
+	job, err := e.s.FetchJob(c.Request.Context(), service.FetchJobRequest{JobID: jobID})
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, job)
 }
@@ -0,0 +1,130 @@
+package endpoint
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// statusCodes maps problem codes of service errors to http status
+var statusCodes = map[problem.Code]int{
+	problem.CodeBadRequest:        http.StatusBadRequest,
+	problem.CodeValidation:        http.StatusBadRequest,
+	problem.CodeUnauthorized:      http.StatusUnauthorized,
+	problem.CodeForbidden:         http.StatusForbidden,
+	problem.CodeNotFound:          http.StatusNotFound,
+	problem.CodeSongNotFound:      http.StatusNotFound,
+	problem.CodeGroupNotFound:     http.StatusNotFound,
+	problem.CodeJobNotFound:       http.StatusNotFound,
+	problem.CodeWebhookNotFound:   http.StatusNotFound,
+	problem.CodeDeliveryNotFound:  http.StatusNotFound,
+	problem.CodeAPIKeyNotFound:    http.StatusNotFound,
+	problem.CodeNoSongs:           http.StatusNotFound,
+	problem.CodeDeliveryNotFailed: http.StatusConflict,
+	problem.CodeIdempotencyReused: http.StatusConflict,
+	problem.CodeIdempotencyBusy:   http.StatusConflict,
+	problem.CodeSongExists:        http.StatusConflict,
+	problem.CodeVersionMismatch:   http.StatusPreconditionFailed,
+	problem.CodeBadReleaseDate:    http.StatusBadGateway,
+	problem.CodeProviderFailed:    http.StatusBadGateway,
+	problem.CodeStorageFailed:     http.StatusServiceUnavailable,
+	problem.CodeTimeout:           http.StatusGatewayTimeout,
+	problem.CodeInternal:          http.StatusInternalServerError,
+}
+
+// StatusCode returns http status answering err, unknown errors are internal errors
+func StatusCode(err error) int {
+	var appErr *problem.Error
+	if !errors.As(err, &appErr) {
+		return http.StatusInternalServerError
+	}
+	if status, ok := statusCodes[appErr.Code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// problemOf builds problem+json document answering err
+func problemOf(err error, instance string) problem.Problem {
+	return problem.From(err, StatusCode(err), instance)
+}
+
+// writeError answers with problem+json document built from err
+func (e *Endpoint) writeError(c *gin.Context, err error) {
+	p := problemOf(err, c.Request.URL.Path)
+	if p.Status >= http.StatusInternalServerError {
+		e.log.Error("endpoint | request failed",
+			"path", c.Request.URL.Path,
+			"status", p.Status,
+			"key_id", c.GetString(APIKeyIDKey),
+			"error", err.Error())
+	}
+	c.Header("Content-Type", problem.ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+// NotFoundHandler answers unknown routes with problem document
+func (e *Endpoint) NotFoundHandler(c *gin.Context) {
+	e.writeError(c, problem.ErrNotFound)
+}
+
+// badRequest wraps bind error to keep its text in problem detail
+func badRequest(err error) error {
+	return fmt.Errorf("%w: %s", problem.ErrBadRequest, err.Error())
+}
+
+// invalidField is validation error for one field
+func invalidField(field, message string) error {
+	return problem.Validation(problem.FieldError{Field: field, Message: message})
+}
+
+// newValidator returns validator reporting json field names
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" || name == "" {
+			return field.Name
+		}
+		return name
+	})
+	return v
+}
+
+// validationError converts validator errors to field details
+func validationError(err error) error {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return badRequest(err)
+	}
+
+	fields := make([]problem.FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		message := fmt.Sprintf("failed on the '%s' rule", fe.Tag())
+		if fe.Param() != "" {
+			message = fmt.Sprintf("failed on the '%s=%s' rule", fe.Tag(), fe.Param())
+		}
+		fields = append(fields, problem.FieldError{Field: fe.Field(), Message: message})
+	}
+	return problem.Validation(fields...)
+}
+
+// unknownFields returns validation error for keys missing in allowedKeys
+func unknownFields(inputData map[string]interface{}, allowedKeys map[string]bool) error {
+	var fields []problem.FieldError
+	for key := range inputData {
+		if _, ok := allowedKeys[key]; !ok {
+			fields = append(fields, problem.FieldError{Field: key, Message: "unknown field"})
+		}
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	return problem.Validation(fields...)
+}
@@ -0,0 +1,129 @@
+package endpoint_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// errorService fails DeleteSong with err
+type errorService struct {
+	service.MusicService
+	err error
+}
+
+func (f *errorService) DeleteSong(ctx context.Context, request service.DeleteSongRequest) (*service.DeleteSongResponse, error) {
+	return nil, f.err
+}
+
+// TestServiceErrorResponses checks that service errors are answered with status and fields of their code
+func TestServiceErrorResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	cases := []struct {
+		name   string
+		err    error
+		status int
+		code   problem.Code
+		fields []problem.FieldError
+		detail string
+	}{
+		{
+			name:   "not found",
+			err:    service.ErrSongNotFound,
+			status: http.StatusNotFound,
+			code:   problem.CodeSongNotFound,
+		},
+		{
+			name:   "validation",
+			err:    problem.Validation(problem.FieldError{Field: "operations", Message: "at least one operation is required"}),
+			status: http.StatusBadRequest,
+			code:   problem.CodeValidation,
+			fields: []problem.FieldError{{Field: "operations", Message: "at least one operation is required"}},
+		},
+		{
+			name:   "wrapped bad request",
+			err:    fmt.Errorf("%w: row 3: wrong columns", problem.ErrBadRequest),
+			status: http.StatusBadRequest,
+			code:   problem.CodeBadRequest,
+			detail: "bad request: row 3: wrong columns",
+		},
+		{
+			name:   "version mismatch",
+			err:    service.ErrVersionMismatch,
+			status: http.StatusPreconditionFailed,
+			code:   problem.CodeVersionMismatch,
+		},
+		{
+			name:   "timeout",
+			err:    service.ErrTimeOut,
+			status: http.StatusGatewayTimeout,
+			code:   problem.CodeTimeout,
+		},
+		{
+			name:   "unknown error",
+			err:    errors.New("pq: connection refused"),
+			status: http.StatusInternalServerError,
+			code:   problem.CodeInternal,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := gin.New()
+			router.DELETE("/songs/:id", endpoint.New(&errorService{err: tc.err}, logger.New()).DeleteSongHandler)
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/songs/1", nil))
+
+			if rec.Code != tc.status {
+				t.Fatalf("status %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != problem.ContentType {
+				t.Errorf("Content-Type %q, want %q", ct, problem.ContentType)
+			}
+
+			var p problem.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("body is not problem document: %v: %s", err, rec.Body.String())
+			}
+			if p.Status != tc.status || p.Code != tc.code || p.Detail != tc.detail {
+				t.Errorf("problem %+v, want status %d, code %s, detail %q", p, tc.status, tc.code, tc.detail)
+			}
+			if len(p.Errors) != len(tc.fields) {
+				t.Fatalf("errors %+v, want %+v", p.Errors, tc.fields)
+			}
+			for i, field := range tc.fields {
+				if p.Errors[i] != field {
+					t.Errorf("errors[%d] = %+v, want %+v", i, p.Errors[i], field)
+				}
+			}
+		})
+	}
+}
+
+// TestStatusCodeCoversCodes checks that every service error has its own status
+func TestStatusCodeCoversCodes(t *testing.T) {
+	errs := []error{
+		service.ErrSongNotFound, service.ErrGroupNotFound, service.ErrNoSongs, service.ErrSongExist,
+		service.ErrVersionMismatch, service.ErrBadDataFormat, service.ErrInfoProvider, service.ErrRequest,
+		service.ErrTimeOut, service.ErrJobNotFound, service.ErrWebhookNotFound, service.ErrDeliveryNotFound,
+		service.ErrDeliveryNotFailed, service.ErrUnauthorized, service.ErrForbidden, service.ErrAPIKeyNotFound,
+		service.ErrIdempotencyKeyReused, service.ErrIdempotencyKeyBusy,
+	}
+	for _, err := range errs {
+		if status := endpoint.StatusCode(err); status == http.StatusInternalServerError {
+			t.Errorf("%q is answered with 500", err.Error())
+		}
+	}
+}
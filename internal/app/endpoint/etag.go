@@ -0,0 +1,123 @@
+package endpoint
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// etag is strong entity tag of song or verse version
+func etag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
+
+// listETag is weak entity tag of song list page, it changes when any song
+// on the page or total count changes
+func listETag(resp *service.FetchSongsResponse) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "%d", resp.TotalCount)
+	for _, song := range resp.Songs {
+		fmt.Fprintf(h, ";%d:%d", song.ID, song.Version)
+	}
+	return fmt.Sprintf("W/%q", hex.EncodeToString(h.Sum(nil))[:16])
+}
+
+// etagMatch reports whether If-None-Match header matches tag using weak comparison
+func etagMatch(header, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// notModified sets ETag and Last-Modified headers and answers 304 when
+// If-None-Match or If-Modified-Since show that client has current representation.
+// Zero lastModified disables Last-Modified and If-Modified-Since
+func notModified(c *gin.Context, tag string, lastModified time.Time) bool {
+	c.Header("ETag", tag)
+	if !lastModified.IsZero() {
+		c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if header := c.GetHeader("If-None-Match"); header != "" {
+		if !etagMatch(header, tag) {
+			return false
+		}
+		c.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+
+	if header := c.GetHeader("If-Modified-Since"); header != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(header)
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+		c.AbortWithStatus(http.StatusNotModified)
+		return true
+	}
+
+	return false
+}
+
+// ifMatchVersion returns version from If-Match header,
+// nil when header is missing or "*"
+func ifMatchVersion(c *gin.Context) (*int, error) {
+	header := strings.TrimSpace(c.GetHeader("If-Match"))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return nil, invalidField("If-Match", "expected one strong entity tag, example \"3\"")
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil {
+		return nil, invalidField("If-Match", "unknown entity tag")
+	}
+	return &version, nil
+}
+
+// representation converts service models to schema of api version
+type representation struct {
+	song  func(service.Song) any
+	verse func(service.VerseSmall) any
+}
+
+var v1Representation = representation{
+	song:  func(song service.Song) any { return songFromService(song) },
+	verse: func(verse service.VerseSmall) any { return verse },
+}
+
+// writeVersionMismatch answers 412 with current representation when err is
+// version mismatch, returns false for other errors
+func (e *Endpoint) writeVersionMismatch(c *gin.Context, err error, r representation) bool {
+	var mismatch *service.VersionMismatchError
+	if !errors.As(err, &mismatch) {
+		return false
+	}
+
+	switch {
+	case mismatch.Song != nil:
+		c.Header("ETag", etag(mismatch.Song.Version))
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, r.song(*mismatch.Song))
+	case mismatch.Verse != nil:
+		c.Header("ETag", etag(mismatch.Verse.Version))
+		c.AbortWithStatusJSON(http.StatusPreconditionFailed, r.verse(*mismatch.Verse))
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,94 @@
+package endpoint_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// verseService keeps song at version 3, verse update bumps song version
+type verseService struct {
+	service.MusicService
+}
+
+func (f *verseService) FetchVerses(ctx context.Context, request service.FetchVersesRequest) (*service.FetchVersesResponse, error) {
+	return &service.FetchVersesResponse{Verses: []service.VerseSmall{verse(1)}, TotalCount: 1, Version: 3, UpdatedAt: changed}, nil
+}
+
+func (f *verseService) FetchSong(ctx context.Context, request service.FetchSongRequest) (*service.Song, error) {
+	s := song(request.SongID)
+	return &s, nil
+}
+
+func (f *verseService) UpdateVerse(ctx context.Context, request service.UpdateVerseRequest) (service.UpdateVerseResponse, error) {
+	if request.Version != nil {
+		panic("verse version is not sent by http api")
+	}
+	if request.SongVersion != nil && *request.SongVersion != 3 {
+		current := song(request.SongID)
+		return service.UpdateVerseResponse{}, &service.VersionMismatchError{Song: &current}
+	}
+	return service.UpdateVerseResponse{Success: true, Version: 3, SongVersion: 4, Verse: verse(request.VerseNumber)}, nil
+}
+
+// TestVerseIfMatchUsesSongETag checks that If-Match of verse change is compared with
+// ETag of song text and answer carries new song ETag
+func TestVerseIfMatchUsesSongETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := endpoint.New(&verseService{}, logger.New())
+	router := gin.New()
+	router.GET("/api/v1/songs/:id", e.FetchSongTextHandler)
+	router.PATCH("/api/v1/songs/:id/verse", e.UpdateSongVerseHandler)
+	router.GET("/api/v2/songs/:id", e.GetSongV2Handler)
+	router.PATCH("/api/v2/songs/:id/verses/:number", e.UpdateVerseV2Handler)
+
+	cases := []struct {
+		name  string
+		get   string
+		patch string
+		body  string
+	}{
+		{name: "v1", get: "/api/v1/songs/1", patch: "/api/v1/songs/1/verse", body: `{"verseNumber":1,"verseText":"new"}`},
+		{name: "v2", get: "/api/v2/songs/1", patch: "/api/v2/songs/1/verses/1", body: `{"text":"new"}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.get, nil))
+			tag := rec.Header().Get("ETag")
+			if rec.Code != http.StatusOK || tag != `"3"` {
+				t.Fatalf("GET status %d, ETag %q, want 200 and song version \"3\"", rec.Code, tag)
+			}
+
+			patch := func(ifMatch string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPatch, tc.patch, strings.NewReader(tc.body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("If-Match", ifMatch)
+				rec := httptest.NewRecorder()
+				router.ServeHTTP(rec, req)
+				return rec
+			}
+
+			rec = patch(tag)
+			if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"4"` {
+				t.Errorf("PATCH with song ETag: status %d, ETag %q, want 200 and \"4\": %s",
+					rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+			}
+
+			rec = patch(`"2"`)
+			if rec.Code != http.StatusPreconditionFailed || rec.Header().Get("ETag") != `"3"` {
+				t.Errorf("PATCH with stale ETag: status %d, ETag %q, want 412 and current \"3\": %s",
+					rec.Code, rec.Header().Get("ETag"), rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ContentTypeEventStream = "text/event-stream"
+	//reconnection delay suggested to EventSource, ms
+	eventsRetry = 3000
+)
+
+// @Summary Library change events
+// @Schemes
+// @Description Server-Sent Events stream of song.created, song.updated, song.deleted and verse.updated events.
+// @Description Every event has id, reconnecting client sends it in Last-Event-ID header and stream resumes after it.
+// @Description Without Last-Event-ID only new events are sent
+// @Tags Events
+// @Produce text/event-stream
+// @Param   Last-Event-ID      header     int     false  "id of last received event"
+// @Param   last_event_id      query     int     false  "same as Last-Event-ID header, for clients that can't set headers"
+// @Param   group      query     string     false  "only events of songs of group"	example(Muse)
+// @Param   song_id      query     int     false  "only events of song"	example(1)
+// @Success 200 {object} service.Event
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/events [get]
+func (e *Endpoint) StreamEventsHandler(c *gin.Context) {
+	var request service.StreamEventsRequest
+
+	lastEventID := c.GetHeader("Last-Event-ID")
+	if val, ok := c.GetQuery("last_event_id"); ok && lastEventID == "" {
+		lastEventID = val
+	}
+	if lastEventID != "" {
+		eventID, err := strconv.ParseInt(lastEventID, 10, 64)
+		if err != nil || eventID < 0 {
+			e.writeError(c, invalidField("Last-Event-ID", "wrong format Last-Event-ID"))
+			return
+		}
+		request.LastEventID = &eventID
+	}
+
+	if val, ok := c.GetQuery("group"); ok {
+		request.GroupName = &val
+	}
+
+	if val, ok := c.GetQuery("song_id"); ok {
+		songID, err := strconv.Atoi(val)
+		if err != nil || songID < 1 {
+			e.writeError(c, invalidField("song_id", "wrong format song_id"))
+			return
+		}
+		request.SongID = &songID
+	}
+
+	stream := &sseStream{c: c}
+	if err := e.s.StreamEvents(c.Request.Context(), request, stream); err != nil {
+		if !stream.started {
+			e.writeError(c, err)
+			return
+		}
+		var p *problem.Error
+		if errors.As(err, &p) {
+			//stream is already sent, error is reported as event
+			body, _ := json.Marshal(problemOf(err, c.Request.URL.Path))
+			_ = stream.write("event: error\ndata: %s\n\n", body)
+		}
+	}
+}
+
+// sseStream writes events in text/event-stream format,
+// headers are sent with first event so errors before it are answered with problem
+type sseStream struct {
+	c       *gin.Context
+	started bool
+}
+
+func (s *sseStream) start() {
+	if s.started {
+		return
+	}
+	s.c.Header("Content-Type", ContentTypeEventStream)
+	s.c.Header("Cache-Control", "no-cache")
+	s.c.Header("Connection", "keep-alive")
+	//disables response buffering of nginx
+	s.c.Header("X-Accel-Buffering", "no")
+	s.c.Status(http.StatusOK)
+	fmt.Fprintf(s.c.Writer, "retry: %d\n\n", eventsRetry)
+	s.started = true
+}
+
+func (s *sseStream) write(format string, args ...any) error {
+	s.start()
+	if _, err := fmt.Fprintf(s.c.Writer, format, args...); err != nil {
+		return err
+	}
+	s.c.Writer.Flush()
+	return nil
+}
+
+// Send writes event with its events log position as id, so Last-Event-ID resumes after it
+func (s *sseStream) Send(position int64, event service.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return s.write("id: %d\nevent: %s\ndata: %s\n\n", position, event.Type, data)
+}
+
+func (s *sseStream) KeepAlive() error {
+	return s.write(": keep-alive\n\n")
+}
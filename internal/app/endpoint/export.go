@@ -0,0 +1,63 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Export songs
+// @Schemes
+// @Description streaming export of songs with lyrics as ndjson or csv (columns id,group,song,releaseDate,link,text), filters are the same as in song list
+// @Param   format      query     string     false  "ndjson (default) or csv"	example(csv)
+// @Param   group      query     string     false  "group name"	example(Muse)
+// @Param   song      query     string     false  "song name"	example(Supermassive Black Hole)
+// @Param   text      query     string     false  "part of song text"	example(baby)
+// @Param   releaseDate      query     string     false  "release date"	example(16.07.2006)
+// @Param   updated_since      query     string     false  "songs changed since time (RFC 3339)"	example(2024-07-03T10:00:00Z)
+// @Param   released_from      query     string     false  "released on or after date, day, month or year"	example(2006)
+// @Param   released_to      query     string     false  "released on or before date, month or year is included whole"	example(12.2009)
+// @Param   year      query     int     false  "release year"	example(2006)
+// @Param   decade      query     int     false  "first year of release decade"	example(2000)
+// @Tags Songs
+// @Produce plain
+// @Success 200 {object} service.ExportRecord
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/export [get]
+func (e *Endpoint) ExportSongsHandler(c *gin.Context) {
+	filter, err := songFilterFromQuery(c, false)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	request := service.ExportSongsRequest{
+		SongFilter: filter,
+		Format:     strings.ToLower(c.DefaultQuery("format", service.FormatNDJSON)),
+	}
+
+	if err := service.CheckExportFormat(request.Format); err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	contentType := ContentTypeNDJSON
+	if request.Format == service.FormatCSV {
+		contentType = "text/csv; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=songs-%s.%s",
+		time.Now().UTC().Format("20060102-150405"), request.Format))
+	c.Status(http.StatusOK)
+
+	//status is already sent, errors are only logged by service
+	_, _ = e.s.ExportSongs(c.Request.Context(), request, c.Writer)
+}
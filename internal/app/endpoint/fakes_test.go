@@ -0,0 +1,276 @@
+package endpoint_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// Fakes of this package embed the interface they implement,
+// methods not used by tests are nil and panic when called
+
+var (
+	released = time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)
+	changed  = time.Date(2024, 7, 3, 10, 0, 0, 0, time.UTC)
+)
+
+// fakeService answers every call with fixed data
+type fakeService struct {
+	service.MusicService
+}
+
+func song(id int) service.Song {
+	s := service.Song{
+		ID:                   id,
+		GroupName:            "Muse",
+		SongName:             "Supermassive Black Hole",
+		ReleaseDate:          &released,
+		ReleaseDatePrecision: "day",
+		Link:                 "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+		Version:              3,
+		CreatedAt:            changed,
+		UpdatedAt:            changed,
+	}
+	if id%2 == 0 {
+		//songs without release date are allowed
+		s.ReleaseDate = nil
+		s.ReleaseDatePrecision = ""
+	}
+	return s
+}
+
+func verse(number int) service.VerseSmall {
+	return service.VerseSmall{
+		VerseNumber: number,
+		VerseText:   "Ooh baby, don't you know I suffer?",
+		Version:     2,
+		CreatedAt:   changed,
+		UpdatedAt:   changed,
+	}
+}
+
+func (f *fakeService) FetchSongs(ctx context.Context, request service.FetchSongsRequest) (*service.FetchSongsResponse, error) {
+	return &service.FetchSongsResponse{Songs: []service.Song{song(1), song(2)}, TotalCount: 2}, nil
+}
+
+func (f *fakeService) FetchSongsVerses(ctx context.Context, songIDs []int, first int) (map[int][]service.VerseSmall, error) {
+	result := make(map[int][]service.VerseSmall, len(songIDs))
+	for _, id := range songIDs {
+		result[id] = []service.VerseSmall{verse(1), verse(2)}
+	}
+	return result, nil
+}
+
+func (f *fakeService) FetchVerses(ctx context.Context, request service.FetchVersesRequest) (*service.FetchVersesResponse, error) {
+	return &service.FetchVersesResponse{
+		Verses:     []service.VerseSmall{verse(1), verse(2)},
+		TotalCount: 2,
+		Version:    3,
+		UpdatedAt:  changed,
+	}, nil
+}
+
+func (f *fakeService) FetchSong(ctx context.Context, request service.FetchSongRequest) (*service.Song, error) {
+	s := song(request.SongID)
+	return &s, nil
+}
+
+func (f *fakeService) DeleteSong(ctx context.Context, request service.DeleteSongRequest) (*service.DeleteSongResponse, error) {
+	if request.SongID == 404 {
+		return nil, service.ErrSongNotFound
+	}
+	return &service.DeleteSongResponse{Success: true}, nil
+}
+
+func (f *fakeService) UpdateSong(ctx context.Context, request service.UpdateSongRequest) (service.UpdateSongResponse, error) {
+	s := song(request.SongID)
+	s.Version = 4
+	return service.UpdateSongResponse{Success: true, Version: 4, Song: s}, nil
+}
+
+func (f *fakeService) UpdateVerse(ctx context.Context, request service.UpdateVerseRequest) (service.UpdateVerseResponse, error) {
+	return service.UpdateVerseResponse{Success: true, Version: 3, SongVersion: 4, Verse: verse(request.VerseNumber)}, nil
+}
+
+func (f *fakeService) NewSong(ctx context.Context, request service.NewSongRequest) (*service.Song, error) {
+	s := song(1)
+	return &s, nil
+}
+
+func (f *fakeService) CreateSong(ctx context.Context, request service.CreateSongRequest) (*service.Song, error) {
+	s := song(2)
+	return &s, nil
+}
+
+func (f *fakeService) NewSongAsync(ctx context.Context, request service.NewSongRequest) (*service.Job, error) {
+	return &service.Job{ID: 1, Status: "pending", CreatedAt: changed, UpdatedAt: changed}, nil
+}
+
+func (f *fakeService) FetchJob(ctx context.Context, request service.FetchJobRequest) (*service.Job, error) {
+	s := song(1)
+	return &service.Job{ID: request.JobID, Status: "done", Song: &s, CreatedAt: changed, UpdatedAt: changed}, nil
+}
+
+func (f *fakeService) CreateWebhook(ctx context.Context, request service.CreateWebhookRequest) (*service.Webhook, error) {
+	return &service.Webhook{ID: 1, URL: request.URL, EventTypes: request.EventTypes, Active: true,
+		Secret: "secret", CreatedAt: changed}, nil
+}
+
+func (f *fakeService) FetchWebhooks(ctx context.Context) ([]service.Webhook, error) {
+	return []service.Webhook{{ID: 1, URL: "https://example.com/hook", EventTypes: []string{}, Active: true, CreatedAt: changed}}, nil
+}
+
+func (f *fakeService) DeleteWebhook(ctx context.Context, request service.DeleteWebhookRequest) error {
+	return nil
+}
+
+func (f *fakeService) FetchWebhookDeliveries(ctx context.Context, request service.FetchWebhookDeliveriesRequest) ([]service.WebhookDelivery, error) {
+	status := 500
+	return []service.WebhookDelivery{{
+		ID: 1, WebhookID: request.WebhookID, EventType: service.EventSongUpdated, Payload: []byte(`{"id":1}`),
+		Status: "failed", Attempts: 8, NextAttemptAt: changed, ResponseStatus: &status, Error: "answered 500",
+		CreatedAt: changed, UpdatedAt: changed,
+	}}, nil
+}
+
+func (f *fakeService) ReplayWebhookDelivery(ctx context.Context, request service.ReplayWebhookDeliveryRequest) (*service.WebhookDelivery, error) {
+	return &service.WebhookDelivery{
+		ID: 2, WebhookID: request.WebhookID, EventType: service.EventSongUpdated, Payload: []byte(`{"id":1}`),
+		Status: "pending", NextAttemptAt: changed, ReplayOf: &request.DeliveryID, CreatedAt: changed, UpdatedAt: changed,
+	}, nil
+}
+
+func (f *fakeService) Batch(ctx context.Context, request service.BatchRequest) (*service.BatchResponse, error) {
+	resp := &service.BatchResponse{Committed: true}
+	for _, op := range request.Operations {
+		result := service.BatchResult{Op: op.Op, Status: service.BatchStatusDone, SongID: 1, Version: 4}
+		switch {
+		case op.CreateSong != nil:
+			s := song(3)
+			result.Song = &s
+		case op.UpdateVerse != nil:
+			if op.UpdateVerse.SongID == 404 {
+				//rejected operation rolls back the whole batch
+				return &service.BatchResponse{Results: []service.BatchResult{{
+					Op: op.Op, Status: service.BatchStatusFailed, Error: &problem.Problem{
+						Type: "/problems/song_not_found", Title: "song is not found", Status: http.StatusNotFound,
+						Code: "song_not_found",
+					},
+				}}}, service.ErrSongNotFound
+			}
+			v := verse(op.UpdateVerse.VerseNumber)
+			result.Verse = &v
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func (f *fakeService) StartIdempotent(ctx context.Context, request service.IdempotencyRequest) (*service.IdempotentResponse, error) {
+	return nil, nil
+}
+
+func (f *fakeService) FinishIdempotent(ctx context.Context, request service.IdempotencyRequest, response service.IdempotentResponse) error {
+	return nil
+}
+
+func (f *fakeService) Authenticate(ctx context.Context, token string) (*service.APIKey, error) {
+	switch token {
+	case "admin-key":
+		return &service.APIKey{ID: "9f86d081884c7d65", Name: "admin", Scopes: []string{service.ScopeAdmin}}, nil
+	case "read-key":
+		return &service.APIKey{ID: "2c26b46b68ffc68f", Name: "reader", Scopes: []string{service.ScopeRead}}, nil
+	default:
+		return nil, service.ErrUnauthorized
+	}
+}
+
+// memStorage keeps idempotency keys in memory like idempotency_keys table,
+// it is used with real service.Service
+type memStorage struct {
+	database.Storage
+	mu   sync.Mutex
+	keys map[string]*database.IdempotencyKey
+}
+
+func (f *memStorage) ReserveIdempotencyKey(ctx context.Context, request database.ReserveIdempotencyKeyRequest) (*database.IdempotencyKey, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := request.Scope + "\x00" + request.Key
+	if key, ok := f.keys[id]; ok && key.ExpiresAt.After(time.Now()) {
+		stored := *key
+		return &stored, false, nil
+	}
+	key := &database.IdempotencyKey{
+		Scope:       request.Scope,
+		Key:         request.Key,
+		Fingerprint: request.Fingerprint,
+		Owner:       request.Owner,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(request.Lease),
+	}
+	f.keys[id] = key
+	stored := *key
+	return &stored, true, nil
+}
+
+func (f *memStorage) SaveIdempotentResponse(ctx context.Context, request database.SaveIdempotentResponseRequest) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key, ok := f.keys[request.Scope+"\x00"+request.Key]
+	if !ok || key.Owner != request.Owner {
+		return sql.ErrNoRows
+	}
+	key.Status = &request.Status
+	key.Header = request.Header
+	key.Body = request.Body
+	key.ExpiresAt = time.Now().Add(request.TTL)
+	return nil
+}
+
+func (f *memStorage) DeleteIdempotencyKey(ctx context.Context, scope, key, owner string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := scope + "\x00" + key
+	if stored, ok := f.keys[id]; ok && stored.Owner == owner {
+		delete(f.keys, id)
+	}
+	return nil
+}
+
+// expire ends lease of the only stored key as if its request ran longer than lease
+func (f *memStorage) expire(t *testing.T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.keys) != 1 {
+		t.Fatalf("%d keys stored, want 1", len(f.keys))
+	}
+	for _, key := range f.keys {
+		key.ExpiresAt = time.Now().Add(-time.Second)
+	}
+}
+
+// expiresIn returns time left until the only stored key expires
+func (f *memStorage) expiresIn(t *testing.T) time.Duration {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if len(f.keys) != 1 {
+		t.Fatalf("%d keys stored, want 1", len(f.keys))
+	}
+	for _, key := range f.keys {
+		return time.Until(key.ExpiresAt)
+	}
+	return 0
+}
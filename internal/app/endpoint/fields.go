@@ -0,0 +1,135 @@
+package endpoint
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// songListFields are json names of song list attributes allowed in fields param
+var songListFields = jsonFieldNames(reflect.TypeOf(SongListItem{}))
+
+// SongListItem is song of v1 list in schema of song text, fields param selects its attributes
+type SongListItem struct {
+	Song
+	Verses []service.VerseSmall `json:"verses,omitempty"`
+	Links  *service.SongLinks   `json:"links,omitempty"`
+}
+
+func songListItemFromService(song service.Song) SongListItem {
+	return SongListItem{Song: songFromService(song), Verses: song.Verses, Links: song.Links}
+}
+
+// SongsPage is song list trimmed by fields param
+type SongsPage struct {
+	Songs      []map[string]any `json:"songs"`
+	TotalCount int              `json:"total_count"`
+	NextCursor string           `json:"next_cursor,omitempty"`
+	Links      *service.Links   `json:"links,omitempty"`
+}
+
+// jsonField is struct field encoded by json package
+type jsonField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+}
+
+// jsonFields lists fields of struct type t by json names, fields of embedded structs are promoted
+func jsonFields(t reflect.Type) []jsonField {
+	var fields []jsonField
+	for _, f := range reflect.VisibleFields(t) {
+		if f.Anonymous || !f.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, jsonField{
+			name:      name,
+			index:     f.Index,
+			omitEmpty: strings.Contains(options, "omitempty"),
+		})
+	}
+	return fields
+}
+
+func jsonFieldNames(t reflect.Type) []string {
+	fields := jsonFields(t)
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.name)
+	}
+	return names
+}
+
+// parseFields validates comma separated field list against allowed names
+func parseFields(value string, allowed []string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		found := false
+		for _, name := range allowed {
+			if name == field {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, invalidField("fields", fmt.Sprintf("unknown field %q, allowed: %s", field, strings.Join(allowed, ", ")))
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// trimSongs keeps only requested fields of songs, id and links are always kept
+func trimSongs(resp *service.FetchSongsResponse, fields []string) *SongsPage {
+	items := make([]SongListItem, 0, len(resp.Songs))
+	for _, song := range resp.Songs {
+		items = append(items, songListItemFromService(song))
+	}
+	return &SongsPage{
+		Songs:      trimFields(items, fields),
+		TotalCount: resp.TotalCount,
+		NextCursor: resp.NextCursor,
+		Links:      resp.Links,
+	}
+}
+
+// trimFields selects id, links and requested fields of items by json names,
+// empty omitempty fields are skipped like json package does
+func trimFields[T any](items []T, fields []string) []map[string]any {
+	keep := map[string]bool{"id": true, "links": true}
+	for _, field := range fields {
+		keep[field] = true
+	}
+
+	var selected []jsonField
+	for _, field := range jsonFields(reflect.TypeOf((*T)(nil)).Elem()) {
+		if keep[field.name] {
+			selected = append(selected, field)
+		}
+	}
+
+	result := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		value := reflect.ValueOf(item)
+		trimmed := make(map[string]any, len(selected))
+		for _, field := range selected {
+			fieldValue := value.FieldByIndex(field.index)
+			if field.omitEmpty && fieldValue.IsZero() {
+				continue
+			}
+			trimmed[field.name] = fieldValue.Interface()
+		}
+		result = append(result, trimmed)
+	}
+	return result
+}
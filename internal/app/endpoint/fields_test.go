@@ -0,0 +1,68 @@
+package endpoint_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// TestSongListFields checks that fields param selects attributes by names of song text schema
+func TestSongListFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := endpoint.New(&fakeService{}, logger.New())
+	router := gin.New()
+	router.GET("/api/v1/songs", e.FetchSongsHandler)
+	router.GET("/api/v2/songs", e.ListSongsV2Handler)
+
+	cases := []struct {
+		path  string
+		items string
+		want  string
+	}{
+		{path: "/api/v1/songs?fields=group,releaseDate", items: "songs", want: "group,id,links,releaseDate"},
+		{path: "/api/v2/songs?fields=song,createdAt", items: "items", want: "createdAt,id,song"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var page map[string]json.RawMessage
+			var items []map[string]json.RawMessage
+			if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+				t.Fatalf("decode: %v: %s", err, rec.Body.String())
+			}
+			if err := json.Unmarshal(page[tc.items], &items); err != nil || len(items) == 0 {
+				t.Fatalf("no %s in %s", tc.items, rec.Body.String())
+			}
+			for _, item := range items {
+				names := make([]string, 0, len(item))
+				for name := range item {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				if got := strings.Join(names, ","); got != tc.want {
+					t.Errorf("fields %s, want %s", got, tc.want)
+				}
+			}
+		})
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/songs?fields=group_name", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("snake_case field: status %d, want 400", rec.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package endpoint_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/gin-gonic/gin"
+)
+
+// TestFetchSongsInvalidFilter checks that wrong filters are answered with one problem document
+// before service is called, nil service panics when handler falls through
+func TestFetchSongsInvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/songs", endpoint.New(nil, logger.New()).FetchSongsHandler)
+
+	cases := []struct {
+		query string
+		field string
+	}{
+		{query: "releaseDate=2006-07-16", field: "releaseDate"},
+		{query: "releaseDate=yesterday", field: "releaseDate"},
+		{query: "releaseDate=31.02.2006", field: "releaseDate"},
+		{query: "released_from=summer", field: "released_from"},
+		{query: "released_from=2009&released_to=2006", field: "released_to"},
+		{query: "year=two", field: "year"},
+		{query: "decade=1995", field: "decade"},
+		{query: "limit=ten", field: "limit"},
+		{query: "offset=-1", field: "offset"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/songs?"+tc.query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+
+			var p problem.Problem
+			if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil {
+				t.Fatalf("body is not single problem document: %v: %s", err, rec.Body.String())
+			}
+			if p.Code != problem.CodeValidation || len(p.Errors) != 1 || p.Errors[0].Field != tc.field {
+				t.Errorf("problem %+v, want validation error of %s", p, tc.field)
+			}
+		})
+	}
+}
+
+// TestListSongsV2InvalidPage checks that v2 list rejects wrong paging like v1
+func TestListSongsV2InvalidPage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.GET("/v2/songs", endpoint.New(nil, logger.New()).ListSongsV2Handler)
+
+	for _, query := range []string{"limit=ten", "limit=-5", "offset=2.5"} {
+		t.Run(query, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v2/songs?"+query, nil))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, want 400: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package endpoint
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	idempotencyKeyHeader = "Idempotency-Key"
+	replayedHeader       = "Idempotent-Replayed"
+
+	maxIdempotencyKey     = 255
+	idempotencyOwnerBytes = 16
+	maxIdempotentBody     = 1 << 20
+	idempotentBodyHint    = "request body of idempotent request is limited to 1MB"
+)
+
+// storedHeaders are response headers replayed with stored response
+var storedHeaders = []string{"Content-Type", "Location", "ETag"}
+
+// Idempotency stores responses of requests sent with Idempotency-Key header and replays them
+// for retries with the same key and body. Requests without the header are passed as is
+func (e *Endpoint) Idempotency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKey {
+			e.writeError(c, invalidField(idempotencyKeyHeader, fmt.Sprintf("key is longer than %d", maxIdempotencyKey)))
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBody+1))
+		if err != nil {
+			e.writeError(c, badRequest(err))
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			e.writeError(c, invalidField("body", idempotentBodyHint))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		//keys of different api clients do not collide
+		scope := c.Request.Method + " " + c.FullPath()
+		if keyID := c.GetString(APIKeyIDKey); keyID != "" {
+			scope = keyID + " " + scope
+		}
+
+		owner := make([]byte, idempotencyOwnerBytes)
+		if _, err := rand.Read(owner); err != nil {
+			e.writeError(c, err)
+			return
+		}
+
+		request := service.IdempotencyRequest{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: requestFingerprint(c, body),
+			Owner:       hex.EncodeToString(owner),
+		}
+
+		replay, err := e.s.StartIdempotent(c.Request.Context(), request)
+		if err != nil {
+			e.writeError(c, err)
+			return
+		}
+		if replay != nil {
+			for name, value := range replay.Header {
+				c.Header(name, value)
+			}
+			c.Header(replayedHeader, "true")
+			c.Data(replay.Status, replay.Header["Content-Type"], replay.Body)
+			c.Abort()
+			return
+		}
+
+		//key is released when handler panics or response is not stored, so retry is not answered
+		//with in progress conflict until lease of the key expires
+		finished := false
+		defer func() {
+			if finished {
+				return
+			}
+			if err := e.s.ReleaseIdempotent(context.WithoutCancel(c.Request.Context()), request); err != nil {
+				e.log.Error("endpoint.Idempotency | ReleaseIdempotent", "error", err.Error(), "key", key)
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		response := service.IdempotentResponse{
+			Status: recorder.Status(),
+			Header: make(map[string]string, len(storedHeaders)),
+			Body:   recorder.body.Bytes(),
+		}
+		for _, name := range storedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				response.Header[name] = value
+			}
+		}
+
+		//response is kept when client has gone, its retry gets it
+		if err := e.s.FinishIdempotent(context.WithoutCancel(c.Request.Context()), request, response); err != nil {
+			e.log.Error("endpoint.Idempotency | FinishIdempotent", "error", err.Error(), "key", key)
+			return
+		}
+		finished = true
+	}
+}
+
+// requestFingerprint hashes method, path, query and body of request,
+// json body is compacted with sorted keys so formatting does not change fingerprint
+func requestFingerprint(c *gin.Context, body []byte) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s %s?%s\n", c.Request.Method, c.Request.URL.Path, c.Request.URL.Query().Encode())
+
+	var value any
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&value); err == nil {
+		if canonical, err := json.Marshal(value); err == nil {
+			body = canonical
+		}
+	}
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder passes response to client and keeps its copy
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *bodyRecorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
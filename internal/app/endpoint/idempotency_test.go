@@ -0,0 +1,159 @@
+package endpoint_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const idempotencyTTL = 24 * time.Hour
+
+// TestIdempotency checks replay of completed request, conflicts of reused and in progress keys
+// and release of key when handler panics
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	storage := &memStorage{keys: make(map[string]*database.IdempotencyKey)}
+	e := endpoint.New(service.New(storage, nil, nil, nil, idempotencyTTL, logger.New(), false), logger.New())
+
+	var (
+		calls       int
+		started     = make(chan struct{})
+		release     = make(chan struct{})
+		slowStarted = make(chan struct{})
+		slowRelease = make(chan struct{})
+	)
+	router := gin.New()
+	router.Use(gin.RecoveryWithWriter(io.Discard))
+	router.POST("/songs/new", e.Idempotency(), func(c *gin.Context) {
+		calls++
+		switch c.Query("mode") {
+		case "block":
+			close(started)
+			<-release
+		case "slow":
+			//only the first request outlives its lease
+			if calls == 1 {
+				close(slowStarted)
+				<-slowRelease
+			}
+		case "panic":
+			panic("handler failed")
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	send := func(path, key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Idempotency-Key", key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	code := func(rec *httptest.ResponseRecorder) problem.Code {
+		var p problem.Problem
+		_ = json.Unmarshal(rec.Body.Bytes(), &p)
+		return p.Code
+	}
+
+	t.Run("in progress", func(t *testing.T) {
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- send("/songs/new?mode=block", "busy", `{"group":"Muse"}`) }()
+		<-started
+
+		if lease := storage.expiresIn(t); lease > 5*time.Minute {
+			t.Errorf("key in progress expires in %v, want short lease", lease)
+		}
+		rec := send("/songs/new?mode=block", "busy", `{"group":"Muse"}`)
+		if rec.Code != http.StatusConflict || code(rec) != problem.CodeIdempotencyBusy {
+			t.Errorf("status %d, want 409 %s: %s", rec.Code, problem.CodeIdempotencyBusy, rec.Body.String())
+		}
+
+		close(release)
+		if rec := <-done; rec.Code != http.StatusCreated {
+			t.Fatalf("first request status %d: %s", rec.Code, rec.Body.String())
+		}
+		if ttl := storage.expiresIn(t); ttl < idempotencyTTL-time.Minute {
+			t.Errorf("stored response expires in %v, want %v", ttl, idempotencyTTL)
+		}
+		storage.keys = make(map[string]*database.IdempotencyKey)
+	})
+
+	t.Run("replay", func(t *testing.T) {
+		calls = 0
+		first := send("/songs/new", "replay", `{"group":"Muse","song":"Uprising"}`)
+		//formatting of json body does not change request
+		second := send("/songs/new", "replay", `{"song": "Uprising", "group": "Muse"}`)
+
+		if calls != 1 {
+			t.Errorf("handler called %d times, want 1", calls)
+		}
+		if second.Code != first.Code || second.Body.String() != first.Body.String() {
+			t.Errorf("replayed %d %s, want %d %s", second.Code, second.Body.String(), first.Code, first.Body.String())
+		}
+		if second.Header().Get("Idempotent-Replayed") != "true" {
+			t.Errorf("replayed response is not marked")
+		}
+	})
+
+	t.Run("conflicting body", func(t *testing.T) {
+		calls = 0
+		send("/songs/new", "conflict", `{"group":"Muse","song":"Uprising"}`)
+		rec := send("/songs/new", "conflict", `{"group":"Muse","song":"Starlight"}`)
+
+		if rec.Code != http.StatusConflict || code(rec) != problem.CodeIdempotencyReused {
+			t.Errorf("status %d, want 409 %s: %s", rec.Code, problem.CodeIdempotencyReused, rec.Body.String())
+		}
+		if calls != 1 {
+			t.Errorf("handler called %d times, want 1", calls)
+		}
+	})
+
+	t.Run("lease taken over", func(t *testing.T) {
+		calls = 0
+		storage.keys = make(map[string]*database.IdempotencyKey)
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- send("/songs/new?mode=slow", "slow", `{"group":"Muse"}`) }()
+		<-slowStarted
+
+		storage.expire(t)
+		retry := send("/songs/new?mode=slow", "slow", `{"group":"Muse"}`)
+		if retry.Code != http.StatusCreated {
+			t.Fatalf("retry status %d: %s", retry.Code, retry.Body.String())
+		}
+
+		close(slowRelease)
+		if rec := <-done; rec.Code != http.StatusCreated {
+			t.Fatalf("first request status %d: %s", rec.Code, rec.Body.String())
+		}
+
+		//response of request which lost the key does not replace response of retry
+		replay := send("/songs/new?mode=slow", "slow", `{"group":"Muse"}`)
+		if replay.Body.String() != retry.Body.String() || calls != 2 {
+			t.Errorf("replayed %s after %d calls, want %s", replay.Body.String(), calls, retry.Body.String())
+		}
+	})
+
+	t.Run("panic releases key", func(t *testing.T) {
+		calls = 0
+		if rec := send("/songs/new?mode=panic", "panic", `{}`); rec.Code != http.StatusInternalServerError {
+			t.Fatalf("status %d, want 500", rec.Code)
+		}
+		rec := send("/songs/new?mode=panic", "panic", `{}`)
+		if code(rec) == problem.CodeIdempotencyBusy || calls != 2 {
+			t.Errorf("retry after panic: status %d, %d calls: %s", rec.Code, calls, rec.Body.String())
+		}
+	})
+}
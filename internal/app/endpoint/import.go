@@ -0,0 +1,104 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+const ContentTypeNDJSON = "application/x-ndjson"
+
+// @Summary Import songs
+// @Schemes
+// @Description bulk import of songs with lyrics from ndjson or csv (columns group,song,releaseDate,link,text).
+// @Description Response is ndjson stream: report line for every row (created, duplicate, invalid, failed) and summary line at the end
+// @Tags Songs
+// @Accept plain
+// @Produce plain
+// @Param   format      query     string     false  "ndjson or csv, by default from Content-Type"	example(ndjson)
+// @Param   dry_run      query     bool     false  "validate and check duplicates without saving"	example(true)
+// @Param   batch_size      query     int     false  "songs per transaction"	example(100)
+// @Param request body string true "ndjson or csv file"
+// @Success 200 {object} service.ImportRowReport
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/songs/import [post]
+func (e *Endpoint) ImportSongsHandler(c *gin.Context) {
+	request := service.ImportSongsRequest{
+		Reader: c.Request.Body,
+		Format: importFormat(c),
+	}
+
+	if val, ok := c.GetQuery("dry_run"); ok {
+		dryRun, err := strconv.ParseBool(val)
+		if err != nil {
+			e.writeError(c, invalidField("dry_run", "wrong format dry_run: example true"))
+			return
+		}
+		request.DryRun = dryRun
+	}
+
+	if val, ok := c.GetQuery("batch_size"); ok {
+		batchSize, err := strconv.Atoi(val)
+		if err != nil || batchSize <= 0 {
+			e.writeError(c, invalidField("batch_size", "wrong format batch_size: positive int"))
+			return
+		}
+		request.BatchSize = batchSize
+	}
+
+	//report is written while body is still read
+	_ = http.NewResponseController(c.Writer).EnableFullDuplex()
+
+	enc := json.NewEncoder(c.Writer)
+	started := false
+	start := func() {
+		if !started {
+			c.Header("Content-Type", ContentTypeNDJSON)
+			c.Status(http.StatusOK)
+			started = true
+		}
+	}
+
+	result, err := e.s.ImportSongs(c.Request.Context(), request, func(row service.ImportRowReport) error {
+		start()
+		if err := enc.Encode(row); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if !started {
+			e.writeError(c, err)
+			return
+		}
+		p := problemOf(err, c.Request.URL.Path)
+		_ = enc.Encode(ImportResult{Error: &p})
+		return
+	}
+
+	start()
+	_ = enc.Encode(ImportResult{Summary: result})
+}
+
+// importFormat takes format from query or Content-Type
+func importFormat(c *gin.Context) string {
+	if val, ok := c.GetQuery("format"); ok {
+		return val
+	}
+	mediaType, _, _ := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	switch mediaType {
+	case "text/csv":
+		return service.FormatCSV
+	default:
+		return service.FormatNDJSON
+	}
+}
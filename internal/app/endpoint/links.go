@@ -0,0 +1,106 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// songListLinks sets pagination links of song list and lyrics links of its songs
+func songListLinks(u *url.URL, request service.FetchSongsRequest, resp *service.FetchSongsResponse) {
+	limit := service.SongsLimit(request.Limit)
+	if request.Cursor != "" {
+		resp.Links = cursorLinks(u, limit, resp.NextCursor)
+	} else {
+		offset := int(request.Offset)
+		//next cursor is issued only for full page
+		hasNext := resp.NextCursor != "" && offset+limit < resp.TotalCount
+		resp.Links = offsetLinks(u, limit, offset, resp.TotalCount, hasNext)
+	}
+
+	base := strings.TrimSuffix(u.Path, "/")
+	for i := range resp.Songs {
+		resp.Songs[i].Links = &service.SongLinks{Lyrics: base + "/" + strconv.Itoa(resp.Songs[i].ID)}
+	}
+}
+
+// verseListLinks sets pagination links of song verses
+func verseListLinks(u *url.URL, request service.FetchVersesRequest, resp *service.FetchVersesResponse) {
+	limit := service.VersesLimit(request.Limit)
+	offset := max(request.Offset, 0)
+	resp.Links = offsetLinks(u, limit, offset, resp.TotalCount, offset+limit < resp.TotalCount)
+}
+
+// offsetLinks builds pagination links of list paged by limit and offset,
+// other query params of request are kept in links
+func offsetLinks(u *url.URL, limit, offset, total int, hasNext bool) *service.Links {
+	links := &service.Links{
+		Self:  u.RequestURI(),
+		First: pageURL(u, limit, 0, ""),
+	}
+
+	if offset > 0 {
+		links.Prev = pageURL(u, limit, max(offset-limit, 0), "")
+	}
+	if hasNext {
+		links.Next = pageURL(u, limit, offset+limit, "")
+	}
+
+	last := 0
+	if total > 0 {
+		last = (total - 1) / limit * limit
+	}
+	links.Last = pageURL(u, limit, last, "")
+
+	return links
+}
+
+// cursorLinks builds pagination links of list paged by cursor,
+// cursor only moves forward so prev and last links are not known
+func cursorLinks(u *url.URL, limit int, nextCursor string) *service.Links {
+	links := &service.Links{
+		Self:  u.RequestURI(),
+		First: pageURL(u, limit, 0, ""),
+	}
+	if nextCursor != "" {
+		links.Next = pageURL(u, limit, 0, nextCursor)
+	}
+	return links
+}
+
+func pageURL(u *url.URL, limit, offset int, cursor string) string {
+	query := u.Query()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Del("offset")
+	query.Del("cursor")
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	if cursor != "" {
+		query.Set("cursor", cursor)
+	}
+
+	page := url.URL{Path: u.Path, RawQuery: query.Encode()}
+	return page.RequestURI()
+}
+
+// setLinkHeader sends links in Link header (RFC 8288)
+func setLinkHeader(c *gin.Context, links *service.Links) {
+	var values []string
+	for _, link := range []struct{ rel, target string }{
+		{"self", links.Self},
+		{"first", links.First},
+		{"prev", links.Prev},
+		{"next", links.Next},
+		{"last", links.Last},
+	} {
+		if link.target != "" {
+			values = append(values, fmt.Sprintf("<%s>; rel=%q", link.target, link.rel))
+		}
+	}
+	c.Header("Link", strings.Join(values, ", "))
+}
@@ -2,18 +2,35 @@ package endpoint
 
 import (
 	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
 )
 
 type Song struct {
-	ID          int       `json:"id" example:"1"`
-	GroupName   string    `json:"group" example:"Muse"`
-	SongName    string    `json:"song" example:"Supermassive Black Hole"`
-	ReleaseDate time.Time `json:"releaseDate" example:"1987-07-03T00:00:00Z"`
-	Link        string    `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	ID                   int        `json:"id" example:"1"`
+	GroupName            string     `json:"group" example:"Muse"`
+	SongName             string     `json:"song" example:"Supermassive Black Hole"`
+	ReleaseDate          *time.Time `json:"releaseDate" example:"1987-07-03T00:00:00Z" extensions:"x-nullable"`
+	ReleaseDatePrecision string     `json:"releaseDatePrecision,omitempty" example:"day"`
+	Link                 string     `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Version              int        `json:"version" example:"1"`
+	CreatedAt            time.Time  `json:"createdAt" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt            time.Time  `json:"updatedAt" example:"2024-07-03T10:00:00Z"`
 }
 
-type MessageError struct {
-	Message string `json:"message"`
+func songFromService(song service.Song) Song {
+	return Song{
+		ID:                   song.ID,
+		GroupName:            song.GroupName,
+		SongName:             song.SongName,
+		ReleaseDate:          song.ReleaseDate,
+		ReleaseDatePrecision: song.ReleaseDatePrecision,
+		Link:                 song.Link,
+		Version:              song.Version,
+		CreatedAt:            song.CreatedAt,
+		UpdatedAt:            song.UpdatedAt,
+	}
 }
 
 type SongText struct {
@@ -39,7 +56,50 @@ type NewSong struct {
 	SongName  string `json:"song" validate:"required"`
 }
 
+type CreateSong struct {
+	GroupName   string   `json:"group" validate:"required" example:"Muse"`
+	SongName    string   `json:"song" validate:"required" example:"Supermassive Black Hole"`
+	ReleaseDate string   `json:"releaseDate" example:"16.07.2006"`
+	Link        string   `json:"link" validate:"omitempty,url" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Text        string   `json:"text" validate:"required_without=Verses,excluded_with=Verses" example:"Ooh baby, don't you know I suffer?\n\nOoh\nYou set my soul alight"`
+	Verses      []string `json:"verses" validate:"required_without=Text,dive,min=1"`
+}
+
 type UpdateVerseRequest struct {
-	VerseNumber int    `json:"verse_number"`
-	VerseText   string `json:"verse_text"`
+	VerseNumber int    `json:"verseNumber" example:"1"`
+	VerseText   string `json:"verseText" example:"Ooh baby, don't you know I suffer?"`
+}
+
+// ImportResult is the last line of import report stream
+type ImportResult struct {
+	Summary *service.ImportSongsResponse `json:"summary,omitempty"`
+	Error   *problem.Problem             `json:"error,omitempty"`
+}
+
+type CreateWebhook struct {
+	URL        string   `json:"url" validate:"required,url" example:"https://example.com/hooks/music"`
+	Secret     string   `json:"secret" validate:"omitempty,min=16" example:"my-shared-secret-value"`
+	EventTypes []string `json:"event_types" validate:"dive,required" example:"song.created,song.deleted"`
+}
+
+// BatchRequest is list of changes applied all or nothing
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation is one change of batch. songId is required by all operations except create_song,
+// update_song takes group, song, releaseDate and link, update_verse takes verseNumber and verseText,
+// create_song takes song fields with text or verses
+type BatchOperation struct {
+	Op          string   `json:"op" enums:"create_song,update_song,update_verse,delete_song" example:"update_song"`
+	SongID      int      `json:"songId" example:"1"`
+	Version     *int     `json:"version" example:"3" extensions:"x-nullable"`
+	Group       *string  `json:"group" example:"Muse" extensions:"x-nullable"`
+	Song        *string  `json:"song" example:"Supermassive Black Hole" extensions:"x-nullable"`
+	ReleaseDate *string  `json:"releaseDate" example:"16.07.2006" extensions:"x-nullable"`
+	Link        *string  `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw" extensions:"x-nullable"`
+	VerseNumber int      `json:"verseNumber" example:"1"`
+	VerseText   *string  `json:"verseText" example:"Ooh baby, don't you know I suffer?" extensions:"x-nullable"`
+	Text        string   `json:"text" example:"Ooh baby, don't you know I suffer?\n\nOoh\nYou set my soul alight"`
+	Verses      []string `json:"verses"`
 }
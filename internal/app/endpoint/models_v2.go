@@ -0,0 +1,141 @@
+package endpoint
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// SongV2 is song in api v2 schema, releaseDate is ISO 8601 date
+// of known precision: "2006-07-16", "2006-07" or "2006"
+type SongV2 struct {
+	ID          int       `json:"id" example:"1"`
+	Group       string    `json:"group" example:"Muse"`
+	Song        string    `json:"song" example:"Supermassive Black Hole"`
+	ReleaseDate *string   `json:"releaseDate" example:"2006-07-16" extensions:"x-nullable"`
+	Link        string    `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Version     int       `json:"version" example:"1"`
+	CreatedAt   time.Time `json:"createdAt" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt   time.Time `json:"updatedAt" example:"2024-07-03T10:00:00Z"`
+	Verses      []VerseV2 `json:"verses,omitempty"`
+}
+
+type VerseV2 struct {
+	Number    int       `json:"number" example:"1"`
+	Text      string    `json:"text" example:"Ooh baby, don't you know I suffer?"`
+	Version   int       `json:"version" example:"1"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt time.Time `json:"updatedAt" example:"2024-07-03T10:00:00Z"`
+}
+
+type SongListV2 struct {
+	Items      []SongV2 `json:"items"`
+	Total      int      `json:"total" example:"25"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// SongListFieldsV2 is song list trimmed by fields param
+type SongListFieldsV2 struct {
+	Items      []map[string]any `json:"items"`
+	Total      int              `json:"total" example:"25"`
+	NextCursor string           `json:"nextCursor,omitempty"`
+}
+
+type JobV2 struct {
+	ID        int64     `json:"id" example:"1"`
+	Status    string    `json:"status" example:"done"`
+	Error     string    `json:"error,omitempty" example:"song is not found"`
+	Song      *SongV2   `json:"song,omitempty"`
+	CreatedAt time.Time `json:"createdAt" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt time.Time `json:"updatedAt" example:"2024-07-03T10:00:05Z"`
+}
+
+type CreateSongV2 struct {
+	Group       string   `json:"group" validate:"required" example:"Muse"`
+	Song        string   `json:"song" validate:"required" example:"Supermassive Black Hole"`
+	ReleaseDate string   `json:"releaseDate" example:"2006-07-16"`
+	Link        string   `json:"link" validate:"omitempty,url" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Text        string   `json:"text" validate:"required_without=Verses,excluded_with=Verses" example:"Ooh baby, don't you know I suffer?\n\nOoh\nYou set my soul alight"`
+	Verses      []string `json:"verses" validate:"required_without=Text,dive,min=1"`
+}
+
+type NewSongV2 struct {
+	Group string `json:"group" validate:"required" example:"Muse"`
+	Song  string `json:"song" validate:"required" example:"Supermassive Black Hole"`
+}
+
+// UpdateSongV2 changes only passed fields
+type UpdateSongV2 struct {
+	Group       *string `json:"group" validate:"omitempty,min=1" example:"Muse"`
+	Song        *string `json:"song" validate:"omitempty,min=1" example:"Supermassive Black Hole"`
+	ReleaseDate *string `json:"releaseDate" example:"2006-07" extensions:"x-nullable"`
+	Link        *string `json:"link" validate:"omitempty,url" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+}
+
+type UpdateVerseV2 struct {
+	Text *string `json:"text" validate:"required" example:"Ooh baby, don't you know I suffer?"`
+}
+
+// songListFieldsV2 are json names of v2 song attributes allowed in fields param
+var songListFieldsV2 = jsonFieldNames(reflect.TypeOf(SongV2{}))
+
+var v2Representation = representation{
+	song:  func(song service.Song) any { return songV2FromService(song) },
+	verse: func(verse service.VerseSmall) any { return verseV2FromService(verse) },
+}
+
+func songV2FromService(song service.Song) SongV2 {
+	result := SongV2{
+		ID:        song.ID,
+		Group:     song.GroupName,
+		Song:      song.SongName,
+		Link:      song.Link,
+		Version:   song.Version,
+		CreatedAt: song.CreatedAt,
+		UpdatedAt: song.UpdatedAt,
+	}
+
+	if song.ReleaseDate != nil {
+		releaseDate := dateparse.Date{
+			Time:      *song.ReleaseDate,
+			Precision: dateparse.Precision(song.ReleaseDatePrecision),
+		}.ISO()
+		result.ReleaseDate = &releaseDate
+	}
+
+	if song.Verses != nil {
+		result.Verses = make([]VerseV2, 0, len(song.Verses))
+		for _, verse := range song.Verses {
+			result.Verses = append(result.Verses, verseV2FromService(verse))
+		}
+	}
+
+	return result
+}
+
+func verseV2FromService(verse service.VerseSmall) VerseV2 {
+	return VerseV2{
+		Number:    verse.VerseNumber,
+		Text:      verse.VerseText,
+		Version:   verse.Version,
+		CreatedAt: verse.CreatedAt,
+		UpdatedAt: verse.UpdatedAt,
+	}
+}
+
+func jobV2FromService(job service.Job) JobV2 {
+	result := JobV2{
+		ID:        job.ID,
+		Status:    job.Status,
+		Error:     job.Error,
+		CreatedAt: job.CreatedAt,
+		UpdatedAt: job.UpdatedAt,
+	}
+	if job.Song != nil {
+		song := songV2FromService(*job.Song)
+		result.Song = &song
+	}
+	return result
+}
@@ -0,0 +1,151 @@
+package endpoint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// queryFilterNames are names of filter query params in both api versions
+var queryFilterNames = service.FilterNames{
+	ReleaseDate:  "releaseDate",
+	ReleasedFrom: "released_from",
+	ReleasedTo:   "released_to",
+	Year:         "year",
+	Decade:       "decade",
+}
+
+// songFilterFromQuery reads song list filter query params,
+// with iso dates are accepted only in ISO 8601 form
+func songFilterFromQuery(c *gin.Context, iso bool) (service.SongFilter, error) {
+	params := service.SongFilterParams{ISO: iso, Names: queryFilterNames}
+
+	if val, ok := c.GetQuery("group"); ok {
+		params.GroupName = &val
+	}
+
+	if val, ok := c.GetQuery("song"); ok {
+		params.SongName = &val
+	}
+
+	if val, ok := c.GetQuery("text"); ok {
+		params.SongText = &val
+	}
+
+	if val, ok := c.GetQuery("releaseDate"); ok {
+		params.ReleaseDate = &val
+	}
+
+	if val, ok := c.GetQuery("updated_since"); ok {
+		since, err := time.Parse(time.RFC3339, val)
+		if err != nil {
+			return service.SongFilter{}, invalidField("updated_since", "wrong format updated_since: example 2024-07-03T10:00:00Z")
+		}
+		params.UpdatedSince = &since
+	}
+
+	if val, ok := c.GetQuery("released_from"); ok {
+		params.ReleasedFrom = &val
+	}
+
+	if val, ok := c.GetQuery("released_to"); ok {
+		params.ReleasedTo = &val
+	}
+
+	if val, ok := c.GetQuery("year"); ok {
+		year, err := strconv.Atoi(val)
+		if err != nil {
+			return service.SongFilter{}, invalidField("year", service.YearMessage)
+		}
+		params.Year = &year
+	}
+
+	if val, ok := c.GetQuery("decade"); ok {
+		decade, err := strconv.Atoi(val)
+		if err != nil {
+			return service.SongFilter{}, invalidField("decade", service.DecadeMessage)
+		}
+		params.Decade = &decade
+	}
+
+	return service.NewSongFilter(params)
+}
+
+// songListRequest reads song list query params shared by api versions,
+// returns requested fields checked against allowedFields
+func songListRequest(c *gin.Context, iso bool, allowedFields []string) (service.FetchSongsRequest, []string, error) {
+	var fetchParams service.FetchSongsRequest
+
+	filter, err := songFilterFromQuery(c, iso)
+	if err != nil {
+		return fetchParams, nil, err
+	}
+	fetchParams.SongFilter = filter
+
+	if val, ok := c.GetQuery("sort"); ok {
+		fetchParams.Sort, err = service.ParseSort(val)
+		if err != nil {
+			return fetchParams, nil, err
+		}
+	}
+
+	if val, ok := c.GetQuery("offset"); ok {
+		offset, err := pageParam("offset", val)
+		if err != nil {
+			return fetchParams, nil, err
+		}
+		fetchParams.Offset = offset
+	}
+
+	if val, ok := c.GetQuery("cursor"); ok {
+		if fetchParams.Offset > 0 {
+			return fetchParams, nil, invalidField("cursor", "cursor can't be combined with offset")
+		}
+		fetchParams.Cursor = val
+	}
+
+	if val, ok := c.GetQuery("include"); ok {
+		for _, include := range strings.Split(val, ",") {
+			if strings.TrimSpace(include) != "verses" {
+				return fetchParams, nil, invalidField("include", fmt.Sprintf("unknown include %q, allowed: verses", include))
+			}
+		}
+		fetchParams.IncludeVerses = true
+	}
+
+	var fields []string
+	if val, ok := c.GetQuery("fields"); ok {
+		fields, err = parseFields(val, allowedFields)
+		if err != nil {
+			return fetchParams, nil, err
+		}
+		for _, field := range fields {
+			if field == "verses" {
+				fetchParams.IncludeVerses = true
+			}
+		}
+	}
+
+	if val, ok := c.GetQuery("limit"); ok {
+		limit, err := pageParam("limit", val)
+		if err != nil {
+			return fetchParams, nil, err
+		}
+		fetchParams.Limit = limit
+	}
+
+	return fetchParams, fields, nil
+}
+
+// pageParam parses limit or offset, request is rejected the same way when spec validation is off
+func pageParam(name, value string) (uint64, error) {
+	intval, err := strconv.Atoi(value)
+	if err != nil || intval < 0 {
+		return 0, invalidField(name, fmt.Sprintf("%s must be a non-negative integer", name))
+	}
+	return uint64(intval), nil
+}
@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// Register adds handlers of api v1 and v2 to router. With auth every group requires
+// api key scope: read for queries, write for changes and admin for webhooks
+func (e *Endpoint) Register(r gin.IRouter, auth bool) {
+	scope := func(name string) []gin.HandlerFunc {
+		if !auth {
+			return nil
+		}
+		return []gin.HandlerFunc{e.RequireScope(name)}
+	}
+
+	read := r.Group("/api/v1", scope(service.ScopeRead)...)
+	{
+		read.GET("/songs", e.FetchSongsHandler)
+		read.GET("/songs/export", e.ExportSongsHandler)
+		read.GET("/songs/:id", e.FetchSongTextHandler)
+		read.GET("/jobs/:id", e.FetchJobHandler)
+		read.GET("/events", e.StreamEventsHandler)
+	}
+	write := r.Group("/api/v1", scope(service.ScopeWrite)...)
+	{
+		write.POST("/songs", e.CreateSongHandler)
+		write.POST("/songs/import", e.ImportSongsHandler)
+		write.DELETE("/songs/:id", e.DeleteSongHandler)
+		write.PATCH("/songs/:id", e.UpdateSongHandler)
+		write.PATCH("/songs/:id/verse", e.UpdateSongVerseHandler)
+		write.POST("/songs/new", e.Idempotency(), e.NewSongHandler)
+		write.POST("/batch", e.BatchHandler)
+	}
+	admin := r.Group("/api/v1", scope(service.ScopeAdmin)...)
+	{
+		admin.POST("/webhooks", e.CreateWebhookHandler)
+		admin.GET("/webhooks", e.FetchWebhooksHandler)
+		admin.DELETE("/webhooks/:id", e.DeleteWebhookHandler)
+		admin.GET("/webhooks/:id/deliveries", e.FetchWebhookDeliveriesHandler)
+		admin.POST("/webhooks/:id/deliveries/:delivery_id/replay", e.ReplayWebhookDeliveryHandler)
+	}
+
+	readV2 := r.Group("/api/v2", scope(service.ScopeRead)...)
+	{
+		readV2.GET("/songs", e.ListSongsV2Handler)
+		readV2.GET("/songs/:id", e.GetSongV2Handler)
+		readV2.GET("/jobs/:id", e.FetchJobV2Handler)
+	}
+	writeV2 := r.Group("/api/v2", scope(service.ScopeWrite)...)
+	{
+		writeV2.POST("/songs", e.CreateSongV2Handler)
+		writeV2.POST("/songs/new", e.Idempotency(), e.NewSongV2Handler)
+		writeV2.PATCH("/songs/:id", e.UpdateSongV2Handler)
+		writeV2.DELETE("/songs/:id", e.DeleteSongV2Handler)
+		writeV2.PATCH("/songs/:id/verses/:number", e.UpdateVerseV2Handler)
+	}
+}
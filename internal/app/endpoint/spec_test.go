@@ -0,0 +1,235 @@
+package endpoint_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/app/openapi"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type specCase struct {
+	name   string
+	method string
+	path   string
+	body   string
+	header map[string]string
+	status int
+}
+
+// TestHandlersMatchSpec sends documented requests to handlers behind spec validator,
+// the test fails when request is rejected by spec or response contradicts it
+func TestHandlersMatchSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	var violations []string
+	validator, err := openapi.New(doc, openapi.Options{
+		Responses: true,
+		OnResponseError: func(c *gin.Context, err error) {
+			violations = append(violations, err.Error())
+		},
+	}, logger.New())
+	if err != nil {
+		t.Fatalf("init validator: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(validator.Middleware())
+	endpoint.New(&fakeService{}, logger.New()).Register(router, true)
+
+	//requests are sent with admin key unless case sets its own
+	cases := []specCase{
+		{name: "list songs", method: http.MethodGet, status: http.StatusOK,
+			path: "/api/v1/songs?group=Muse&song=Hole&text=baby&releaseDate=16.07.2006&sort=group,-releaseDate&limit=10&offset=2"},
+		{name: "list songs with verses", method: http.MethodGet, status: http.StatusOK,
+			path: "/api/v1/songs?include=verses&year=2006&decade=2000&released_from=2006&released_to=12.2009"},
+		{name: "list songs fields", method: http.MethodGet, status: http.StatusOK,
+			path: "/api/v1/songs?fields=group,song&updated_since=2024-07-03T10:00:00Z"},
+		{name: "song text", method: http.MethodGet, path: "/api/v1/songs/1?limit=1&offset=0", status: http.StatusOK},
+		{name: "delete song", method: http.MethodDelete, path: "/api/v1/songs/1", status: http.StatusOK},
+		{name: "delete missing song", method: http.MethodDelete, path: "/api/v1/songs/404", status: http.StatusNotFound},
+		{name: "update song", method: http.MethodPatch, path: "/api/v1/songs/1", status: http.StatusOK,
+			body: `{"song": "Uprising", "releaseDate": "2009"}`},
+		{name: "update verse", method: http.MethodPatch, path: "/api/v1/songs/1/verse", status: http.StatusOK,
+			body: `{"verseNumber": 1, "verseText": "Paranoia is in bloom"}`},
+		{name: "new song", method: http.MethodPost, path: "/api/v1/songs/new", status: http.StatusCreated,
+			body: `{"group": "Muse", "song": "Supermassive Black Hole"}`},
+		{name: "new song idempotent", method: http.MethodPost, path: "/api/v1/songs/new", status: http.StatusCreated,
+			body: `{"group": "Muse", "song": "Supermassive Black Hole"}`, header: map[string]string{"Idempotency-Key": "7c4b0a9e"}},
+		{name: "new song async", method: http.MethodPost, path: "/api/v1/songs/new?async=true", status: http.StatusAccepted,
+			body: `{"group": "Muse", "song": "Supermassive Black Hole"}`},
+		{name: "create song with text", method: http.MethodPost, path: "/api/v1/songs", status: http.StatusCreated,
+			body: `{"group": "Muse", "song": "Uprising", "releaseDate": "2009", "text": "Paranoia is in bloom\n\nThe PR transmissions will resume"}`},
+		{name: "create song with verses", method: http.MethodPost, path: "/api/v1/songs", status: http.StatusCreated,
+			body: `{"group": "Muse", "song": "Uprising", "verses": ["Paranoia is in bloom"]}`},
+		{name: "job", method: http.MethodGet, path: "/api/v1/jobs/1", status: http.StatusOK},
+		{name: "batch", method: http.MethodPost, path: "/api/v1/batch", status: http.StatusOK,
+			body: `{"operations": [
+				{"op": "update_song", "songId": 1, "song": "Uprising", "link": "https://www.youtube.com/watch?v=w8KQmps-Sog"},
+				{"op": "update_verse", "songId": 1, "verseNumber": 2, "verseText": "Paranoia is in bloom", "version": 3},
+				{"op": "create_song", "group": "Muse", "song": "Resistance", "releaseDate": "2009", "verses": ["Is our secret safe tonight"]},
+				{"op": "delete_song", "songId": 2}
+			]}`},
+		{name: "batch rejected", method: http.MethodPost, path: "/api/v1/batch", status: http.StatusUnprocessableEntity,
+			body: `{"operations": [{"op": "update_verse", "songId": 404, "verseNumber": 1, "verseText": "Paranoia is in bloom"}]}`},
+		{name: "create webhook", method: http.MethodPost, path: "/api/v1/webhooks", status: http.StatusCreated,
+			body: `{"url": "https://example.com/hook", "event_types": ["song.created"]}`},
+		{name: "webhooks", method: http.MethodGet, path: "/api/v1/webhooks", status: http.StatusOK},
+		{name: "delete webhook", method: http.MethodDelete, path: "/api/v1/webhooks/1", status: http.StatusNoContent},
+		{name: "webhook deliveries", method: http.MethodGet, path: "/api/v1/webhooks/1/deliveries?status=failed&limit=10", status: http.StatusOK},
+		{name: "replay delivery", method: http.MethodPost, path: "/api/v1/webhooks/1/deliveries/1/replay", status: http.StatusAccepted},
+		{name: "v2 list songs", method: http.MethodGet, status: http.StatusOK,
+			path: "/api/v2/songs?group=Muse&released_from=2006-07&include=verses&limit=5"},
+		{name: "v2 song", method: http.MethodGet, path: "/api/v2/songs/1", status: http.StatusOK},
+		{name: "v2 update song", method: http.MethodPatch, path: "/api/v2/songs/1", status: http.StatusOK,
+			body: `{"song": "Uprising", "releaseDate": "2009-09"}`},
+		{name: "v2 update verse", method: http.MethodPatch, path: "/api/v2/songs/1/verses/2", status: http.StatusOK,
+			body: `{"text": "Paranoia is in bloom"}`},
+		{name: "v2 delete song", method: http.MethodDelete, path: "/api/v2/songs/1", status: http.StatusNoContent},
+		{name: "v2 new song", method: http.MethodPost, path: "/api/v2/songs/new", status: http.StatusCreated,
+			body: `{"group": "Muse", "song": "Supermassive Black Hole"}`},
+		{name: "v2 create song", method: http.MethodPost, path: "/api/v2/songs", status: http.StatusCreated,
+			body: `{"group": "Muse", "song": "Uprising", "releaseDate": "2009-09-07", "verses": ["Paranoia is in bloom"]}`},
+		{name: "v2 job", method: http.MethodGet, path: "/api/v2/jobs/1", status: http.StatusOK},
+		{name: "bearer key", method: http.MethodGet, path: "/api/v2/songs/1", status: http.StatusOK,
+			header: map[string]string{"Authorization": "Bearer read-key"}},
+		{name: "missing key", method: http.MethodGet, path: "/api/v1/songs", status: http.StatusUnauthorized,
+			header: map[string]string{"X-API-Key": ""}},
+		{name: "unknown key", method: http.MethodDelete, path: "/api/v2/songs/1", status: http.StatusUnauthorized,
+			header: map[string]string{"Authorization": "Bearer mlk_unknown"}},
+		{name: "read key changes song", method: http.MethodDelete, path: "/api/v1/songs/1", status: http.StatusForbidden,
+			header: map[string]string{"X-API-Key": "read-key"}},
+		{name: "read key lists webhooks", method: http.MethodGet, path: "/api/v1/webhooks", status: http.StatusForbidden,
+			header: map[string]string{"X-API-Key": "read-key"}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			violations = nil
+
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			_, hasKey := tc.header["X-API-Key"]
+			if _, bearer := tc.header["Authorization"]; !hasKey && !bearer {
+				req.Header.Set("X-API-Key", "admin-key")
+			}
+			for key, value := range tc.header {
+				req.Header.Set(key, value)
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != tc.status {
+				t.Errorf("status %d, want %d: %s", rec.Code, tc.status, rec.Body.String())
+			}
+			for _, violation := range violations {
+				t.Errorf("response contradicts spec: %s", violation)
+			}
+		})
+	}
+}
+
+// TestSpecRejectsUndocumented checks that requests outside of spec are answered with validation problem,
+// unknown query params are rejected only on strict paths
+func TestSpecRejectsUndocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+	validator, err := openapi.New(doc, openapi.Options{StrictQuery: []string{"/api/v2/"}}, logger.New())
+	if err != nil {
+		t.Fatalf("init validator: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(validator.Middleware())
+	endpoint.New(&fakeService{}, logger.New()).Register(router, false)
+
+	t.Run("unknown query parameter in v1", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/songs?genre=rock", nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("status %d, want 200 for v1 clients: %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	cases := []specCase{
+		{name: "unknown query parameter", method: http.MethodGet, path: "/api/v2/songs?genre=rock"},
+		{name: "wrong parameter type", method: http.MethodGet, path: "/api/v1/songs?limit=ten"},
+		{name: "wrong path parameter type", method: http.MethodGet, path: "/api/v1/jobs/first"},
+		{name: "wrong body field type", method: http.MethodPatch, path: "/api/v1/songs/1/verse",
+			body: `{"verseNumber": "one", "verseText": "Paranoia is in bloom"}`},
+		{name: "missing required body field", method: http.MethodPost, path: "/api/v1/songs/new",
+			body: `{"group": "Muse"}`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, strings.NewReader(tc.body))
+			if tc.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), `"validation_failed"`) {
+				t.Errorf("status %d, want validation problem: %s", rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+// TestRoutesDocumented fails when route is registered without swagger annotation or
+// documented operation has no handler
+func TestRoutesDocumented(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("load spec: %v", err)
+	}
+
+	router := gin.New()
+	endpoint.New(&fakeService{}, logger.New()).Register(router, false)
+
+	registered := make(map[string]bool)
+	for _, route := range router.Routes() {
+		path := strings.TrimPrefix(route.Path, "/api")
+		segments := strings.Split(path, "/")
+		for i, segment := range segments {
+			if strings.HasPrefix(segment, ":") {
+				segments[i] = "{" + segment[1:] + "}"
+			}
+		}
+		key := route.Method + " " + strings.Join(segments, "/")
+		registered[key] = true
+
+		item := doc.Paths.Find(strings.Join(segments, "/"))
+		if item == nil || item.GetOperation(route.Method) == nil {
+			t.Errorf("route %s is not documented", key)
+		}
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			if key := method + " " + path; !registered[key] {
+				t.Errorf("documented operation %s has no route", key)
+			}
+		}
+	}
+}
@@ -0,0 +1,452 @@
+package endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// bindStrictJSON decodes request body rejecting unknown fields
+func bindStrictJSON(c *gin.Context, dst any) error {
+	dec := json.NewDecoder(c.Request.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		//json package has no typed error for unknown fields
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return invalidField(strings.Trim(field, `"`), "unknown field")
+		}
+		return badRequest(err)
+	}
+	return nil
+}
+
+func pathID(c *gin.Context, name string) (int, error) {
+	id, err := strconv.Atoi(c.Param(name))
+	if err != nil || id < 1 {
+		return 0, invalidField(name, fmt.Sprintf("wrong format %s", name))
+	}
+	return id, nil
+}
+
+// @Summary List songs
+// @Schemes
+// @Description song list in v2 schema, filters, sorting and paging are the same as in v1, dates are ISO 8601
+// @Param   group      query     string     false  "group name"	example(Muse)
+// @Param   song      query     string     false  "song name"	example(Supermassive Black Hole)
+// @Param   text      query     string     false  "part of song text"	example(baby)
+// @Param   releaseDate      query     string     false  "release date"	example(2006-07-16)
+// @Param   updated_since      query     string     false  "songs changed since time (RFC 3339)"	example(2024-07-03T10:00:00Z)
+// @Param   released_from      query     string     false  "released on or after date, day, month or year"	example(2006)
+// @Param   released_to      query     string     false  "released on or before date, month or year is included whole"	example(2009-12)
+// @Param   year      query     int     false  "release year"	example(2006)
+// @Param   decade      query     int     false  "first year of release decade"	example(2000)
+// @Param   sort      query     string     false  "comma separated sort fields, - for descending: id, group, song, releaseDate, createdAt, updatedAt"	example(group,-releaseDate)
+// @Param   limit      query     int     false  "items limit"	example(10)
+// @Param   offset      query     int     false "offset items"	example(2)
+// @Param   cursor      query     string     false "nextCursor of previous page, can't be combined with offset"
+// @Param   include      query     string     false "verses to embed song lyrics"	example(verses)
+// @Param   fields      query     string     false "comma separated song attributes to return, id is always returned"	example(group,song)
+// @Param        If-None-Match   header      string  false  "ETag of cached page"
+// @Tags Songs v2
+// @Produce json
+// @Success 200 {object} endpoint.SongListV2
+// @Header 200 {string} ETag "page entity tag"
+// @Success 304 "Not Modified"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs [get]
+func (e *Endpoint) ListSongsV2Handler(c *gin.Context) {
+	fetchParams, fields, err := songListRequest(c, true, songListFieldsV2)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	songsResp, err := e.s.FetchSongs(c.Request.Context(), fetchParams)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if notModified(c, listETag(songsResp), time.Time{}) {
+		return
+	}
+
+	items := make([]SongV2, 0, len(songsResp.Songs))
+	for _, song := range songsResp.Songs {
+		items = append(items, songV2FromService(song))
+	}
+
+	if fields != nil {
+		c.JSON(http.StatusOK, SongListFieldsV2{Items: trimFields(items, fields), Total: songsResp.TotalCount, NextCursor: songsResp.NextCursor})
+		return
+	}
+
+	c.JSON(http.StatusOK, SongListV2{Items: items, Total: songsResp.TotalCount, NextCursor: songsResp.NextCursor})
+}
+
+// @Summary Song
+// @Schemes
+// @Description song with all verses, answers 304 when If-None-Match or If-Modified-Since show that song was not changed
+// @Param        id   path      int  true  "Song ID"
+// @Param        If-None-Match   header      string  false  "cached song ETag"
+// @Param        If-Modified-Since   header      string  false  "cached song Last-Modified"
+// @Tags Songs v2
+// @Produce json
+// @Success 200 {object} endpoint.SongV2
+// @Header 200 {string} ETag "song version"
+// @Header 200 {string} Last-Modified "song update time"
+// @Success 304 "Not Modified"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs/{id} [get]
+func (e *Endpoint) GetSongV2Handler(c *gin.Context) {
+	songID, err := pathID(c, "id")
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	song, err := e.s.FetchSong(c.Request.Context(), service.FetchSongRequest{SongID: songID, IncludeVerses: true})
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if notModified(c, etag(song.Version), song.UpdatedAt) {
+		return
+	}
+	c.JSON(http.StatusOK, songV2FromService(*song))
+}
+
+// @Summary Create song
+// @Schemes
+// @Description create song with lyrics without song info api, lyrics are passed as text with verses separated by empty line or as verse array
+// @Tags Songs v2
+// @Accept json
+// @Produce json
+// @Param request body endpoint.CreateSongV2 true "song with lyrics"
+// @Success 201 {object} endpoint.SongV2
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      409  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs [post]
+func (e *Endpoint) CreateSongV2Handler(c *gin.Context) {
+	var songData CreateSongV2
+	if err := bindStrictJSON(c, &songData); err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if err := e.validate.Struct(songData); err != nil {
+		e.writeError(c, validationError(err))
+		return
+	}
+
+	request := service.CreateSongRequest{
+		GroupName: songData.Group,
+		SongName:  songData.Song,
+		Link:      songData.Link,
+		Text:      songData.Text,
+		Verses:    songData.Verses,
+	}
+
+	if songData.ReleaseDate != "" {
+		releaseDate, err := dateparse.ParseISO(songData.ReleaseDate)
+		if err != nil {
+			e.writeError(c, invalidField("releaseDate", service.ISODateMessage))
+			return
+		}
+		request.ReleaseDate = &releaseDate
+	}
+
+	song, err := e.s.CreateSong(c.Request.Context(), request)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/v2/songs/%d", song.ID))
+	c.Header("ETag", etag(song.Version))
+	c.JSON(http.StatusCreated, songV2FromService(*song))
+}
+
+// @Summary New song
+// @Schemes
+// @Description create song from song info api, with async=true song is created in background and job is returned
+// @Tags Songs v2
+// @Accept json
+// @Produce json
+// @Param request body endpoint.NewSongV2 true "group and song"
+// @Param   async      query     bool     false  "create song in background"	example(true)
+// @Param        Idempotency-Key   header      string  false  "unique key of request, retry with the same key and body gets response of the first request"
+// @Success 201 {object} endpoint.SongV2
+// @Header 201 {string} Idempotent-Replayed "true when stored response of the key is replayed"
+// @Success 202 {object} endpoint.JobV2
+// @Header 202 {string} Idempotent-Replayed "true when stored response of the key is replayed"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      409  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Failure      502  {object}  problem.Problem
+// @Failure      504  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs/new [post]
+func (e *Endpoint) NewSongV2Handler(c *gin.Context) {
+	var songData NewSongV2
+	if err := bindStrictJSON(c, &songData); err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if err := e.validate.Struct(songData); err != nil {
+		e.writeError(c, validationError(err))
+		return
+	}
+
+	request := service.NewSongRequest{
+		GroupName: songData.Group,
+		SongName:  songData.Song,
+	}
+
+	if val, ok := c.GetQuery("async"); ok {
+		async, err := strconv.ParseBool(val)
+		if err != nil {
+			e.writeError(c, invalidField("async", "wrong format async: example true"))
+			return
+		}
+		if async {
+			job, err := e.s.NewSongAsync(c.Request.Context(), request)
+			if err != nil {
+				e.writeError(c, err)
+				return
+			}
+			c.Header("Location", fmt.Sprintf("/api/v2/jobs/%d", job.ID))
+			c.JSON(http.StatusAccepted, jobV2FromService(*job))
+			return
+		}
+	}
+
+	song, err := e.s.NewSong(c.Request.Context(), request)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/v2/songs/%d", song.ID))
+	c.Header("ETag", etag(song.Version))
+	c.JSON(http.StatusCreated, songV2FromService(*song))
+}
+
+// @Summary Edit song
+// @Schemes
+// @Description change passed song fields, with If-Match header song is changed only when its version equals ETag, otherwise 412 with current song is returned
+// @Tags Songs v2
+// @Accept json
+// @Produce json
+// @Param        id   path      int  true  "Song ID"
+// @Param        If-Match   header      string  false  "song ETag"
+// @Param request body endpoint.UpdateSongV2 true "changed fields"
+// @Success 200 {object} endpoint.SongV2
+// @Header 200 {string} ETag "new song version"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      412  {object}  endpoint.SongV2
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs/{id} [patch]
+func (e *Endpoint) UpdateSongV2Handler(c *gin.Context) {
+	songID, err := pathID(c, "id")
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	request := service.UpdateSongRequest{SongID: songID}
+	request.Version, err = ifMatchVersion(c)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	var songData UpdateSongV2
+	if err := bindStrictJSON(c, &songData); err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if err := e.validate.Struct(songData); err != nil {
+		e.writeError(c, validationError(err))
+		return
+	}
+
+	if songData.Group == nil && songData.Song == nil && songData.ReleaseDate == nil && songData.Link == nil {
+		e.writeError(c, invalidField("body", "no fields to change"))
+		return
+	}
+
+	request.GroupName = songData.Group
+	request.SongName = songData.Song
+	request.Link = songData.Link
+
+	if songData.ReleaseDate != nil {
+		releaseDate, err := dateparse.ParseISO(*songData.ReleaseDate)
+		if err != nil {
+			e.writeError(c, invalidField("releaseDate", service.ISODateMessage))
+			return
+		}
+		request.ReleaseDate = &releaseDate
+	}
+
+	resp, err := e.s.UpdateSong(c.Request.Context(), request)
+	if err != nil {
+		if !e.writeVersionMismatch(c, err, v2Representation) {
+			e.writeError(c, err)
+		}
+		return
+	}
+
+	c.Header("ETag", etag(resp.Song.Version))
+	c.JSON(http.StatusOK, songV2FromService(resp.Song))
+}
+
+// @Summary Edit verse
+// @Schemes
+// @Description change verse text, with If-Match header verse is changed only when song version equals song ETag, otherwise 412 with current song is returned
+// @Tags Songs v2
+// @Accept json
+// @Produce json
+// @Param        id   path      int  true  "Song ID"
+// @Param        number   path      int  true  "Verse number"
+// @Param        If-Match   header      string  false  "song ETag"
+// @Param request body endpoint.UpdateVerseV2 true "verse text"
+// @Success 200 {object} endpoint.VerseV2
+// @Header 200 {string} ETag "new song version"
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      412  {object}  endpoint.SongV2
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs/{id}/verses/{number} [patch]
+func (e *Endpoint) UpdateVerseV2Handler(c *gin.Context) {
+	songID, err := pathID(c, "id")
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	verseNumber, err := pathID(c, "number")
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	request := service.UpdateVerseRequest{SongID: songID, VerseNumber: verseNumber}
+	//verse has no own representation, ETag is version of song
+	request.SongVersion, err = ifMatchVersion(c)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	var verseData UpdateVerseV2
+	if err := bindStrictJSON(c, &verseData); err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if err := e.validate.Struct(verseData); err != nil {
+		e.writeError(c, validationError(err))
+		return
+	}
+	request.VerseText = *verseData.Text
+
+	resp, err := e.s.UpdateVerse(c.Request.Context(), request)
+	if err != nil {
+		if !e.writeVersionMismatch(c, err, v2Representation) {
+			e.writeError(c, err)
+		}
+		return
+	}
+
+	c.Header("ETag", etag(resp.SongVersion))
+	c.JSON(http.StatusOK, verseV2FromService(resp.Verse))
+}
+
+// @Summary Delete song
+// @Schemes
+// @Description deleting song with lyrics
+// @Tags Songs v2
+// @Param        id   path      int  true  "Song ID"
+// @Success 	 204
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/songs/{id} [delete]
+func (e *Endpoint) DeleteSongV2Handler(c *gin.Context) {
+	songID, err := pathID(c, "id")
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	if _, err := e.s.DeleteSong(c.Request.Context(), service.DeleteSongRequest{SongID: songID}); err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Job status
+// @Schemes
+// @Description status of async song creation
+// @Param        id   path      int  true  "Job ID"
+// @Tags Jobs v2
+// @Produce json
+// @Success 200 {object} endpoint.JobV2
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v2/jobs/{id} [get]
+func (e *Endpoint) FetchJobV2Handler(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
+	}
+
+	job, err := e.s.FetchJob(c.Request.Context(), service.FetchJobRequest{JobID: jobID})
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, jobV2FromService(*job))
+}
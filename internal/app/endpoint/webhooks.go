@@ -0,0 +1,193 @@
+package endpoint
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Create webhook
+// @Schemes
+// @Description subscribe url to library change events (song.created, song.updated, song.deleted, verse.updated), empty event_types means all events. Deliveries are signed with secret, it is returned only in this response
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param request body endpoint.CreateWebhook true "webhook subscription"
+// @Success 201 {object} service.Webhook
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/webhooks [post]
+func (e *Endpoint) CreateWebhookHandler(c *gin.Context) {
+	var hookData CreateWebhook
+
+	if err := c.ShouldBindJSON(&hookData); err != nil {
+		e.writeError(c, badRequest(err))
+		return
+	}
+
+	if err := e.validate.Struct(hookData); err != nil {
+		e.writeError(c, validationError(err))
+		return
+	}
+
+	hook, err := e.s.CreateWebhook(c.Request.Context(), service.CreateWebhookRequest{
+		URL:        hookData.URL,
+		Secret:     hookData.Secret,
+		EventTypes: hookData.EventTypes,
+	})
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+
+	c.Header("Location", fmt.Sprintf("/api/v1/webhooks/%d", hook.ID))
+	c.JSON(http.StatusCreated, hook)
+}
+
+// @Summary Webhooks
+// @Schemes
+// @Description list webhook subscriptions, secrets are not returned
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {array} service.Webhook
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/webhooks [get]
+func (e *Endpoint) FetchWebhooksHandler(c *gin.Context) {
+	hooks, err := e.s.FetchWebhooks(c.Request.Context())
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, hooks)
+}
+
+// @Summary Delete webhook
+// @Schemes
+// @Description delete webhook subscription with its delivery log
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param        id   path      int  true  "Webhook ID"
+// @Success 204
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id} [delete]
+func (e *Endpoint) DeleteWebhookHandler(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
+	}
+
+	if err := e.s.DeleteWebhook(c.Request.Context(), service.DeleteWebhookRequest{WebhookID: webhookID}); err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// @Summary Webhook deliveries
+// @Schemes
+// @Description delivery log of webhook, newest first
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param        id   path      int  true  "Webhook ID"
+// @Param   status      query     string     false  "delivery status"	Enums(pending, running, delivered, failed)
+// @Param   limit      query     int     false  "items limit"	example(10)
+// @Param   offset      query     int     false "offset items"	example(2)
+// @Success 200 {array} service.WebhookDelivery
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id}/deliveries [get]
+func (e *Endpoint) FetchWebhookDeliveriesHandler(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
+	}
+
+	request := service.FetchWebhookDeliveriesRequest{WebhookID: webhookID}
+
+	if val, ok := c.GetQuery("status"); ok {
+		request.Status = &val
+	}
+
+	if val, ok := c.GetQuery("limit"); ok {
+		if intval, err := strconv.ParseUint(val, 10, 64); err == nil {
+			request.Limit = intval
+		}
+	}
+
+	if val, ok := c.GetQuery("offset"); ok {
+		if intval, err := strconv.ParseUint(val, 10, 64); err == nil {
+			request.Offset = intval
+		}
+	}
+
+	deliveries, err := e.s.FetchWebhookDeliveries(c.Request.Context(), request)
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// @Summary Replay webhook delivery
+// @Schemes
+// @Description queue failed delivery again with the same payload, new delivery refers to original by replay_of
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Param        id   path      int  true  "Webhook ID"
+// @Param        delivery_id   path      int  true  "Delivery ID"
+// @Success 202 {object} service.WebhookDelivery
+// @Failure      400  {object}  problem.Problem
+// @Failure      401  {object}  problem.Problem
+// @Failure      403  {object}  problem.Problem
+// @Failure      404  {object}  problem.Problem
+// @Failure      409  {object}  problem.Problem
+// @Failure      500  {object}  problem.Problem
+// @Security ApiKeyAuth
+// @Router /v1/webhooks/{id}/deliveries/{delivery_id}/replay [post]
+func (e *Endpoint) ReplayWebhookDeliveryHandler(c *gin.Context) {
+	webhookID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		e.writeError(c, invalidField("id", "wrong format id"))
+		return
+	}
+
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		e.writeError(c, invalidField("delivery_id", "wrong format delivery_id"))
+		return
+	}
+
+	delivery, err := e.s.ReplayWebhookDelivery(c.Request.Context(), service.ReplayWebhookDeliveryRequest{
+		WebhookID:  webhookID,
+		DeliveryID: deliveryID,
+	})
+	if err != nil {
+		e.writeError(c, err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
@@ -0,0 +1,70 @@
+package graph
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// resolverError exposes problem code, status and field errors
+// of service error as graphql error extensions
+type resolverError struct {
+	err    error
+	appErr *problem.Error
+}
+
+func gqlError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *problem.Error
+	if !errors.As(err, &appErr) {
+		appErr = problem.ErrInternal
+	}
+	return &resolverError{err: err, appErr: appErr}
+}
+
+func (e *resolverError) Error() string {
+	//unknown errors become internal error without leaking details
+	if e.appErr == problem.ErrInternal {
+		return e.appErr.Message
+	}
+	return e.err.Error()
+}
+
+func (e *resolverError) Unwrap() error {
+	return e.err
+}
+
+func (e *resolverError) Extensions() map[string]interface{} {
+	ext := map[string]interface{}{
+		"code":   e.appErr.Code,
+		"status": endpoint.StatusCode(e.appErr),
+	}
+	if len(e.appErr.Fields) > 0 {
+		ext["errors"] = e.appErr.Fields
+	}
+
+	var mismatch *service.VersionMismatchError
+	if errors.As(e.err, &mismatch) {
+		switch {
+		case mismatch.Song != nil:
+			ext["currentVersion"] = mismatch.Song.Version
+		case mismatch.Verse != nil:
+			ext["currentVersion"] = mismatch.Verse.Version
+		}
+	}
+	return ext
+}
+
+func (e *resolverError) internal() bool {
+	return endpoint.StatusCode(e.appErr) >= http.StatusInternalServerError
+}
+
+func invalidField(field, message string) error {
+	return gqlError(problem.Validation(problem.FieldError{Field: field, Message: message}))
+}
@@ -0,0 +1,76 @@
+package graph
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var schema string
+
+// maxDepth limits nesting of group { songs { group { songs ... } } } queries
+const maxDepth = 8
+
+// Handler serves graphql queries over http
+type Handler struct {
+	schema *graphql.Schema
+	s      service.MusicService
+	log    *logger.Logger
+}
+
+func NewHandler(s service.MusicService, log *logger.Logger) *Handler {
+	return &Handler{
+		schema: graphql.MustParseSchema(schema, &Resolver{s: s},
+			graphql.UseStringDescriptions(),
+			graphql.MaxDepth(maxDepth),
+		),
+		s:   s,
+		log: log,
+	}
+}
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var req request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Query == "" {
+		h.write(w, http.StatusBadRequest, &graphql.Response{
+			Errors: []*gqlerrors.QueryError{{Message: "request body must be json with query"}},
+		})
+		return
+	}
+
+	//loaders cache only lives during one request
+	ctx := withLoaders(r.Context(), newLoaders(h.s))
+	resp := h.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+
+	for _, queryErr := range resp.Errors {
+		var resolverErr *resolverError
+		if errors.As(queryErr.ResolverError, &resolverErr) && resolverErr.internal() {
+			h.log.Error("graph | request failed",
+				"path", queryErr.Path,
+				"error", resolverErr.err.Error())
+		}
+	}
+
+	h.write(w, http.StatusOK, resp)
+}
+
+func (h *Handler) write(w http.ResponseWriter, status int, resp *graphql.Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.log.Error("graph | write response", "error", err.Error())
+	}
+}
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/graph-gophers/dataloader/v7"
+)
+
+type loadersKey struct{}
+
+// loaders batch nested lookups of one request, so a page of songs
+// costs one query for verses and one for groups instead of one per song
+type loaders struct {
+	verses     *dataloader.Loader[pageKey, []service.VerseSmall]
+	groups     *dataloader.Loader[string, *service.Group]
+	groupSongs *dataloader.Loader[pageKey, []service.Song]
+}
+
+// pageKey is owner id of nested list with its first argument, 0 loads all items
+type pageKey struct {
+	ID    int
+	First int
+}
+
+func newLoaders(s service.MusicService) *loaders {
+	return &loaders{
+		verses:     dataloader.NewBatchedLoader(versesBatch(s)),
+		groups:     dataloader.NewBatchedLoader(groupsBatch(s)),
+		groupSongs: dataloader.NewBatchedLoader(groupSongsBatch(s)),
+	}
+}
+
+func withLoaders(ctx context.Context, l *loaders) context.Context {
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+func versesBatch(s service.MusicService) dataloader.BatchFunc[pageKey, []service.VerseSmall] {
+	return func(ctx context.Context, keys []pageKey) []*dataloader.Result[[]service.VerseSmall] {
+		return pagesBatch(keys, func(songIDs []int, first int) (map[int][]service.VerseSmall, error) {
+			return s.FetchSongsVerses(ctx, songIDs, first)
+		})
+	}
+}
+
+func groupsBatch(s service.MusicService) dataloader.BatchFunc[string, *service.Group] {
+	return func(ctx context.Context, names []string) []*dataloader.Result[*service.Group] {
+		results := make([]*dataloader.Result[*service.Group], len(names))
+
+		groups, err := s.FetchGroupsByName(ctx, names)
+		byName := make(map[string]*service.Group, len(groups))
+		for i := range groups {
+			byName[groups[i].Name] = &groups[i]
+		}
+		for i, name := range names {
+			results[i] = &dataloader.Result[*service.Group]{Data: byName[name], Error: err}
+		}
+		return results
+	}
+}
+
+func groupSongsBatch(s service.MusicService) dataloader.BatchFunc[pageKey, []service.Song] {
+	return func(ctx context.Context, keys []pageKey) []*dataloader.Result[[]service.Song] {
+		return pagesBatch(keys, func(groupIDs []int, first int) (map[int][]service.Song, error) {
+			return s.FetchGroupsSongs(ctx, groupIDs, first)
+		})
+	}
+}
+
+// pagesBatch loads nested lists with one query per distinct first argument,
+// usually all keys of batch come from the same field and share it
+func pagesBatch[T any](keys []pageKey, load func(ids []int, first int) (map[int][]T, error)) []*dataloader.Result[[]T] {
+	results := make([]*dataloader.Result[[]T], len(keys))
+
+	ids := make(map[int][]int)
+	for _, key := range keys {
+		ids[key.First] = append(ids[key.First], key.ID)
+	}
+
+	for first, batch := range ids {
+		items, err := load(batch, first)
+		for i, key := range keys {
+			if key.First == first {
+				results[i] = &dataloader.Result[[]T]{Data: items[key.ID], Error: err}
+			}
+		}
+	}
+	return results
+}
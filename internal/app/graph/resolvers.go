@@ -0,0 +1,413 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Resolver is root of query and mutation resolvers
+type Resolver struct {
+	s service.MusicService
+}
+
+type songFilterInput struct {
+	Group        *string
+	Song         *string
+	Text         *string
+	ReleaseDate  *string
+	UpdatedSince *graphql.Time
+	ReleasedFrom *string
+	ReleasedTo   *string
+	Year         *int32
+	Decade       *int32
+}
+
+type updateSongInput struct {
+	Group       *string
+	Song        *string
+	ReleaseDate *string
+	Link        *string
+	Version     *int32
+}
+
+func (r *Resolver) Group(ctx context.Context, args struct{ Name string }) (*groupResolver, error) {
+	group, err := loadersFrom(ctx).groups.Load(ctx, args.Name)()
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	if group == nil {
+		return nil, nil
+	}
+	return &groupResolver{group: *group}, nil
+}
+
+func (r *Resolver) Groups(ctx context.Context, args struct {
+	Name   *string
+	Limit  *int32
+	Offset *int32
+}) ([]*groupResolver, error) {
+	request := service.FetchGroupsRequest{Name: args.Name}
+	if args.Limit != nil && *args.Limit > 0 {
+		request.Limit = uint64(*args.Limit)
+	}
+	if args.Offset != nil && *args.Offset > 0 {
+		request.Offset = uint64(*args.Offset)
+	}
+
+	groups, err := r.s.FetchGroups(ctx, request)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	result := make([]*groupResolver, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, &groupResolver{group: group})
+	}
+	return result, nil
+}
+
+func (r *Resolver) Song(ctx context.Context, args struct{ ID graphql.ID }) (*songResolver, error) {
+	songID, err := parseID("id", args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	song, err := r.s.FetchSong(ctx, service.FetchSongRequest{SongID: songID})
+	if err != nil {
+		if errors.Is(err, service.ErrSongNotFound) {
+			return nil, nil
+		}
+		return nil, gqlError(err)
+	}
+	return &songResolver{song: *song}, nil
+}
+
+func (r *Resolver) Songs(ctx context.Context, args struct {
+	Filter *songFilterInput
+	Sort   *[]string
+	Limit  *int32
+	Offset *int32
+	Cursor *string
+}) (*songListResolver, error) {
+	var request service.FetchSongsRequest
+
+	if args.Filter != nil {
+		filter, err := songFilter(*args.Filter)
+		if err != nil {
+			return nil, err
+		}
+		request.SongFilter = filter
+	}
+
+	if args.Sort != nil && len(*args.Sort) > 0 {
+		sort, err := service.ParseSort(strings.Join(*args.Sort, ","))
+		if err != nil {
+			return nil, gqlError(err)
+		}
+		request.Sort = sort
+	}
+
+	if args.Limit != nil && *args.Limit > 0 {
+		request.Limit = uint64(*args.Limit)
+	}
+	if args.Offset != nil && *args.Offset > 0 {
+		request.Offset = uint64(*args.Offset)
+	}
+
+	if args.Cursor != nil {
+		if request.Offset > 0 {
+			return nil, invalidField("cursor", "cursor can't be combined with offset")
+		}
+		request.Cursor = *args.Cursor
+	}
+
+	resp, err := r.s.FetchSongs(ctx, request)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	return &songListResolver{resp: resp}, nil
+}
+
+func (r *Resolver) UpdateSong(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input updateSongInput
+}) (*songResolver, error) {
+	if err := service.CheckScope(ctx, service.ScopeWrite); err != nil {
+		return nil, gqlError(err)
+	}
+	songID, err := parseID("id", args.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	input := args.Input
+	if input.Group == nil && input.Song == nil && input.ReleaseDate == nil && input.Link == nil {
+		return nil, invalidField("input", "no fields to change")
+	}
+
+	request := service.UpdateSongRequest{
+		SongID:    songID,
+		GroupName: input.Group,
+		SongName:  input.Song,
+		Link:      input.Link,
+		Version:   service.OptionalInt(input.Version),
+	}
+
+	if input.ReleaseDate != nil {
+		releaseDate, err := dateparse.ParseISO(*input.ReleaseDate)
+		if err != nil {
+			return nil, invalidField("releaseDate", service.ISODateMessage)
+		}
+		request.ReleaseDate = &releaseDate
+	}
+
+	resp, err := r.s.UpdateSong(ctx, request)
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	return &songResolver{song: resp.Song}, nil
+}
+
+func (r *Resolver) UpdateVerse(ctx context.Context, args struct {
+	SongID  graphql.ID
+	Number  int32
+	Text    string
+	Version *int32
+}) (*verseResolver, error) {
+	if err := service.CheckScope(ctx, service.ScopeWrite); err != nil {
+		return nil, gqlError(err)
+	}
+	songID, err := parseID("songId", args.SongID)
+	if err != nil {
+		return nil, err
+	}
+	if args.Number < 1 {
+		return nil, invalidField("number", "number must be a positive integer")
+	}
+	if args.Text == "" {
+		return nil, invalidField("text", "text is required")
+	}
+
+	resp, err := r.s.UpdateVerse(ctx, service.UpdateVerseRequest{
+		SongID:      songID,
+		VerseNumber: int(args.Number),
+		VerseText:   args.Text,
+		Version:     service.OptionalInt(args.Version),
+	})
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	return &verseResolver{verse: resp.Verse}, nil
+}
+
+func (r *Resolver) DeleteSong(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	if err := service.CheckScope(ctx, service.ScopeWrite); err != nil {
+		return false, gqlError(err)
+	}
+	songID, err := parseID("id", args.ID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := r.s.DeleteSong(ctx, service.DeleteSongRequest{SongID: songID}); err != nil {
+		return false, gqlError(err)
+	}
+	return true, nil
+}
+
+type groupResolver struct {
+	group service.Group
+}
+
+func (r *groupResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.group.ID))
+}
+
+func (r *groupResolver) Name() string {
+	return r.group.Name
+}
+
+func (r *groupResolver) Songs(ctx context.Context, args struct{ First *int32 }) ([]*songResolver, error) {
+	if args.First != nil && *args.First == 0 {
+		return []*songResolver{}, nil
+	}
+	songs, err := loadersFrom(ctx).groupSongs.Load(ctx, pageKey{ID: r.group.ID, First: first(args.First)})()
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	result := make([]*songResolver, 0, len(songs))
+	for _, song := range songs {
+		result = append(result, &songResolver{song: song})
+	}
+	return result, nil
+}
+
+type songResolver struct {
+	song service.Song
+}
+
+func (r *songResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(r.song.ID))
+}
+
+func (r *songResolver) Group(ctx context.Context) (*groupResolver, error) {
+	group, err := loadersFrom(ctx).groups.Load(ctx, r.song.GroupName)()
+	if err != nil {
+		return nil, gqlError(err)
+	}
+	if group == nil {
+		return nil, gqlError(service.ErrGroupNotFound)
+	}
+	return &groupResolver{group: *group}, nil
+}
+
+func (r *songResolver) Name() string {
+	return r.song.SongName
+}
+
+func (r *songResolver) ReleaseDate() *string {
+	if r.song.ReleaseDate == nil {
+		return nil
+	}
+	releaseDate := dateparse.Date{
+		Time:      *r.song.ReleaseDate,
+		Precision: dateparse.Precision(r.song.ReleaseDatePrecision),
+	}.ISO()
+	return &releaseDate
+}
+
+func (r *songResolver) Link() string {
+	return r.song.Link
+}
+
+func (r *songResolver) Version() int32 {
+	return int32(r.song.Version)
+}
+
+func (r *songResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.song.CreatedAt}
+}
+
+func (r *songResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.song.UpdatedAt}
+}
+
+func (r *songResolver) Verses(ctx context.Context, args struct{ First *int32 }) ([]*verseResolver, error) {
+	if args.First != nil && *args.First == 0 {
+		return []*verseResolver{}, nil
+	}
+	verses, err := loadersFrom(ctx).verses.Load(ctx, pageKey{ID: r.song.ID, First: first(args.First)})()
+	if err != nil {
+		return nil, gqlError(err)
+	}
+
+	result := make([]*verseResolver, 0, len(verses))
+	for _, verse := range verses {
+		result = append(result, &verseResolver{verse: verse})
+	}
+	return result, nil
+}
+
+type verseResolver struct {
+	verse service.VerseSmall
+}
+
+func (r *verseResolver) Number() int32 {
+	return int32(r.verse.VerseNumber)
+}
+
+func (r *verseResolver) Text() string {
+	return r.verse.VerseText
+}
+
+func (r *verseResolver) Version() int32 {
+	return int32(r.verse.Version)
+}
+
+func (r *verseResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.verse.CreatedAt}
+}
+
+func (r *verseResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.verse.UpdatedAt}
+}
+
+type songListResolver struct {
+	resp *service.FetchSongsResponse
+}
+
+func (r *songListResolver) Items() []*songResolver {
+	result := make([]*songResolver, 0, len(r.resp.Songs))
+	for _, song := range r.resp.Songs {
+		result = append(result, &songResolver{song: song})
+	}
+	return result
+}
+
+func (r *songListResolver) Total() int32 {
+	return int32(r.resp.TotalCount)
+}
+
+func (r *songListResolver) NextCursor() *string {
+	if r.resp.NextCursor == "" {
+		return nil
+	}
+	return &r.resp.NextCursor
+}
+
+// filterNames are filter argument names reported in validation errors
+var filterNames = service.FilterNames{
+	ReleaseDate:  "releaseDate",
+	ReleasedFrom: "releasedFrom",
+	ReleasedTo:   "releasedTo",
+	Year:         "year",
+	Decade:       "decade",
+}
+
+// songFilter reads list filter, dates are accepted in ISO 8601 form like in api v2
+func songFilter(input songFilterInput) (service.SongFilter, error) {
+	params := service.SongFilterParams{
+		GroupName:    input.Group,
+		SongName:     input.Song,
+		SongText:     input.Text,
+		ReleaseDate:  input.ReleaseDate,
+		ReleasedFrom: input.ReleasedFrom,
+		ReleasedTo:   input.ReleasedTo,
+		Year:         service.OptionalInt(input.Year),
+		Decade:       service.OptionalInt(input.Decade),
+		ISO:          true,
+		Names:        filterNames,
+	}
+	if input.UpdatedSince != nil {
+		params.UpdatedSince = &input.UpdatedSince.Time
+	}
+
+	filter, err := service.NewSongFilter(params)
+	if err != nil {
+		return filter, gqlError(err)
+	}
+	return filter, nil
+}
+
+func parseID(field string, id graphql.ID) (int, error) {
+	value, err := strconv.Atoi(string(id))
+	if err != nil || value < 1 {
+		return 0, invalidField(field, "wrong format "+field)
+	}
+	return value, nil
+}
+
+// first converts first argument of nested list to loader limit, 0 loads all items
+func first(n *int32) int {
+	if n == nil || *n < 0 {
+		return 0
+	}
+	return int(*n)
+}
@@ -0,0 +1,79 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/app/graph"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// songService returns song 1 and records updates
+type songService struct {
+	service.MusicService
+	updated bool
+}
+
+func (f *songService) FetchSong(ctx context.Context, request service.FetchSongRequest) (*service.Song, error) {
+	return &service.Song{ID: request.SongID, GroupName: "Muse", SongName: "Uprising", Version: 1}, nil
+}
+
+func (f *songService) UpdateSong(ctx context.Context, request service.UpdateSongRequest) (service.UpdateSongResponse, error) {
+	f.updated = true
+	song := service.Song{ID: request.SongID, GroupName: "Muse", SongName: *request.SongName, Version: 2}
+	return service.UpdateSongResponse{Success: true, Version: 2, Song: song}, nil
+}
+
+// TestScopes checks that read key can query songs and can not change them
+func TestScopes(t *testing.T) {
+	cases := []struct {
+		name      string
+		query     string
+		forbidden bool
+	}{
+		{name: "song", query: `{ song(id: "1") { name } }`},
+		{name: "updateSong", query: `mutation { updateSong(id: "1", input: {song: "Starlight"}) { name } }`, forbidden: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &songService{}
+			handler := graph.NewHandler(s, logger.New())
+
+			body, _ := json.Marshal(map[string]string{"query": tc.query})
+			req := httptest.NewRequest(http.MethodPost, "/api/graphql", strings.NewReader(string(body)))
+			req = req.WithContext(service.WithAPIKey(req.Context(), &service.APIKey{ID: "reader", Scopes: []string{service.ScopeRead}}))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			var resp struct {
+				Data   map[string]any `json:"data"`
+				Errors []struct {
+					Extensions map[string]any `json:"extensions"`
+				} `json:"errors"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("decode response: %v: %s", err, rec.Body.String())
+			}
+
+			if !tc.forbidden {
+				if len(resp.Errors) > 0 || resp.Data[tc.name] == nil {
+					t.Errorf("read key is refused: %s", rec.Body.String())
+				}
+				return
+			}
+			if len(resp.Errors) != 1 || resp.Errors[0].Extensions["code"] != string(problem.CodeForbidden) {
+				t.Errorf("errors %+v, want %s: %s", resp.Errors, problem.CodeForbidden, rec.Body.String())
+			}
+			if s.updated {
+				t.Errorf("song is updated with read key")
+			}
+		})
+	}
+}
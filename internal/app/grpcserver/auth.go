@@ -0,0 +1,82 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	musicv1 "github.com/Vic07Region/musicLibrary/api/music/v1"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// methodScopes are api key scopes required by methods, unknown methods require admin
+var methodScopes = map[string]string{
+	musicv1.MusicService_FetchSongs_FullMethodName:  service.ScopeRead,
+	musicv1.MusicService_FetchVerses_FullMethodName: service.ScopeRead,
+	musicv1.MusicService_NewSong_FullMethodName:     service.ScopeWrite,
+	musicv1.MusicService_UpdateSong_FullMethodName:  service.ScopeWrite,
+	musicv1.MusicService_UpdateVerse_FullMethodName: service.ScopeWrite,
+	musicv1.MusicService_DeleteSong_FullMethodName:  service.ScopeWrite,
+}
+
+// unaryAuth authenticates unary calls like endpoint.RequireScope does for http
+func (s *Server) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamAuth authenticates streaming calls
+func (s *Server) streamAuth(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: ctx})
+}
+
+// authorize checks api key sent as authorization: Bearer <key> or x-api-key metadata,
+// returns context with authenticated key
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		token = values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if bearer, ok := strings.CutPrefix(values[0], "Bearer "); ok {
+			token = strings.TrimSpace(bearer)
+		}
+	}
+	if token == "" {
+		return nil, statusError(service.ErrUnauthorized)
+	}
+
+	key, err := s.s.Authenticate(ctx, token)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	scope, ok := methodScopes[method]
+	if !ok {
+		scope = service.ScopeAdmin
+	}
+	if !key.HasScope(scope) {
+		s.log.Warn("grpcserver.authorize | scope denied", "key_id", key.ID, "scope", scope, "method", method)
+		return nil, statusError(service.ErrForbidden)
+	}
+	return service.WithAPIKey(ctx, key), nil
+}
+
+// authStream is server stream with context of authenticated key
+type authStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authStream) Context() context.Context {
+	return s.ctx
+}
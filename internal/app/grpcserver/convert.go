@@ -0,0 +1,79 @@
+package grpcserver
+
+import (
+	musicv1 "github.com/Vic07Region/musicLibrary/api/music/v1"
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// filterNames are names of FetchSongsRequest filter fields
+var filterNames = service.FilterNames{
+	ReleaseDate:  "release_date",
+	ReleasedFrom: "released_from",
+	ReleasedTo:   "released_to",
+	Year:         "year",
+	Decade:       "decade",
+}
+
+// songFilter reads list filter, dates are accepted in ISO 8601 form like in api v2
+func songFilter(req *musicv1.FetchSongsRequest) (service.SongFilter, error) {
+	params := service.SongFilterParams{
+		GroupName:    req.GroupName,
+		SongName:     req.SongName,
+		SongText:     req.Text,
+		ReleaseDate:  req.ReleaseDate,
+		ReleasedFrom: req.ReleasedFrom,
+		ReleasedTo:   req.ReleasedTo,
+		Year:         service.OptionalInt(req.Year),
+		Decade:       service.OptionalInt(req.Decade),
+		ISO:          true,
+		Names:        filterNames,
+	}
+
+	if req.UpdatedSince != nil {
+		if err := req.UpdatedSince.CheckValid(); err != nil {
+			return service.SongFilter{}, invalidField("updated_since", err.Error())
+		}
+		since := req.UpdatedSince.AsTime()
+		params.UpdatedSince = &since
+	}
+
+	return service.NewSongFilter(params)
+}
+
+func songToProto(song service.Song) *musicv1.Song {
+	result := &musicv1.Song{
+		Id:        int64(song.ID),
+		GroupName: song.GroupName,
+		SongName:  song.SongName,
+		Link:      song.Link,
+		Version:   int32(song.Version),
+		CreatedAt: timestamppb.New(song.CreatedAt),
+		UpdatedAt: timestamppb.New(song.UpdatedAt),
+	}
+
+	if song.ReleaseDate != nil {
+		releaseDate := dateparse.Date{
+			Time:      *song.ReleaseDate,
+			Precision: dateparse.Precision(song.ReleaseDatePrecision),
+		}.ISO()
+		result.ReleaseDate = &releaseDate
+	}
+
+	for _, verse := range song.Verses {
+		result.Verses = append(result.Verses, verseToProto(verse))
+	}
+
+	return result
+}
+
+func verseToProto(verse service.VerseSmall) *musicv1.Verse {
+	return &musicv1.Verse{
+		Number:    int32(verse.VerseNumber),
+		Text:      verse.VerseText,
+		Version:   int32(verse.Version),
+		CreatedAt: timestamppb.New(verse.CreatedAt),
+		UpdatedAt: timestamppb.New(verse.UpdatedAt),
+	}
+}
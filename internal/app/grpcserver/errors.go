@@ -0,0 +1,123 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// statusCodes maps problem codes of service errors to grpc codes
+var statusCodes = map[problem.Code]codes.Code{
+	problem.CodeBadRequest:        codes.InvalidArgument,
+	problem.CodeValidation:        codes.InvalidArgument,
+	problem.CodeUnauthorized:      codes.Unauthenticated,
+	problem.CodeForbidden:         codes.PermissionDenied,
+	problem.CodeNotFound:          codes.NotFound,
+	problem.CodeSongNotFound:      codes.NotFound,
+	problem.CodeGroupNotFound:     codes.NotFound,
+	problem.CodeJobNotFound:       codes.NotFound,
+	problem.CodeWebhookNotFound:   codes.NotFound,
+	problem.CodeDeliveryNotFound:  codes.NotFound,
+	problem.CodeAPIKeyNotFound:    codes.NotFound,
+	problem.CodeNoSongs:           codes.NotFound,
+	problem.CodeDeliveryNotFailed: codes.AlreadyExists,
+	problem.CodeIdempotencyReused: codes.AlreadyExists,
+	problem.CodeIdempotencyBusy:   codes.AlreadyExists,
+	problem.CodeSongExists:        codes.AlreadyExists,
+	problem.CodeVersionMismatch:   codes.FailedPrecondition,
+	problem.CodeBadReleaseDate:    codes.Unavailable,
+	problem.CodeProviderFailed:    codes.Unavailable,
+	problem.CodeStorageFailed:     codes.Unavailable,
+	problem.CodeTimeout:           codes.DeadlineExceeded,
+	problem.CodeInternal:          codes.Internal,
+}
+
+// statusError converts service error to grpc status error,
+// problem code is sent as ErrorInfo reason and field errors as BadRequest details
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	}
+
+	//unknown errors become internal error without leaking details
+	message := err.Error()
+	var appErr *problem.Error
+	if !errors.As(err, &appErr) {
+		appErr = problem.ErrInternal
+		message = appErr.Message
+	}
+
+	code, ok := statusCodes[appErr.Code]
+	if !ok {
+		code = codes.Unknown
+	}
+
+	st := status.New(code, message)
+
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason: string(appErr.Code),
+		Domain: "musicLibrary",
+	}}
+	if len(appErr.Fields) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, field := range appErr.Fields {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       field.Field,
+				Description: field.Message,
+			})
+		}
+		details = append(details, badRequest)
+	}
+
+	withDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
+
+func invalidField(field, message string) error {
+	return problem.Validation(problem.FieldError{Field: field, Message: message})
+}
+
+// unaryLogger logs failed unary calls like endpoint.writeError does for http
+func (s *Server) unaryLogger(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	s.logFailure(ctx, info.FullMethod, err)
+	return resp, err
+}
+
+// streamLogger logs failed streaming calls
+func (s *Server) streamLogger(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	s.logFailure(ss.Context(), info.FullMethod, err)
+	return err
+}
+
+func (s *Server) logFailure(ctx context.Context, method string, err error) {
+	if err == nil {
+		return
+	}
+	var keyID string
+	if key := service.APIKeyFromContext(ctx); key != nil {
+		keyID = key.ID
+	}
+	switch status.Code(err) {
+	case codes.Internal, codes.Unavailable, codes.DeadlineExceeded, codes.Unknown:
+		s.log.Error("grpcserver | request failed",
+			"method", method,
+			"code", status.Code(err).String(),
+			"key_id", keyID,
+			"error", err.Error())
+	}
+}
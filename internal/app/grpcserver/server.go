@@ -0,0 +1,223 @@
+package grpcserver
+
+import (
+	"context"
+	"strings"
+
+	musicv1 "github.com/Vic07Region/musicLibrary/api/music/v1"
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+	"google.golang.org/grpc"
+)
+
+// versesPageSize is count of verses fetched from service per stream step
+const versesPageSize = 50
+
+// Server implements musicv1.MusicServiceServer over service layer
+type Server struct {
+	musicv1.UnimplementedMusicServiceServer
+	s   service.MusicService
+	log *logger.Logger
+}
+
+func New(s service.MusicService, log *logger.Logger) *Server {
+	return &Server{s: s, log: log}
+}
+
+// NewGRPCServer returns grpc server with registered music service,
+// with auth calls require api key with scope of method
+func NewGRPCServer(s service.MusicService, log *logger.Logger, auth bool) *grpc.Server {
+	srv := New(s, log)
+	//logger runs after auth so failures are logged with authenticated key,
+	//failed authentication is logged by service
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if auth {
+		unary = append(unary, srv.unaryAuth)
+		stream = append(stream, srv.streamAuth)
+	}
+	unary = append(unary, srv.unaryLogger)
+	stream = append(stream, srv.streamLogger)
+	g := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	)
+	musicv1.RegisterMusicServiceServer(g, srv)
+	return g
+}
+
+func (s *Server) FetchSongs(ctx context.Context, req *musicv1.FetchSongsRequest) (*musicv1.FetchSongsResponse, error) {
+	filter, err := songFilter(req)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	request := service.FetchSongsRequest{
+		SongFilter:    filter,
+		Cursor:        req.GetCursor(),
+		IncludeVerses: req.GetIncludeVerses(),
+		Limit:         req.GetLimit(),
+		Offset:        req.GetOffset(),
+	}
+
+	if len(req.GetSort()) > 0 {
+		request.Sort, err = service.ParseSort(strings.Join(req.GetSort(), ","))
+		if err != nil {
+			return nil, statusError(err)
+		}
+	}
+
+	if request.Cursor != "" && request.Offset > 0 {
+		return nil, statusError(invalidField("cursor", "cursor can't be combined with offset"))
+	}
+
+	resp, err := s.s.FetchSongs(ctx, request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	result := &musicv1.FetchSongsResponse{
+		Songs:      make([]*musicv1.Song, 0, len(resp.Songs)),
+		TotalCount: int64(resp.TotalCount),
+		NextCursor: resp.NextCursor,
+	}
+	for _, song := range resp.Songs {
+		result.Songs = append(result.Songs, songToProto(song))
+	}
+
+	return result, nil
+}
+
+// FetchVerses streams verses page by page until limit or end of song
+func (s *Server) FetchVerses(req *musicv1.FetchVersesRequest, stream grpc.ServerStreamingServer[musicv1.FetchVersesResponse]) error {
+	if req.GetSongId() < 1 {
+		return statusError(invalidField("song_id", "wrong format song_id"))
+	}
+	if req.GetLimit() < 0 {
+		return statusError(invalidField("limit", "limit must not be negative"))
+	}
+	if req.GetOffset() < 0 {
+		return statusError(invalidField("offset", "offset must not be negative"))
+	}
+
+	remaining := int(req.GetLimit())
+	offset := int(req.GetOffset())
+	for {
+		pageSize := versesPageSize
+		if req.GetLimit() > 0 && remaining < pageSize {
+			pageSize = remaining
+		}
+
+		resp, err := s.s.FetchVerses(stream.Context(), service.FetchVersesRequest{
+			SongID: int(req.GetSongId()),
+			Limit:  pageSize,
+			Offset: offset,
+		})
+		if err != nil {
+			return statusError(err)
+		}
+
+		for _, verse := range resp.Verses {
+			if err := stream.Send(&musicv1.FetchVersesResponse{Verse: verseToProto(verse)}); err != nil {
+				return err
+			}
+		}
+
+		offset += len(resp.Verses)
+		remaining -= len(resp.Verses)
+		if len(resp.Verses) < pageSize || (req.GetLimit() > 0 && remaining == 0) {
+			return nil
+		}
+	}
+}
+
+func (s *Server) NewSong(ctx context.Context, req *musicv1.NewSongRequest) (*musicv1.NewSongResponse, error) {
+	if req.GetGroupName() == "" {
+		return nil, statusError(invalidField("group_name", "group_name is required"))
+	}
+	if req.GetSongName() == "" {
+		return nil, statusError(invalidField("song_name", "song_name is required"))
+	}
+
+	song, err := s.s.NewSong(ctx, service.NewSongRequest{
+		GroupName: req.GetGroupName(),
+		SongName:  req.GetSongName(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &musicv1.NewSongResponse{Song: songToProto(*song)}, nil
+}
+
+func (s *Server) UpdateSong(ctx context.Context, req *musicv1.UpdateSongRequest) (*musicv1.UpdateSongResponse, error) {
+	if req.GetSongId() < 1 {
+		return nil, statusError(invalidField("song_id", "wrong format song_id"))
+	}
+	if req.GroupName == nil && req.SongName == nil && req.ReleaseDate == nil && req.Link == nil {
+		return nil, statusError(invalidField("body", "no fields to change"))
+	}
+
+	request := service.UpdateSongRequest{
+		SongID:    int(req.GetSongId()),
+		GroupName: req.GroupName,
+		SongName:  req.SongName,
+		Link:      req.Link,
+		Version:   service.OptionalInt(req.Version),
+	}
+
+	if req.ReleaseDate != nil {
+		releaseDate, err := dateparse.ParseISO(req.GetReleaseDate())
+		if err != nil {
+			return nil, statusError(invalidField("release_date", service.ISODateMessage))
+		}
+		request.ReleaseDate = &releaseDate
+	}
+
+	resp, err := s.s.UpdateSong(ctx, request)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &musicv1.UpdateSongResponse{Version: int32(resp.Version)}, nil
+}
+
+func (s *Server) UpdateVerse(ctx context.Context, req *musicv1.UpdateVerseRequest) (*musicv1.UpdateVerseResponse, error) {
+	if req.GetSongId() < 1 {
+		return nil, statusError(invalidField("song_id", "wrong format song_id"))
+	}
+	if req.GetVerseNumber() < 1 {
+		return nil, statusError(invalidField("verse_number", "verse_number must be a positive integer"))
+	}
+	if req.GetVerseText() == "" {
+		return nil, statusError(invalidField("verse_text", "verse_text is required"))
+	}
+
+	resp, err := s.s.UpdateVerse(ctx, service.UpdateVerseRequest{
+		SongID:      int(req.GetSongId()),
+		VerseNumber: int(req.GetVerseNumber()),
+		VerseText:   req.GetVerseText(),
+		Version:     service.OptionalInt(req.Version),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return &musicv1.UpdateVerseResponse{
+		Version: int32(resp.Version),
+		Verse:   verseToProto(resp.Verse),
+	}, nil
+}
+
+func (s *Server) DeleteSong(ctx context.Context, req *musicv1.DeleteSongRequest) (*musicv1.DeleteSongResponse, error) {
+	if req.GetSongId() < 1 {
+		return nil, statusError(invalidField("song_id", "wrong format song_id"))
+	}
+
+	if _, err := s.s.DeleteSong(ctx, service.DeleteSongRequest{SongID: int(req.GetSongId())}); err != nil {
+		return nil, statusError(err)
+	}
+
+	return &musicv1.DeleteSongResponse{}, nil
+}
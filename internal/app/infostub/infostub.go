@@ -0,0 +1,134 @@
+package infostub
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time" //nolint:gci
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger" //nolint:gci
+	"github.com/gin-gonic/gin"
+)
+
+// Fixture is one song served by stub, fixture file contains object or array of objects
+type Fixture struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// SongDetail is response schema from tz/api_tz_serv.yaml
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+type Config struct {
+	FixturesDir string
+	Latency     time.Duration
+	Jitter      time.Duration
+	ErrorRate   float64
+}
+
+type Stub struct {
+	songs map[string]SongDetail
+	cfg   Config
+	log   *logger.Logger
+}
+
+func New(cfg Config, log *logger.Logger) (*Stub, error) {
+	songs, err := loadFixtures(cfg.FixturesDir)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("infostub.New | fixtures loaded", "dir", cfg.FixturesDir, "songs", len(songs))
+	return &Stub{songs: songs, cfg: cfg, log: log}, nil
+}
+
+func fixtureKey(group, song string) string {
+	return strings.ToLower(strings.TrimSpace(group)) + "\x00" + strings.ToLower(strings.TrimSpace(song))
+}
+
+func loadFixtures(dir string) (map[string]SongDetail, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	songs := make(map[string]SongDetail)
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("read fixture %s: %w", file, err)
+		}
+
+		var fixtures []Fixture
+		if err := json.Unmarshal(data, &fixtures); err != nil {
+			var fixture Fixture
+			if err := json.Unmarshal(data, &fixture); err != nil {
+				return nil, fmt.Errorf("parse fixture %s: %w", file, err)
+			}
+			fixtures = []Fixture{fixture}
+		}
+
+		for _, f := range fixtures {
+			if f.Group == "" || f.Song == "" {
+				return nil, fmt.Errorf("fixture %s: group and song are required", file)
+			}
+			songs[fixtureKey(f.Group, f.Song)] = SongDetail{
+				ReleaseDate: f.ReleaseDate,
+				Text:        f.Text,
+				Link:        f.Link,
+			}
+		}
+	}
+	return songs, nil
+}
+
+// Router returns gin engine serving GET /info
+func (s *Stub) Router() *gin.Engine {
+	r := gin.Default()
+	r.GET("/info", s.InfoHandler)
+	return r
+}
+
+func (s *Stub) InfoHandler(c *gin.Context) {
+	s.delay()
+
+	if s.cfg.ErrorRate > 0 && rand.Float64() < s.cfg.ErrorRate { //nolint:gosec
+		s.log.Warn("infostub.InfoHandler | injected error")
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	group := c.Query("group")
+	song := c.Query("song")
+	if group == "" || song == "" {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	detail, ok := s.songs[fixtureKey(group, song)]
+	if !ok {
+		c.Status(http.StatusNotFound)
+		return
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+func (s *Stub) delay() {
+	wait := s.cfg.Latency
+	if s.cfg.Jitter > 0 {
+		wait += time.Duration(rand.Int63n(int64(s.cfg.Jitter))) //nolint:gosec
+	}
+	if wait > 0 {
+		time.Sleep(wait)
+	}
+}
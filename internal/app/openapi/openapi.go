@@ -0,0 +1,303 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/docs"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/gin-gonic/gin"
+)
+
+// maxResponseBody limits copy of response kept for validation, bigger responses are not validated
+const maxResponseBody = 1 << 20
+
+// Load converts swagger 2 document generated by swag to OpenAPI 3,
+// paths are served under swagger basePath
+func Load() (*openapi3.T, error) {
+	var doc2 openapi2.T
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc2); err != nil {
+		return nil, fmt.Errorf("parse swagger document: %w", err)
+	}
+
+	doc, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return nil, fmt.Errorf("convert swagger document: %w", err)
+	}
+
+	//router matches request path with server url, host of request is not checked
+	doc.Servers = openapi3.Servers{{URL: docs.SwaggerInfo.BasePath}}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("validate openapi document: %w", err)
+	}
+	return doc, nil
+}
+
+type Options struct {
+	// StrictQuery lists path prefixes where undocumented query params are rejected,
+	// on other paths they are only logged so existing clients are not broken
+	StrictQuery []string
+	// Responses enables validation of json responses, it is meant for debug mode
+	Responses bool
+	// OnResponseError is called when response does not match spec,
+	// by default violation is logged
+	OnResponseError func(c *gin.Context, err error)
+}
+
+// Validator checks requests, and optionally responses, of documented operations.
+// Requests to paths missing in spec are passed as is
+type Validator struct {
+	router  routers.Router
+	options Options
+	log     *logger.Logger
+}
+
+func New(doc *openapi3.T, options Options, log *logger.Logger) (*Validator, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("init openapi router: %w", err)
+	}
+
+	v := &Validator{router: router, options: options, log: log}
+	if v.options.OnResponseError == nil {
+		v.options.OnResponseError = v.logResponseError
+	}
+	return v, nil
+}
+
+// Middleware rejects requests violating spec with validation problem
+func (v *Validator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route, pathParams, err := v.router.FindRoute(c.Request)
+		if err != nil {
+			//not documented path or method, it is answered by router
+			c.Next()
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				//only json bodies are checked, streamed imports are not read in advance
+				ExcludeRequestBody:  !isJSON(c.GetHeader("Content-Type")),
+				MultiError:          true,
+				SkipSettingDefaults: true,
+				AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+
+		var fields []problem.FieldError
+		if unknown := unknownQuery(c.Request.URL.Query(), route.Operation.Parameters); len(unknown) > 0 {
+			if v.strictQuery(c.Request.URL.Path) {
+				fields = append(fields, unknown...)
+			} else {
+				v.logUnknownQuery(c, unknown)
+			}
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			fields = append(fields, requestFields(err)...)
+		}
+		if len(fields) > 0 {
+			writeProblem(c, problem.Validation(fields...))
+			return
+		}
+
+		if !v.options.Responses {
+			c.Next()
+			return
+		}
+
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		if recorder.truncated || !isJSON(recorder.Header().Get("Content-Type")) {
+			return
+		}
+
+		//problem documents are described as json in spec
+		header := recorder.Header().Clone()
+		header.Set("Content-Type", "application/json")
+
+		err = openapi3filter.ValidateResponse(c.Request.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 recorder.Status(),
+			Header:                 header,
+			Body:                   io.NopCloser(bytes.NewReader(recorder.body.Bytes())),
+			Options: &openapi3filter.Options{
+				IncludeResponseStatus: true,
+				MultiError:            true,
+			},
+		})
+		if err != nil {
+			v.options.OnResponseError(c, err)
+		}
+	}
+}
+
+func (v *Validator) logResponseError(c *gin.Context, err error) {
+	v.log.Error("openapi | response does not match spec",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"error", err.Error())
+}
+
+func (v *Validator) strictQuery(path string) bool {
+	for _, prefix := range v.options.StrictQuery {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *Validator) logUnknownQuery(c *gin.Context, fields []problem.FieldError) {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Field)
+	}
+	v.log.Warn("openapi | unknown query parameters",
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"params", strings.Join(names, ","))
+}
+
+// unknownQuery reports query params missing in operation parameters
+func unknownQuery(query url.Values, params openapi3.Parameters) []problem.FieldError {
+	known := make(map[string]bool, len(params))
+	for _, param := range params {
+		if param.Value != nil && param.Value.In == openapi3.ParameterInQuery {
+			known[param.Value.Name] = true
+		}
+	}
+
+	var fields []problem.FieldError
+	for name := range query {
+		if !known[name] {
+			fields = append(fields, problem.FieldError{Field: name, Message: "unknown query parameter"})
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	return fields
+}
+
+// requestFields converts validation errors of openapi3filter to field details
+func requestFields(err error) []problem.FieldError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var fields []problem.FieldError
+		for _, item := range multi {
+			fields = append(fields, requestFields(item)...)
+		}
+		return fields
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		//with MultiError body schema errors are not wrapped to RequestError
+		var schemaErr *openapi3.SchemaError
+		if errors.As(err, &schemaErr) {
+			return []problem.FieldError{schemaField("body", false, schemaErr)}
+		}
+		return []problem.FieldError{{Field: "request", Message: err.Error()}}
+	}
+
+	field := "body"
+	if reqErr.Parameter != nil {
+		field = reqErr.Parameter.Name
+	}
+
+	if reqErr.Err != nil {
+		var nested openapi3.MultiError
+		if errors.As(reqErr.Err, &nested) {
+			var fields []problem.FieldError
+			for _, item := range nested {
+				fields = append(fields, schemaField(field, reqErr.Parameter != nil, item))
+			}
+			return fields
+		}
+		return []problem.FieldError{schemaField(field, reqErr.Parameter != nil, reqErr.Err)}
+	}
+	return []problem.FieldError{{Field: field, Message: reqErr.Reason}}
+}
+
+// schemaField names body field by json pointer of schema error
+func schemaField(field string, isParam bool, err error) problem.FieldError {
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return problem.FieldError{Field: field, Message: err.Error()}
+	}
+
+	if pointer := schemaErr.JSONPointer(); !isParam && len(pointer) > 0 {
+		field = strings.Join(pointer, ".")
+	}
+	return problem.FieldError{Field: field, Message: schemaErr.Reason}
+}
+
+func writeProblem(c *gin.Context, err error) {
+	//request is rejected before handler only by validation
+	p := problem.From(err, http.StatusBadRequest, c.Request.URL.Path)
+	c.Header("Content-Type", problem.ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// responseRecorder passes response to client and keeps its copy,
+// so streamed responses are still flushed as written
+type responseRecorder struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.keep(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.keep([]byte(s))
+	return r.ResponseWriter.WriteString(s)
+}
+
+func (r *responseRecorder) keep(data []byte) {
+	if r.truncated {
+		return
+	}
+	if r.body.Len()+len(data) > maxResponseBody {
+		r.truncated = true
+		r.body.Reset()
+		return
+	}
+	r.body.Write(data)
+}
+
+var _ http.ResponseWriter = (*responseRecorder)(nil)
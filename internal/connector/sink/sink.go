@@ -0,0 +1,141 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+)
+
+const (
+	HeaderEventID   = "X-Event-ID"
+	HeaderEventType = "X-Event-Type"
+
+	//response body is read only to reuse connection and to report error
+	maxResponseBody = 1024
+)
+
+// Message is published change event, ID is the same for every
+// publish of the event and can be used to drop duplicates
+type Message struct {
+	ID     int64
+	Type   string
+	SongID int
+	Body   []byte
+}
+
+// Sink receives change events from outbox relay. Events are published at least once,
+// so sink can get the same message again after failure of any sink
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, msg Message) error
+}
+
+// Log writes events to application log
+type Log struct {
+	log *logger.Logger
+}
+
+func NewLog(log *logger.Logger) *Log {
+	return &Log{log: log}
+}
+
+func (s *Log) Name() string {
+	return "log"
+}
+
+func (s *Log) Publish(ctx context.Context, msg Message) error {
+	s.log.Info("sink.Log | event", "id", msg.ID, "type", msg.Type, "song_id", msg.SongID, "body", string(msg.Body))
+	return nil
+}
+
+// File appends events to file as ndjson lines
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFile(path string) (*File, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open sink file: %w", err)
+	}
+	return &File{file: file}, nil
+}
+
+func (s *File) Name() string {
+	return "file"
+}
+
+func (s *File) Publish(ctx context.Context, msg Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := make([]byte, 0, len(msg.Body)+1)
+	line = append(append(line, msg.Body...), '\n')
+	if _, err := s.file.Write(line); err != nil {
+		return err
+	}
+	//event is published only when it is on disk
+	return s.file.Sync()
+}
+
+func (s *File) Close() error {
+	return s.file.Close()
+}
+
+// StatusError is returned when receiver answers with non 2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sink receiver answered %d: %s", e.StatusCode, e.Body)
+}
+
+// HTTP posts events as json to url
+type HTTP struct {
+	url    string
+	client *http.Client
+}
+
+func NewHTTP(url string, timeout time.Duration) *HTTP {
+	return &HTTP{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *HTTP) Name() string {
+	return "http"
+}
+
+func (s *HTTP) Publish(ctx context.Context, msg Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(msg.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEventID, strconv.FormatInt(msg.ID, 10))
+	req.Header.Set(HeaderEventType, msg.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
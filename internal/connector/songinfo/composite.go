@@ -0,0 +1,93 @@
+package songinfo
+
+import (
+	"context"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger" //nolint:gci
+)
+
+// Provider is an InfoSerice with a name, used for logging in Composite
+type Provider interface {
+	InfoSerice
+	Name() string
+}
+
+// Composite queries providers in order, falls back on errors
+// and merges partial results field by field
+type Composite struct {
+	providers []Provider
+	log       *logger.Logger
+}
+
+func NewComposite(log *logger.Logger, providers ...Provider) *Composite {
+	return &Composite{providers: providers, log: log}
+}
+
+func (c *Composite) FetchSongInfo(ctx context.Context, params FetchSongInfoParam) (*SongInfo, error) {
+	if params.GroupName == "" {
+		return nil, ErrGroupNameRequired
+	}
+
+	if params.SongName == "" {
+		return nil, ErrSongNameRequired
+	}
+
+	if len(c.providers) == 0 {
+		return nil, ErrNoProviders
+	}
+
+	var merged *SongInfo
+	var lastErr error
+
+	for _, p := range c.providers {
+		info, err := p.FetchSongInfo(ctx, params)
+		if err != nil {
+			c.log.Warn("songinfo.Composite | provider failed, fallback to next",
+				"provider", p.Name(),
+				"error", err.Error(),
+			)
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if merged == nil {
+			merged = &SongInfo{}
+		}
+		mergeSongInfo(merged, info)
+
+		if isComplete(merged) {
+			return merged, nil
+		}
+	}
+
+	if merged == nil {
+		return nil, lastErr
+	}
+
+	c.log.Warn("songinfo.Composite | partial song info",
+		"group", params.GroupName,
+		"song", params.SongName,
+	)
+
+	return merged, nil
+}
+
+// mergeSongInfo fills empty fields of dst from src
+func mergeSongInfo(dst, src *SongInfo) {
+	if dst.ReleaseDate == "" {
+		dst.ReleaseDate = src.ReleaseDate
+	}
+	if dst.Text == "" {
+		dst.Text = src.Text
+	}
+	if dst.Link == "" {
+		dst.Link = src.Link
+	}
+}
+
+func isComplete(info *SongInfo) bool {
+	return info.ReleaseDate != "" && info.Text != "" && info.Link != ""
+}
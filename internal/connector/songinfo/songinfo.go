@@ -1,9 +1,14 @@
 package songinfo
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"      //nolint:gci
+	"errors"
+	"fmt" //nolint:gci
+	"net"
 	"net/http" //nolint:gci
+	"net/url"
+	"time"
 
 	"github.com/Vic07Region/musicLibrary/internal/lib/logger" //nolint:gci
 )
@@ -15,19 +20,62 @@ var (
 	ErrServiceBadRequest = fmt.Errorf("song Storage bad request")
 	ErrSerialize         = fmt.Errorf("song Storage bad request")
 	ErrServiceUnknow     = fmt.Errorf("song Storage unknow error")
+	ErrNoProviders       = fmt.Errorf("no song info providers configured")
+	ErrTimeOut           = fmt.Errorf("song info service timeout exceeded")
 )
 
+// DefaultTimeout limits request to provider without configured timeout
+const DefaultTimeout = 10 * time.Second
+
 type InfoSerice interface {
-	FetchSongInfo(params FetchSongInfoParam) (*SongInfo, error)
+	FetchSongInfo(ctx context.Context, params FetchSongInfoParam) (*SongInfo, error)
+}
+
+// ProviderConfig describes one external song info api
+type ProviderConfig struct {
+	Name     string
+	BaseURL  string
+	Token    string
+	User     string
+	Password string
+	Timeout  time.Duration
 }
 
 type SongStorage struct {
-	baseURL string
-	log     *logger.Logger
+	name     string
+	baseURL  string
+	token    string
+	user     string
+	password string
+	client   *http.Client
+	log      *logger.Logger
 }
 
 func New(baseURL string, log *logger.Logger) InfoSerice {
-	return &SongStorage{baseURL: baseURL, log: log}
+	return NewProvider(ProviderConfig{BaseURL: baseURL}, log)
+}
+
+func NewProvider(cfg ProviderConfig, log *logger.Logger) *SongStorage {
+	if cfg.Name == "" {
+		cfg.Name = "default"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	return &SongStorage{
+		name:     cfg.Name,
+		baseURL:  cfg.BaseURL,
+		token:    cfg.Token,
+		user:     cfg.User,
+		password: cfg.Password,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		log:      log,
+	}
+}
+
+// Name returns provider name from config
+func (s *SongStorage) Name() string {
+	return s.name
 }
 
 type SongInfo struct {
@@ -41,7 +89,7 @@ type FetchSongInfoParam struct {
 	SongName  string `json:"song_name"`
 }
 
-func (s *SongStorage) FetchSongInfo(params FetchSongInfoParam) (*SongInfo, error) {
+func (s *SongStorage) FetchSongInfo(ctx context.Context, params FetchSongInfoParam) (*SongInfo, error) {
 	if params.GroupName == "" {
 		s.log.Error("songinfo.FetchSongInfo | empty GroupName")
 		return nil, ErrGroupNameRequired
@@ -51,12 +99,37 @@ func (s *SongStorage) FetchSongInfo(params FetchSongInfoParam) (*SongInfo, error
 		s.log.Error("songinfo.FetchSongInfo | empty SongName")
 		return nil, ErrSongNameRequired
 	}
-	url := s.baseURL
-	resp, err := http.Get(url)
+
+	query := url.Values{}
+	query.Set("group", params.GroupName)
+	query.Set("song", params.SongName)
+	reqURL := s.baseURL + "?" + query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		s.log.Error("songinfo.FetchSongInfo | NewRequest",
+			"provider", s.name,
+			"error", err.Error(),
+		)
+		return nil, ErrServiceInternal
+	}
+
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	} else if s.user != "" {
+		req.SetBasicAuth(s.user, s.password)
+	}
+
+	resp, err := s.client.Do(req)
 	if err != nil {
-		s.log.Error(fmt.Sprintf("songinfo.FetchSongInfo http.Get(%s)", url),
+		s.log.Error(fmt.Sprintf("songinfo.FetchSongInfo http.Get(%s)", reqURL),
+			"provider", s.name,
 			"error", err.Error(),
 		)
+		var netErr net.Error
+		if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil, ErrTimeOut
+		}
 		return nil, ErrServiceInternal
 	}
 
@@ -69,6 +142,7 @@ func (s *SongStorage) FetchSongInfo(params FetchSongInfoParam) (*SongInfo, error
 		if err := json.NewDecoder(resp.Body).Decode(&song); err != nil {
 			s.log.Error(
 				"songinfo.FetchSongInfo | Song info service Decode body",
+				"provider", s.name,
 				"error", err.Error(),
 			)
 			return nil, ErrSerialize
@@ -79,7 +153,9 @@ func (s *SongStorage) FetchSongInfo(params FetchSongInfoParam) (*SongInfo, error
 		return nil, ErrServiceInternal
 	default:
 		s.log.Error(
-			"songinfo.FetchSongInfo response", "error", resp.Status)
+			"songinfo.FetchSongInfo response",
+			"provider", s.name,
+			"error", resp.Status)
 		return nil, ErrServiceUnknow
 	}
 
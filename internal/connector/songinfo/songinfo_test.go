@@ -0,0 +1,60 @@
+package songinfo_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/connector/songinfo"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+)
+
+func TestFetchSongInfoTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	params := songinfo.FetchSongInfoParam{GroupName: "Muse", SongName: "Uprising"}
+
+	t.Run("provider timeout", func(t *testing.T) {
+		provider := songinfo.NewProvider(songinfo.ProviderConfig{BaseURL: srv.URL, Timeout: 50 * time.Millisecond}, logger.New())
+		if _, err := provider.FetchSongInfo(context.Background(), params); !errors.Is(err, songinfo.ErrTimeOut) {
+			t.Errorf("error %v, want %v", err, songinfo.ErrTimeOut)
+		}
+	})
+
+	t.Run("request deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		provider := songinfo.NewProvider(songinfo.ProviderConfig{BaseURL: srv.URL}, logger.New())
+		if _, err := provider.FetchSongInfo(ctx, params); !errors.Is(err, songinfo.ErrTimeOut) {
+			t.Errorf("error %v, want %v", err, songinfo.ErrTimeOut)
+		}
+	})
+
+	t.Run("composite falls back after timeout", func(t *testing.T) {
+		ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"releaseDate": "2009", "text": "Paranoia is in bloom", "link": "https://example.com"}`))
+		}))
+		defer ok.Close()
+
+		composite := songinfo.NewComposite(logger.New(),
+			songinfo.NewProvider(songinfo.ProviderConfig{Name: "slow", BaseURL: srv.URL, Timeout: 50 * time.Millisecond}, logger.New()),
+			songinfo.NewProvider(songinfo.ProviderConfig{Name: "backup", BaseURL: ok.URL}, logger.New()),
+		)
+		info, err := composite.FetchSongInfo(context.Background(), params)
+		if err != nil || info.ReleaseDate != "2009" {
+			t.Errorf("info %+v, error %v, want backup provider answer", info, err)
+		}
+	})
+}
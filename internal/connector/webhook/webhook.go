@@ -0,0 +1,176 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	signaturePrefix = "sha256="
+	//response body is read only to reuse connection and to report error
+	maxResponseBody = 1024
+)
+
+// ErrPrivateAddress is returned for webhook urls pointing to private, loopback or link-local address
+var ErrPrivateAddress = errors.New("webhook url points to private, loopback or link-local address")
+
+type Sender interface {
+	Send(ctx context.Context, request SendRequest) (*SendResult, error)
+	CheckURL(rawURL string) error
+}
+
+type SendRequest struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID int64
+	Body       []byte
+}
+
+type SendResult struct {
+	StatusCode int
+}
+
+// StatusError is returned when receiver answers with non 2xx status
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook receiver answered %d: %s", e.StatusCode, e.Body)
+}
+
+type Client struct {
+	client  *http.Client
+	allowed map[string]bool
+	log     *logger.Logger
+}
+
+// New returns client refusing to connect to private, loopback and link-local addresses,
+// allowedHosts (host names or ips) are trusted receivers sent to without the check
+func New(timeout time.Duration, allowedHosts []string, log *logger.Logger) *Client {
+	c := &Client{
+		allowed: make(map[string]bool, len(allowedHosts)),
+		log:     log,
+	}
+	for _, host := range allowedHosts {
+		c.allowed[strings.ToLower(host)] = true
+	}
+
+	//address is checked after name resolution, so a public name resolving to internal address is refused too
+	dialer := &net.Dialer{Timeout: timeout}
+	public := &net.Dialer{Timeout: timeout, Control: func(network, address string, _ syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+			return ErrPrivateAddress
+		}
+		return nil
+	}}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	//proxy would connect to receiver on its own and skip the address check
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		if c.allowed[strings.ToLower(host)] {
+			return dialer.DialContext(ctx, network, address)
+		}
+		return public.DialContext(ctx, network, address)
+	}
+
+	c.client = &http.Client{Timeout: timeout, Transport: transport}
+	return c
+}
+
+// CheckURL rejects webhook url with private, loopback or link-local ip or localhost host
+// unless the host is allowed. Names are resolved only when delivery is sent
+func (c *Client) CheckURL(rawURL string) error {
+	target, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := strings.ToLower(target.Hostname())
+	if c.allowed[host] {
+		return nil
+	}
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !publicIP(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+func publicIP(ip net.IP) bool {
+	return !ip.IsPrivate() && !ip.IsLoopback() && !ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() && !ip.IsUnspecified()
+}
+
+// Sign returns signature of body sent at timestamp:
+// hex HMAC-SHA256 of "<timestamp>.<body>" with webhook secret
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Send posts signed json body to webhook url, result is returned
+// whenever receiver answered, even with error status
+func (c *Client) Send(ctx context.Context, request SendRequest) (*SendResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, request.URL, bytes.NewReader(request.Body))
+	if err != nil {
+		c.log.Error("webhook.Send | NewRequest", "url", request.URL, "error", err.Error())
+		return nil, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, request.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(request.DeliveryID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(request.Secret, timestamp, request.Body))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	result := &SendResult{StatusCode: resp.StatusCode}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return result, &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	return result, nil
+}
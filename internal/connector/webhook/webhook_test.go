@@ -0,0 +1,70 @@
+package webhook_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/connector/webhook"
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+)
+
+func TestCheckURL(t *testing.T) {
+	client := webhook.New(time.Second, []string{"hooks.local", "10.0.0.5"}, logger.New())
+
+	cases := []struct {
+		url     string
+		private bool
+	}{
+		{url: "https://example.com/hooks", private: false},
+		{url: "http://localhost:8080/hooks", private: true},
+		{url: "http://127.0.0.1/hooks", private: true},
+		{url: "http://10.1.2.3/hooks", private: true},
+		{url: "http://192.168.0.1/hooks", private: true},
+		{url: "http://169.254.169.254/latest/meta-data", private: true},
+		{url: "http://[::1]/hooks", private: true},
+		{url: "http://[fe80::1]/hooks", private: true},
+		{url: "http://0.0.0.0/hooks", private: true},
+		{url: "http://10.0.0.5/hooks", private: false},
+		{url: "http://hooks.local/hooks", private: false},
+	}
+
+	for _, tc := range cases {
+		err := client.CheckURL(tc.url)
+		if got := errors.Is(err, webhook.ErrPrivateAddress); got != tc.private {
+			t.Errorf("CheckURL(%q) = %v, want private %v", tc.url, err, tc.private)
+		}
+	}
+}
+
+// TestSendRefusesPrivateAddress checks that address is checked on connect,
+// names resolving to loopback are refused as well as literal ips
+func TestSendRefusesPrivateAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatalf("parse server url: %v", err)
+	}
+	named := "http://localhost:" + target.Port()
+	request := webhook.SendRequest{Secret: "secret", EventType: "song.created", DeliveryID: 1, Body: []byte(`{}`)}
+
+	client := webhook.New(time.Second, nil, logger.New())
+	for _, rawURL := range []string{srv.URL, named} {
+		request.URL = rawURL
+		if _, err := client.Send(context.Background(), request); !errors.Is(err, webhook.ErrPrivateAddress) {
+			t.Errorf("Send to %s: error %v, want %v", rawURL, err, webhook.ErrPrivateAddress)
+		}
+	}
+
+	allowed := webhook.New(time.Second, []string{target.Hostname()}, logger.New())
+	request.URL = srv.URL
+	if _, err := allowed.Send(context.Background(), request); err != nil {
+		t.Errorf("Send to allowed host: %v", err)
+	}
+}
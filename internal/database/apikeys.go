@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+var apiKeyColumns = []string{"key_id", "name", "key_hash", "scopes", "created_at", "last_used_at", "revoked_at"}
+
+type CreateAPIKeyRequest struct {
+	KeyID   string   `json:"key_id"`
+	Name    string   `json:"name"`
+	KeyHash string   `json:"key_hash"`
+	Scopes  []string `json:"scopes"`
+}
+
+func (q *Queries) CreateAPIKey(ctx context.Context, request CreateAPIKeyRequest) (*APIKey, error) {
+	sqlQuery := sq.Insert("api_keys").Columns("key_id", "name", "key_hash", "scopes").
+		Values(request.KeyID, request.Name, request.KeyHash, pq.Array(request.Scopes)).
+		Suffix("RETURNING " + strings.Join(apiKeyColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	key, err := scanAPIKey(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.CreateAPIKey | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetAPIKey returns key by id, revoked keys are returned too
+func (q *Queries) GetAPIKey(ctx context.Context, keyID string) (*APIKey, error) {
+	sqlQuery := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		Where(sq.Eq{"key_id": keyID}).
+		PlaceholderFormat(sq.Dollar)
+
+	key, err := scanAPIKey(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetAPIKey | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func (q *Queries) GetAPIKeys(ctx context.Context) ([]APIKey, error) {
+	sqlQuery := sq.Select(apiKeyColumns...).
+		From("api_keys").
+		OrderBy("created_at", "key_id").
+		PlaceholderFormat(sq.Dollar)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetAPIKeys | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.GetAPIKeys | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetAPIKeys | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey marks key as revoked, returns sql.ErrNoRows for unknown or already revoked key
+func (q *Queries) RevokeAPIKey(ctx context.Context, keyID string) error {
+	result, err := sq.Update("api_keys").
+		Set("revoked_at", sq.Expr("now()")).
+		Where(sq.Eq{"key_id": keyID, "revoked_at": nil}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.RevokeAPIKey | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.RevokeAPIKey | RowsAffected", "error", err.Error())
+		}
+		return err
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TouchAPIKey records key usage, last_used_at is updated at most once a minute
+func (q *Queries) TouchAPIKey(ctx context.Context, keyID string) error {
+	_, err := sq.Update("api_keys").
+		Set("last_used_at", sq.Expr("now()")).
+		Where(sq.Eq{"key_id": keyID}).
+		Where("(last_used_at IS NULL OR last_used_at < now() - interval '1 minute')").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.TouchAPIKey | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func scanAPIKey(row sq.RowScanner) (*APIKey, error) {
+	var i APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+	if err := row.Scan(
+		&i.KeyID,
+		&i.Name,
+		&i.KeyHash,
+		pq.Array(&i.Scopes),
+		&i.CreatedAt,
+		&lastUsedAt,
+		&revokedAt,
+	); err != nil {
+		return nil, err
+	}
+	if lastUsedAt.Valid {
+		i.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		i.RevokedAt = &revokedAt.Time
+	}
+	return &i, nil
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time" //nolint:gci
@@ -35,12 +36,87 @@ func NewConnection(params ConnectionParams) (*sql.DB, error) {
 	return dbo, nil
 }
 
+// DBTX is implemented by *sql.DB and *sql.Tx, queries of storage run on it
+type DBTX interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
 type Queries struct {
-	db    *sql.DB
+	db    DBTX
+	conn  *sql.DB
+	tx    *sql.Tx
 	log   *logger.Logger
 	debug bool
 }
 
 func NewStorage(db *sql.DB, log *logger.Logger, debug bool) *Queries {
-	return &Queries{db: db, log: log, debug: debug}
+	return &Queries{db: db, conn: db, log: log, debug: debug}
+}
+
+// InTx runs fn with storage bound to one transaction, it is committed when fn returns nil
+// and rolled back otherwise. Write methods of bound storage join the transaction
+// instead of opening their own, nested InTx joins it too
+func (q *Queries) InTx(ctx context.Context, fn func(s Storage) error) error {
+	if q.tx != nil {
+		return fn(q)
+	}
+
+	tx, err := q.conn.BeginTx(ctx, nil)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.InTx | BeginTx", "error", err.Error())
+		}
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if err := fn(&Queries{db: tx, conn: q.conn, tx: tx, log: q.log, debug: q.debug}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		if q.debug {
+			q.log.Error("database.InTx | Commit", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// localTx is transaction of one write method. When storage is bound by InTx
+// it wraps bound transaction and leaves commit and rollback to InTx
+type localTx struct {
+	*sql.Tx
+	own bool
+}
+
+func (t *localTx) Commit() error {
+	if !t.own {
+		return nil
+	}
+	return t.Tx.Commit()
+}
+
+func (t *localTx) Rollback() error {
+	if !t.own {
+		return nil
+	}
+	return t.Tx.Rollback()
+}
+
+// begin opens transaction of write method or joins transaction of InTx
+func (q *Queries) begin(ctx context.Context) (*localTx, error) {
+	if q.tx != nil {
+		return &localTx{Tx: q.tx}, nil
+	}
+	tx, err := q.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &localTx{Tx: tx, own: true}, nil
 }
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	defaultEventsLimit = 100
+	//eventsLockKey is transaction advisory lock taken by every events log insert
+	eventsLockKey = 8008
+)
+
+var eventColumns = []string{"event_id", "event_type", "song_id", "group_name", "payload", "created_at"}
+
+type AddEventRequest struct {
+	OutboxID  int64   `json:"outbox_id"`
+	EventType string  `json:"event_type"`
+	SongID    int     `json:"song_id"`
+	GroupName *string `json:"group_name,omitempty"`
+	Payload   []byte  `json:"payload"`
+}
+
+// AddEvent appends change event to events log. Event of outbox is added once,
+// for already added one sql.ErrNoRows is returned.
+// Inserts are serialized by advisory lock held until commit, so event ids become visible
+// in increasing order and reader continuing after event_id never skips a later committed smaller id
+func (q *Queries) AddEvent(ctx context.Context, request AddEventRequest) (*Event, error) {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.AddEvent | BeginTx", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", eventsLockKey); err != nil {
+		if q.debug {
+			q.log.Error("database.AddEvent | pg_advisory_xact_lock", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	sqlQuery := sq.Insert("events").Columns("outbox_id", "event_type", "song_id", "group_name", "payload").
+		Values(request.OutboxID, request.EventType, request.SongID, request.GroupName, request.Payload).
+		Suffix("ON CONFLICT (outbox_id) DO NOTHING RETURNING " + strings.Join(eventColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	event, err := scanEvent(sqlQuery.RunWith(tx).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug && !errors.Is(err, sql.ErrNoRows) {
+			q.log.Error("database.AddEvent | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		if q.debug {
+			q.log.Error("database.AddEvent | Commit", "error", err.Error())
+		}
+		return nil, err
+	}
+	return event, nil
+}
+
+type GetEventsRequest struct {
+	AfterID   int64   `json:"after_id"`
+	GroupName *string `json:"group_name,omitempty"`
+	SongID    *int    `json:"song_id,omitempty"`
+	Limit     uint64  `json:"limit"`
+}
+
+// GetEvents returns events logged after AfterID in log order,
+// ids are committed in order (see AddEvent), so AfterID is a safe resume position
+func (q *Queries) GetEvents(ctx context.Context, request GetEventsRequest) ([]Event, error) {
+	if request.Limit == 0 {
+		request.Limit = defaultEventsLimit
+	}
+
+	sqlQuery := sq.Select(eventColumns...).
+		From("events").
+		Where(sq.Gt{"event_id": request.AfterID}).
+		OrderBy("event_id").
+		Limit(request.Limit).
+		PlaceholderFormat(sq.Dollar)
+
+	if request.GroupName != nil {
+		sqlQuery = sqlQuery.Where(sq.Eq{"group_name": *request.GroupName})
+	}
+	if request.SongID != nil {
+		sqlQuery = sqlQuery.Where(sq.Eq{"song_id": *request.SongID})
+	}
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetEvents | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.GetEvents | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetEvents | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+	return events, nil
+}
+
+// GetLastEventID returns id of the latest logged event, 0 for empty log
+func (q *Queries) GetLastEventID(ctx context.Context) (int64, error) {
+	sqlQuery := sq.Select("COALESCE(MAX(event_id), 0)").
+		From("events").
+		PlaceholderFormat(sq.Dollar)
+
+	var eventID int64
+	if err := sqlQuery.RunWith(q.db).QueryRowContext(ctx).Scan(&eventID); err != nil {
+		if q.debug {
+			q.log.Error("database.GetLastEventID | QueryRowContext", "error", err.Error())
+		}
+		return 0, err
+	}
+	return eventID, nil
+}
+
+// DeleteEvents removes events logged before given time, returns count of deleted events
+func (q *Queries) DeleteEvents(ctx context.Context, before time.Time) (int64, error) {
+	sqlQuery := sq.Delete("events").
+		Where(sq.Lt{"created_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteEvents | ExecContext", "error", err.Error())
+		}
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteEvents | RowsAffected", "error", err.Error())
+		}
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func scanEvent(row sq.RowScanner) (*Event, error) {
+	var i Event
+	var groupName sql.NullString
+	if err := row.Scan(
+		&i.EventID,
+		&i.EventType,
+		&i.SongID,
+		&groupName,
+		&i.Payload,
+		&i.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if groupName.Valid {
+		i.GroupName = &groupName.String
+	}
+	return &i, nil
+}
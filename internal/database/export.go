@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+// ExportSongs streams songs matching filter with all verses ordered by song id.
+// fn is called once per song, verses slice is reused and must not be kept
+func (q *Queries) ExportSongs(ctx context.Context, filter SongFilter, fn func(song Song, verses []VerseSmall) error) error {
+	rows, err := exportSongsQuery(filter).RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.ExportSongs | QueryContext", "error", err.Error())
+		}
+		return err
+	}
+	defer rows.Close()
+
+	var current Song
+	var verses []VerseSmall
+	started := false
+
+	for rows.Next() {
+		var i Song
+		var verseNumber sql.NullInt64
+		var verseText sql.NullString
+		if err := rows.Scan(append(songDest(&i), &verseNumber, &verseText)...); err != nil {
+			if q.debug {
+				q.log.Error("database.ExportSongs | row.Scan", "error", err.Error())
+			}
+			return err
+		}
+
+		if started && i.SongID != current.SongID {
+			if err := fn(current, verses); err != nil {
+				return err
+			}
+			verses = verses[:0]
+		}
+		current = i
+		started = true
+
+		if verseNumber.Valid {
+			verses = append(verses, VerseSmall{
+				VerseNumber: int(verseNumber.Int64),
+				VerseText:   verseText.String,
+			})
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.ExportSongs | rows.Err", "error", err.Error())
+		}
+		return err
+	}
+
+	if started {
+		return fn(current, verses)
+	}
+	return nil
+}
+
+// exportSongsQuery selects songs with verses, song without verses is one row with null verse
+func exportSongsQuery(filter SongFilter) sq.SelectBuilder {
+	return sq.Select(songColumns...).Columns("verse_number", "verse_text").
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		LeftJoin("verses USING(song_id)").
+		Where(filter.conditions()).
+		OrderBy("song_id", "verse_number").
+		PlaceholderFormat(sq.Dollar)
+}
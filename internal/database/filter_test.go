@@ -0,0 +1,80 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestSongTextFilter checks that text filter matches verses in subquery:
+// joined verses would repeat song once per matching verse and need DISTINCT,
+// songs without verses would disappear from unfiltered list
+// and export would lose verses not matching the filter
+func TestSongTextFilter(t *testing.T) {
+	text := "bloom"
+
+	t.Run("list without text filter", func(t *testing.T) {
+		query, _, err := getSongsQuery(GetSongsRequest{}).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if strings.Contains(query, "verses") {
+			t.Errorf("songs without verses are filtered out by %q", query)
+		}
+	})
+
+	t.Run("list with text filter", func(t *testing.T) {
+		query, args, err := getSongsQuery(GetSongsRequest{SongFilter: SongFilter{SongText: &text}}).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if strings.Contains(query, "JOIN verses") || strings.Contains(query, "DISTINCT") {
+			t.Errorf("verses are joined to song list: %q", query)
+		}
+		if !strings.Contains(query, "song_id IN (SELECT song_id FROM verses WHERE verse_text ILIKE $1)") {
+			t.Errorf("text is not matched in subquery: %q", query)
+		}
+		if len(args) == 0 || args[0] != "%bloom%" {
+			t.Errorf("args %v, want %%bloom%% first", args)
+		}
+	})
+
+	t.Run("export with text filter", func(t *testing.T) {
+		query, _, err := exportSongsQuery(SongFilter{SongText: &text}).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if !strings.Contains(query, "LEFT JOIN verses USING(song_id)") {
+			t.Errorf("export does not keep songs without verses: %q", query)
+		}
+		//filter on joined verse_text would export only matching verses
+		if strings.Contains(query, "WHERE (verse_text ILIKE") {
+			t.Errorf("export filters joined verses: %q", query)
+		}
+		if !strings.Contains(query, "song_id IN (SELECT song_id FROM verses WHERE verse_text ILIKE $1)") {
+			t.Errorf("text is not matched in subquery: %q", query)
+		}
+	})
+}
+
+// TestCountSongsFilter checks that total count of list is narrowed by the same filters as its page
+func TestCountSongsFilter(t *testing.T) {
+	group, text := "Muse", "bloom"
+	filter := SongFilter{GroupName: &group, SongText: &text}
+
+	countQuery, countArgs, err := countSongsQuery(filter).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %v", err)
+	}
+	listQuery, listArgs, err := getSongsQuery(GetSongsRequest{SongFilter: filter}).ToSql()
+	if err != nil {
+		t.Fatalf("ToSql: %v", err)
+	}
+
+	where := countQuery[strings.Index(countQuery, " WHERE "):]
+	if !strings.Contains(listQuery, where) {
+		t.Errorf("total count %q is not narrowed like list %q", countQuery, listQuery)
+	}
+	if len(countArgs) != 2 || len(listArgs) < 2 || countArgs[0] != listArgs[0] || countArgs[1] != listArgs[1] {
+		t.Errorf("count args %v, list args %v", countArgs, listArgs)
+	}
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type GetGroupsRequest struct {
+	Name   *string
+	Limit  uint64
+	Offset uint64
+}
+
+// GetGroups returns groups ordered by name, Name filters by part of group name
+func (q *Queries) GetGroups(ctx context.Context, request GetGroupsRequest) ([]Group, error) {
+	sqlQuery := sq.Select("group_id", "name").
+		From("groups").
+		OrderBy("name", "group_id").
+		Limit(SongsLimit(request.Limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	if request.Name != nil {
+		sqlQuery = sqlQuery.Where(ILikeAny("name", *request.Name))
+	}
+
+	if request.Offset > 0 {
+		sqlQuery = sqlQuery.Offset(request.Offset)
+	}
+
+	return q.queryGroups(ctx, "database.GetGroups", sqlQuery)
+}
+
+// GetGroupsByName returns groups with exact names, missing names are skipped
+func (q *Queries) GetGroupsByName(ctx context.Context, names []string) ([]Group, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	sqlQuery := sq.Select("group_id", "name").
+		From("groups").
+		Where(sq.Eq{"name": names}).
+		PlaceholderFormat(sq.Dollar)
+
+	return q.queryGroups(ctx, "database.GetGroupsByName", sqlQuery)
+}
+
+func (q *Queries) queryGroups(ctx context.Context, caller string, sqlQuery sq.SelectBuilder) ([]Group, error) {
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error(caller+" | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []Group
+	for rows.Next() {
+		var i Group
+		if err := rows.Scan(&i.ID, &i.Name); err != nil {
+			if q.debug {
+				q.log.Error(caller+" | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		groups = append(groups, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error(caller+" | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// GetGroupsSongs returns songs of several groups in one query keyed by group id,
+// songs of each group are in default list order, first > 0 limits songs of each group
+func (q *Queries) GetGroupsSongs(ctx context.Context, groupIDs []int, first int) (map[int][]Song, error) {
+	songs := make(map[int][]Song, len(groupIDs))
+	if len(groupIDs) == 0 {
+		return songs, nil
+	}
+
+	sqlQuery := groupsSongsQuery(groupIDs, first)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetGroupsSongs | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var groupID, rowNumber int
+		var i Song
+		if err := rows.Scan(append(append([]any{&groupID}, songDest(&i)...), &rowNumber)...); err != nil {
+			if q.debug {
+				q.log.Error("database.GetGroupsSongs | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		songs[groupID] = append(songs[groupID], i)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetGroupsSongs | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// groupsSongsQuery numbers songs inside each group in default list order,
+// so limit of songs per group is applied by database and not after fetching all of them
+func groupsSongsQuery(groupIDs []int, first int) sq.SelectBuilder {
+	orderBy := strings.Join(songOrderBy(songSortColumnsFor(DefaultSongSort)), ", ")
+	ranked := sq.Select("songs.group_id").Columns(songColumns...).
+		Column("ROW_NUMBER() OVER (PARTITION BY songs.group_id ORDER BY " + orderBy + ") AS row_number").
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(sq.Eq{"songs.group_id": groupIDs})
+
+	sqlQuery := sq.Select("*").FromSelect(ranked, "ranked").
+		OrderBy("group_id", "row_number").
+		PlaceholderFormat(sq.Dollar)
+	if first > 0 {
+		sqlQuery = sqlQuery.Where(sq.LtOrEq{"row_number": first})
+	}
+	return sqlQuery
+}
@@ -0,0 +1,52 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNestedListsLimit checks that first of nested graphql lists limits rows of each owner
+// in database and is not applied after all rows are fetched
+func TestNestedListsLimit(t *testing.T) {
+	t.Run("group songs", func(t *testing.T) {
+		query, args, err := groupsSongsQuery([]int{1, 2}, 3).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if !strings.Contains(query, "ROW_NUMBER() OVER (PARTITION BY songs.group_id ORDER BY") {
+			t.Errorf("songs are not numbered per group: %q", query)
+		}
+		if !strings.Contains(query, "WHERE row_number <= $3") {
+			t.Errorf("songs per group are not limited: %q", query)
+		}
+		if len(args) != 3 || args[2] != 3 {
+			t.Errorf("args %v, want group ids and limit 3", args)
+		}
+	})
+
+	t.Run("group songs without first", func(t *testing.T) {
+		query, _, err := groupsSongsQuery([]int{1, 2}, 0).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if strings.Contains(query, "row_number <=") {
+			t.Errorf("songs are limited without first: %q", query)
+		}
+	})
+
+	t.Run("song verses", func(t *testing.T) {
+		query, args, err := songsVersesQuery([]int{1, 2}, 2).ToSql()
+		if err != nil {
+			t.Fatalf("ToSql: %v", err)
+		}
+		if !strings.Contains(query, "ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY verse_number)") {
+			t.Errorf("verses are not numbered per song: %q", query)
+		}
+		if !strings.Contains(query, "WHERE row_number <= $3") {
+			t.Errorf("verses per song are not limited: %q", query)
+		}
+		if len(args) != 3 || args[2] != 2 {
+			t.Errorf("args %v, want song ids and limit 2", args)
+		}
+	})
+}
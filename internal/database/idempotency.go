@@ -0,0 +1,188 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+var idempotencyColumns = []string{"scope", "idempotency_key", "fingerprint", "owner", "status", "header", "body",
+	"created_at", "expires_at"}
+
+// reserveIdempotencyAttempts limits retries of reserve when stored key is deleted between insert and select
+const reserveIdempotencyAttempts = 3
+
+type ReserveIdempotencyKeyRequest struct {
+	Scope       string        `json:"scope"`
+	Key         string        `json:"key"`
+	Fingerprint string        `json:"fingerprint"`
+	Owner       string        `json:"owner"`
+	Lease       time.Duration `json:"lease"`
+}
+
+// ReserveIdempotencyKey stores key of request in progress for Lease, so key of crashed request
+// is taken over soon. Expired key is taken over.
+// Returns reserved true for new key, otherwise stored key is returned as is
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, request ReserveIdempotencyKeyRequest) (*IdempotencyKey, bool, error) {
+	for attempt := 1; ; attempt++ {
+		key, err := q.insertIdempotencyKey(ctx, request)
+		if err == nil {
+			return key, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			if q.debug {
+				q.log.Error("database.ReserveIdempotencyKey | insert.QueryRowContext", "error", err.Error())
+			}
+			return nil, false, err
+		}
+
+		//key is taken by not expired request
+		key, err = scanIdempotencyKey(sq.Select(idempotencyColumns...).
+			From("idempotency_keys").
+			Where(sq.Eq{"scope": request.Scope, "idempotency_key": request.Key}).
+			PlaceholderFormat(sq.Dollar).
+			RunWith(q.db).QueryRowContext(ctx))
+		if errors.Is(err, sql.ErrNoRows) && attempt < reserveIdempotencyAttempts {
+			//key was released or deleted as expired after insert, it is free now
+			continue
+		}
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.ReserveIdempotencyKey | select.QueryRowContext", "error", err.Error())
+			}
+			return nil, false, err
+		}
+		return key, false, nil
+	}
+}
+
+// insertIdempotencyKey inserts new key or takes over expired one, sql.ErrNoRows is returned for taken key
+func (q *Queries) insertIdempotencyKey(ctx context.Context, request ReserveIdempotencyKeyRequest) (*IdempotencyKey, error) {
+	expiresAt := time.Now().Add(request.Lease)
+
+	sqlQuery := sq.Insert("idempotency_keys").
+		Columns("scope", "idempotency_key", "fingerprint", "owner", "expires_at").
+		Values(request.Scope, request.Key, request.Fingerprint, request.Owner, expiresAt).
+		Suffix(`ON CONFLICT (scope, idempotency_key) DO UPDATE
+			SET fingerprint = EXCLUDED.fingerprint, owner = EXCLUDED.owner, status = NULL, header = NULL, body = NULL,
+				created_at = now(), expires_at = EXCLUDED.expires_at
+			WHERE idempotency_keys.expires_at <= now()
+			RETURNING ` + strings.Join(idempotencyColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	return scanIdempotencyKey(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+}
+
+type SaveIdempotentResponseRequest struct {
+	Scope  string            `json:"scope"`
+	Key    string            `json:"key"`
+	Owner  string            `json:"owner"`
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   []byte            `json:"body"`
+	TTL    time.Duration     `json:"ttl"`
+}
+
+// SaveIdempotentResponse stores response of key reserved by Owner, it is kept for TTL.
+// Returns sql.ErrNoRows when key expired and was taken over by another request
+func (q *Queries) SaveIdempotentResponse(ctx context.Context, request SaveIdempotentResponseRequest) error {
+	header, err := json.Marshal(request.Header)
+	if err != nil {
+		return err
+	}
+
+	result, err := sq.Update("idempotency_keys").
+		Set("status", request.Status).
+		Set("header", header).
+		Set("body", request.Body).
+		Set("expires_at", time.Now().Add(request.TTL)).
+		Where(sq.Eq{"scope": request.Scope, "idempotency_key": request.Key, "owner": request.Owner}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.SaveIdempotentResponse | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.SaveIdempotentResponse | RowsAffected", "error", err.Error())
+		}
+		return err
+	}
+	if updated == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteIdempotencyKey releases key reserved by owner, request with it is executed again.
+// Key taken over by another request is not changed
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, scope, key, owner string) error {
+	_, err := sq.Delete("idempotency_keys").
+		Where(sq.Eq{"scope": scope, "idempotency_key": key, "owner": owner}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteIdempotencyKey | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteExpiredIdempotencyKeys deletes keys expired before time
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	result, err := sq.Delete("idempotency_keys").
+		Where(sq.Lt{"expires_at": before}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteExpiredIdempotencyKeys | ExecContext", "error", err.Error())
+		}
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteExpiredIdempotencyKeys | RowsAffected", "error", err.Error())
+		}
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func scanIdempotencyKey(row sq.RowScanner) (*IdempotencyKey, error) {
+	var i IdempotencyKey
+	var header []byte
+	if err := row.Scan(
+		&i.Scope,
+		&i.Key,
+		&i.Fingerprint,
+		&i.Owner,
+		&i.Status,
+		&header,
+		&i.Body,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	); err != nil {
+		return nil, err
+	}
+	if len(header) > 0 {
+		if err := json.Unmarshal(header, &i.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &i, nil
+}
@@ -0,0 +1,68 @@
+package database
+
+import (
+	"context"
+)
+
+type ImportSongResult struct {
+	SongID int64 `json:"song_id"`
+	Err    error `json:"-"`
+}
+
+// ImportSongs inserts batch of songs in one transaction, every song uses own savepoint
+// so failed song does not break the batch. With dryRun transaction is rolled back
+func (q *Queries) ImportSongs(ctx context.Context, requests []AddSongRequest, dryRun bool) ([]ImportSongResult, error) {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.ImportSongs | BeginTx", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	results := make([]ImportSongResult, len(requests))
+	for idx, request := range requests {
+		if _, err := tx.ExecContext(ctx, "SAVEPOINT import_song"); err != nil {
+			if q.debug {
+				q.log.Error("database.ImportSongs | SAVEPOINT", "error", err.Error())
+			}
+			return nil, err
+		}
+
+		inserted, err := q.insertSong(ctx, tx, request)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			if _, rbErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT import_song"); rbErr != nil {
+				if q.debug {
+					q.log.Error("database.ImportSongs | ROLLBACK TO SAVEPOINT", "error", rbErr.Error())
+				}
+				return nil, rbErr
+			}
+			results[idx].Err = err
+			continue
+		}
+
+		if _, err := tx.ExecContext(ctx, "RELEASE SAVEPOINT import_song"); err != nil {
+			if q.debug {
+				q.log.Error("database.ImportSongs | RELEASE SAVEPOINT", "error", err.Error())
+			}
+			return nil, err
+		}
+		results[idx].SongID = inserted.SongID
+	}
+
+	if dryRun {
+		return results, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		if q.debug {
+			q.log.Error("database.ImportSongs | Commit", "error", err.Error())
+		}
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	JobStatusPending = "pending"
+	JobStatusRunning = "running"
+	JobStatusDone    = "done"
+	JobStatusFailed  = "failed"
+)
+
+var jobColumns = []string{"job_id", "kind", "status", "payload", "song_id", "error", "attempt", "created_at", "updated_at"}
+
+type CreateJobRequest struct {
+	Kind    string `json:"kind"`
+	Payload []byte `json:"payload"`
+}
+
+func (q *Queries) CreateJob(ctx context.Context, request CreateJobRequest) (int64, error) {
+	sqlQuery := sq.Insert("jobs").Columns("kind", "status", "payload").
+		Values(request.Kind, JobStatusPending, request.Payload).
+		Suffix("RETURNING job_id").
+		PlaceholderFormat(sq.Dollar)
+
+	var jobID int64
+	if err := sqlQuery.RunWith(q.db).QueryRowContext(ctx).Scan(&jobID); err != nil {
+		if q.debug {
+			q.log.Error("database.CreateJob | QueryRowContext", "error", err.Error())
+		}
+		return 0, err
+	}
+	return jobID, nil
+}
+
+func (q *Queries) GetJob(ctx context.Context, jobID int64) (*Job, error) {
+	sqlQuery := sq.Select(jobColumns...).
+		From("jobs").
+		Where(sq.Eq{"job_id": jobID}).
+		PlaceholderFormat(sq.Dollar)
+
+	job, err := scanJob(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetJob | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+// ClaimJob marks the oldest pending job of kind as running and leases it for lease.
+// Running jobs whose lease expired (worker died) are claimed again.
+// Every claim increments attempt of the job, it is required to finish or release the job
+// Returns sql.ErrNoRows when there is nothing to do
+func (q *Queries) ClaimJob(ctx context.Context, kind string, lease time.Duration) (*Job, error) {
+	pending := sq.Select("job_id").
+		From("jobs").
+		Where(sq.Eq{"kind": kind}).
+		Where(sq.Or{
+			sq.Eq{"status": JobStatusPending},
+			sq.And{
+				sq.Eq{"status": JobStatusRunning},
+				sq.Expr("(locked_until IS NULL OR locked_until < now())"),
+			},
+		}).
+		OrderBy("job_id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sqlQuery := sq.Update("jobs").
+		Set("status", JobStatusRunning).
+		Set("attempt", sq.Expr("attempt + 1")).
+		Set("locked_until", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Expr("job_id = (?)", pending)).
+		Suffix("RETURNING " + strings.Join(jobColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	job, err := scanJob(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug && !errors.Is(err, sql.ErrNoRows) {
+			q.log.Error("database.ClaimJob | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return job, nil
+}
+
+type FinishJobRequest struct {
+	JobID   int64   `json:"job_id"`
+	Attempt int     `json:"attempt"`
+	Status  string  `json:"status"`
+	SongID  *int    `json:"song_id,omitempty"`
+	Error   *string `json:"error,omitempty"`
+}
+
+// FinishJob stores result of the claimed attempt of job.
+// Returns sql.ErrNoRows when the lease expired and job was claimed again by another worker
+func (q *Queries) FinishJob(ctx context.Context, request FinishJobRequest) error {
+	sqlQuery := sq.Update("jobs").
+		Set("status", request.Status).
+		Set("song_id", request.SongID).
+		Set("error", request.Error).
+		Set("locked_until", nil).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"job_id": request.JobID, "status": JobStatusRunning, "attempt": request.Attempt}).
+		PlaceholderFormat(sq.Dollar)
+
+	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.FinishJob | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.FinishJob | RowsAffected", "error", err.Error())
+		}
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReleaseJob returns running job to the queue without waiting for its lease to expire,
+// job claimed again by another worker is not changed
+func (q *Queries) ReleaseJob(ctx context.Context, jobID int64, attempt int) error {
+	sqlQuery := sq.Update("jobs").
+		Set("status", JobStatusPending).
+		Set("locked_until", nil).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"job_id": jobID, "status": JobStatusRunning, "attempt": attempt}).
+		PlaceholderFormat(sq.Dollar)
+
+	if _, err := sqlQuery.RunWith(q.db).ExecContext(ctx); err != nil {
+		if q.debug {
+			q.log.Error("database.ReleaseJob | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+func scanJob(row sq.RowScanner) (*Job, error) {
+	var i Job
+	var songID sql.NullInt64
+	var jobErr sql.NullString
+	if err := row.Scan(
+		&i.JobID,
+		&i.Kind,
+		&i.Status,
+		&i.Payload,
+		&songID,
+		&jobErr,
+		&i.Attempt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if songID.Valid {
+		id := int(songID.Int64)
+		i.SongID = &id
+	}
+	if jobErr.Valid {
+		i.Error = &jobErr.String
+	}
+	return &i, nil
+}
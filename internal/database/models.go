@@ -10,11 +10,15 @@ type Group struct {
 }
 
 type Song struct {
-	SongID      int       `json:"song_id"`
-	GroupName   string    `json:"group_name"`
-	SongName    string    `json:"song_name"`
-	ReleaseDate time.Time `json:"release_date,omitempty"`
-	Link        string    `json:"link,omitempty"`
+	SongID               int        `json:"song_id"`
+	GroupName            string     `json:"group_name"`
+	SongName             string     `json:"song_name"`
+	ReleaseDate          *time.Time `json:"release_date,omitempty"`
+	ReleaseDatePrecision string     `json:"release_date_precision,omitempty"`
+	Link                 string     `json:"link,omitempty"`
+	Version              int        `json:"version"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
 }
 
 type Verse struct {
@@ -25,6 +29,95 @@ type Verse struct {
 }
 
 type VerseSmall struct {
-	VerseNumber int    `json:"verse_number"`
-	VerseText   string `json:"verse_text"`
+	VerseNumber int       `json:"verse_number"`
+	VerseText   string    `json:"verse_text"`
+	Version     int       `json:"version,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type Job struct {
+	JobID     int64     `json:"job_id"`
+	Kind      string    `json:"kind"`
+	Status    string    `json:"status"`
+	Payload   []byte    `json:"payload"`
+	SongID    *int      `json:"song_id,omitempty"`
+	Error     *string   `json:"error,omitempty"`
+	Attempt   int       `json:"attempt"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type Webhook struct {
+	WebhookID  int       `json:"webhook_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	DeliveryID     int64      `json:"delivery_id"`
+	WebhookID      int        `json:"webhook_id"`
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `json:"payload"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	Error          *string    `json:"error,omitempty"`
+	ReplayOf       *int64     `json:"replay_of,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+type Event struct {
+	EventID   int64     `json:"event_id"`
+	EventType string    `json:"event_type"`
+	SongID    int       `json:"song_id"`
+	GroupName *string   `json:"group_name,omitempty"`
+	Payload   []byte    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type OutboxEvent struct {
+	OutboxID      int64     `json:"outbox_id"`
+	EventType     string    `json:"event_type"`
+	SongID        int       `json:"song_id"`
+	Payload       []byte    `json:"payload"`
+	Attempts      int       `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	Error         *string   `json:"error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// OutboxPayload is state of the song captured in transaction of the change,
+// for deleted song it is state before deletion
+type OutboxPayload struct {
+	Song  *Song       `json:"song,omitempty"`
+	Verse *VerseSmall `json:"verse,omitempty"`
+}
+
+type IdempotencyKey struct {
+	Scope       string            `json:"scope"`
+	Key         string            `json:"key"`
+	Fingerprint string            `json:"fingerprint"`
+	Owner       string            `json:"owner"`
+	Status      *int              `json:"status,omitempty"`
+	Header      map[string]string `json:"header,omitempty"`
+	Body        []byte            `json:"body,omitempty"`
+	CreatedAt   time.Time         `json:"created_at"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+}
+
+type APIKey struct {
+	KeyID      string     `json:"key_id"`
+	Name       string     `json:"name"`
+	KeyHash    string     `json:"key_hash"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
 }
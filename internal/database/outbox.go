@@ -0,0 +1,228 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	EventSongCreated  = "song.created"
+	EventSongUpdated  = "song.updated"
+	EventSongDeleted  = "song.deleted"
+	EventVerseUpdated = "verse.updated"
+
+	defaultOutboxLimit = 100
+)
+
+var outboxColumns = []string{"o.outbox_id", "o.event_type", "o.song_id", "o.payload", "o.attempts",
+	"o.next_attempt_at", "o.error", "o.created_at"}
+
+// addOutbox writes change event of song to outbox using tx of the change,
+// so event is stored only when change is committed. Song row must be already changed
+// (and so locked) by the tx, then outbox ids of a song follow order of its changes
+func (q *Queries) addOutbox(ctx context.Context, tx DBTX, eventType string, songID int, verse *VerseSmall) error {
+	var song Song
+	err := sq.Select(songColumns...).
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(sq.Eq{"song_id": songID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).QueryRowContext(ctx).Scan(songDest(&song)...)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.addOutbox | GetSong", "event_type", eventType, "song_id", songID, "error", err.Error())
+		}
+		return err
+	}
+
+	return q.insertOutbox(ctx, tx, eventType, song, verse)
+}
+
+// insertOutbox writes change event with song already read by the change
+func (q *Queries) insertOutbox(ctx context.Context, tx DBTX, eventType string, song Song, verse *VerseSmall) error {
+	payload, err := json.Marshal(OutboxPayload{Song: &song, Verse: verse})
+	if err != nil {
+		return err
+	}
+
+	songID := song.SongID
+	_, err = sq.Insert("outbox").Columns("event_type", "song_id", "payload").
+		Values(eventType, songID, payload).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.insertOutbox | ExecContext", "event_type", eventType, "song_id", songID, "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+type ClaimOutboxRequest struct {
+	Limit uint64        `json:"limit"`
+	Lease time.Duration `json:"lease"`
+}
+
+// ClaimOutbox leases due unpublished events for publishing outside of transaction, events
+// of relays died while publishing are claimed again after lease. Only the oldest unpublished
+// event of every song is taken, so events of a song are published in order of changes.
+// Events are returned in outbox order
+func (q *Queries) ClaimOutbox(ctx context.Context, request ClaimOutboxRequest) ([]OutboxEvent, error) {
+	if request.Limit == 0 {
+		request.Limit = defaultOutboxLimit
+	}
+
+	earlier := sq.Select("1").
+		From("outbox p").
+		Where("p.song_id = o.song_id").
+		Where("p.published_at IS NULL").
+		Where("p.outbox_id < o.outbox_id")
+
+	due := sq.Select("o.outbox_id").
+		From("outbox o").
+		Where("o.published_at IS NULL").
+		Where("o.next_attempt_at <= now()").
+		Where("(o.locked_until IS NULL OR o.locked_until < now())").
+		Where(sq.Expr("NOT EXISTS (?)", earlier)).
+		OrderBy("o.outbox_id").
+		Limit(request.Limit).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sqlQuery := sq.Update("outbox o").
+		Set("locked_until", sq.Expr("now() + make_interval(secs => ?)", request.Lease.Seconds())).
+		Where(sq.Expr("o.outbox_id IN (?)", due)).
+		Suffix("RETURNING " + strings.Join(outboxColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.ClaimOutbox | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []OutboxEvent
+	for rows.Next() {
+		event, err := scanOutboxEvent(rows)
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.ClaimOutbox | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.ClaimOutbox | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].OutboxID < events[j].OutboxID })
+	return events, nil
+}
+
+type FinishOutboxRequest struct {
+	OutboxID int64 `json:"outbox_id"`
+	// Error of failed publish, event is published again at NextAttemptAt
+	Error         *string    `json:"error,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// FinishOutbox records result of publishing claimed event and releases its lease
+func (q *Queries) FinishOutbox(ctx context.Context, request FinishOutboxRequest) error {
+	sqlQuery := sq.Update("outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("error", request.Error).
+		Set("locked_until", nil).
+		Where(sq.Eq{"outbox_id": request.OutboxID}).
+		Where("published_at IS NULL").
+		PlaceholderFormat(sq.Dollar)
+
+	if request.Error == nil {
+		sqlQuery = sqlQuery.Set("published_at", sq.Expr("now()"))
+	}
+	if request.NextAttemptAt != nil {
+		sqlQuery = sqlQuery.Set("next_attempt_at", *request.NextAttemptAt)
+	}
+
+	if _, err := sqlQuery.RunWith(q.db).ExecContext(ctx); err != nil {
+		if q.debug {
+			q.log.Error("database.FinishOutbox | ExecContext", "outbox_id", request.OutboxID, "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// ReleaseOutbox returns claimed events not published yet without waiting for their lease to expire
+func (q *Queries) ReleaseOutbox(ctx context.Context, outboxIDs []int64) error {
+	sqlQuery := sq.Update("outbox").
+		Set("locked_until", nil).
+		Where(sq.Eq{"outbox_id": outboxIDs}).
+		Where("published_at IS NULL").
+		PlaceholderFormat(sq.Dollar)
+
+	if _, err := sqlQuery.RunWith(q.db).ExecContext(ctx); err != nil {
+		if q.debug {
+			q.log.Error("database.ReleaseOutbox | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+// DeletePublishedOutbox removes events published before given time, returns count of deleted events
+func (q *Queries) DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error) {
+	sqlQuery := sq.Delete("outbox").
+		Where(sq.Lt{"published_at": before}).
+		PlaceholderFormat(sq.Dollar)
+
+	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeletePublishedOutbox | ExecContext", "error", err.Error())
+		}
+		return 0, err
+	}
+
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeletePublishedOutbox | RowsAffected", "error", err.Error())
+		}
+		return 0, err
+	}
+	return deleted, nil
+}
+
+func scanOutboxEvent(row sq.RowScanner) (*OutboxEvent, error) {
+	var i OutboxEvent
+	var eventErr sql.NullString
+	if err := row.Scan(
+		&i.OutboxID,
+		&i.EventType,
+		&i.SongID,
+		&i.Payload,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&eventErr,
+		&i.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	if eventErr.Valid {
+		i.Error = &eventErr.String
+	}
+	return &i, nil
+}
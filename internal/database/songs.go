@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt" //nolint:gci
+	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -12,19 +13,85 @@ import (
 )
 
 var (
-	ErrDuplicateKey = fmt.Errorf("duplicate key value violates uniqueness constraint")
+	ErrDuplicateKey    = fmt.Errorf("duplicate key value violates uniqueness constraint")
+	ErrVersionMismatch = fmt.Errorf("row version does not match expected version")
+	//ErrSongVersionMismatch is version mismatch of song changed with its verse
+	ErrSongVersionMismatch = fmt.Errorf("song %w", ErrVersionMismatch)
 )
 
 type Storage interface {
+	InTx(ctx context.Context, fn func(s Storage) error) error
 	GetGroupID(ctx context.Context, groupName string) (int64, error)
-	CountSongs(ctx context.Context) (int, error)
+	GetGroups(ctx context.Context, request GetGroupsRequest) ([]Group, error)
+	GetGroupsByName(ctx context.Context, names []string) ([]Group, error)
+	GetGroupsSongs(ctx context.Context, groupIDs []int, first int) (map[int][]Song, error)
+	CountSongs(ctx context.Context, filter SongFilter) (int, error)
 	GetSongs(ctx context.Context, request GetSongsRequest) ([]Song, error)
+	GetSong(ctx context.Context, SongID int) (*Song, error)
+	ExportSongs(ctx context.Context, filter SongFilter, fn func(song Song, verses []VerseSmall) error) error
 	CountVerses(ctx context.Context, SongID int) (int, error)
 	GetVerses(ctx context.Context, request GetVersesRequest) ([]VerseSmall, error)
+	GetSongsVerses(ctx context.Context, songIDs []int, first int) (map[int][]VerseSmall, error)
 	AddSong(ctx context.Context, request AddSongRequest) (*AddSongResponse, error)
-	UpdateSong(ctx context.Context, request UpdateSongRequest) error
-	UpdateVerse(ctx context.Context, request UpdateVerseRequest) error
+	ImportSongs(ctx context.Context, requests []AddSongRequest, dryRun bool) ([]ImportSongResult, error)
+	GetVerse(ctx context.Context, SongID int, verseNumber int) (*VerseSmall, error)
+	UpdateSong(ctx context.Context, request UpdateSongRequest) (*Song, error)
+	UpdateVerse(ctx context.Context, request UpdateVerseRequest) (*UpdateVerseResponse, error)
 	DeleteSong(ctx context.Context, SongID int) error
+	ListSongsForSync(ctx context.Context, request ListSongsForSyncRequest) ([]Song, error)
+	GetAllVerses(ctx context.Context, SongID int) ([]VerseSmall, error)
+	SyncSong(ctx context.Context, request SyncSongRequest) error
+	CreateJob(ctx context.Context, request CreateJobRequest) (int64, error)
+	GetJob(ctx context.Context, jobID int64) (*Job, error)
+	ClaimJob(ctx context.Context, kind string, lease time.Duration) (*Job, error)
+	FinishJob(ctx context.Context, request FinishJobRequest) error
+	ReleaseJob(ctx context.Context, jobID int64, attempt int) error
+	CreateWebhook(ctx context.Context, request CreateWebhookRequest) (*Webhook, error)
+	GetWebhook(ctx context.Context, webhookID int) (*Webhook, error)
+	GetWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int) error
+	EnqueueWebhookDeliveries(ctx context.Context, request EnqueueWebhookDeliveriesRequest) (int64, error)
+	ClaimWebhookDelivery(ctx context.Context, lease time.Duration) (*WebhookDelivery, error)
+	FinishWebhookDelivery(ctx context.Context, request FinishWebhookDeliveryRequest) error
+	ReleaseWebhookDelivery(ctx context.Context, deliveryID int64) error
+	GetWebhookDeliveries(ctx context.Context, request GetWebhookDeliveriesRequest) ([]WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, deliveryID int64) (*WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, deliveryID int64) (*WebhookDelivery, error)
+	AddEvent(ctx context.Context, request AddEventRequest) (*Event, error)
+	ClaimOutbox(ctx context.Context, request ClaimOutboxRequest) ([]OutboxEvent, error)
+	FinishOutbox(ctx context.Context, request FinishOutboxRequest) error
+	ReleaseOutbox(ctx context.Context, outboxIDs []int64) error
+	DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error)
+	GetEvents(ctx context.Context, request GetEventsRequest) ([]Event, error)
+	GetLastEventID(ctx context.Context) (int64, error)
+	DeleteEvents(ctx context.Context, before time.Time) (int64, error)
+	ReserveIdempotencyKey(ctx context.Context, request ReserveIdempotencyKeyRequest) (*IdempotencyKey, bool, error)
+	SaveIdempotentResponse(ctx context.Context, request SaveIdempotentResponseRequest) error
+	DeleteIdempotencyKey(ctx context.Context, scope, key, owner string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+	CreateAPIKey(ctx context.Context, request CreateAPIKeyRequest) (*APIKey, error)
+	GetAPIKey(ctx context.Context, keyID string) (*APIKey, error)
+	GetAPIKeys(ctx context.Context) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, keyID string) error
+	TouchAPIKey(ctx context.Context, keyID string) error
+}
+
+// songColumns are selected from songs joined with groups, scan them with songDest
+var songColumns = []string{"song_id", "name", "song", "releaseDate", "COALESCE(release_date_precision, '')", "link",
+	"songs.version", "songs.created_at", "songs.updated_at"}
+
+func songDest(i *Song) []any {
+	return []any{
+		&i.SongID,
+		&i.GroupName,
+		&i.SongName,
+		&i.ReleaseDate,
+		&i.ReleaseDatePrecision,
+		&i.Link,
+		&i.Version,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	}
 }
 
 func ILikeAny(column string, value string) sq.Sqlizer {
@@ -46,9 +113,10 @@ func (q *Queries) GetGroupID(ctx context.Context, groupName string) (int64, erro
 	return groupID, nil
 }
 
-func (q *Queries) CountSongs(ctx context.Context) (int, error) {
+// CountSongs returns count of songs matching filter, it is total count of GetSongs list
+func (q *Queries) CountSongs(ctx context.Context, filter SongFilter) (int, error) {
 	var countSongs int
-	sqlQuery := sq.Select("COUNT(song_id)").From("songs")
+	sqlQuery := countSongsQuery(filter)
 	err := sqlQuery.RunWith(q.db).QueryRowContext(ctx).Scan(&countSongs)
 	if err != nil {
 		if q.debug {
@@ -59,50 +127,85 @@ func (q *Queries) CountSongs(ctx context.Context) (int, error) {
 	return countSongs, nil
 }
 
-type GetSongsRequest struct {
-	GroupName   *string    `json:"group_name,omitempty" form:"group_name"`
-	SongName    *string    `json:"song_name,omitempty" form:"song_name"`
-	ReleaseDate *time.Time `json:"release_date,omitempty" form:"release_date"`
-	SongText    *string    `json:"song_text,omitempty" form:"song_text"`
-	Limit       uint64     `json:"limit" form:"limit"`
-	Offset      uint64     `json:"offset" form:"offset"`
+// SongFilter is song list filter shared by GetSongs and ExportSongs
+type SongFilter struct {
+	GroupName    *string    `json:"group_name,omitempty" form:"group_name"`
+	SongName     *string    `json:"song_name,omitempty" form:"song_name"`
+	ReleaseDate  *time.Time `json:"release_date,omitempty" form:"release_date"`
+	SongText     *string    `json:"song_text,omitempty" form:"song_text"`
+	UpdatedSince *time.Time `json:"updated_since,omitempty" form:"updated_since"`
+	// ReleasedFrom is inclusive and ReleasedBefore is exclusive bound of release date
+	ReleasedFrom   *time.Time `json:"released_from,omitempty" form:"released_from"`
+	ReleasedBefore *time.Time `json:"released_before,omitempty" form:"released_before"`
+	Year           *int       `json:"year,omitempty" form:"year"`
+	Decade         *int       `json:"decade,omitempty" form:"decade"`
 }
 
-func (q *Queries) GetSongs(ctx context.Context, request GetSongsRequest) ([]Song, error) {
-	sqlQuery := sq.Select("DISTINCT song_id", "name", "song", "releaseDate", "link").
-		From("songs").
-		InnerJoin("groups USING(group_id)").
-		InnerJoin("verses USING(song_id)").PlaceholderFormat(sq.Dollar)
+// conditions builds where clause, query must select from songs joined with groups
+func (f SongFilter) conditions() sq.And {
+	where := sq.And{}
 
-	if request.SongText != nil {
-		sqlQuery = sqlQuery.Where(ILikeAny("verse_text", *request.SongText))
+	//verses are matched in subquery, not joined: song is listed once without DISTINCT,
+	//songs without verses stay in the list and export keeps every verse of matched song
+	if f.SongText != nil {
+		where = append(where, sq.Expr("song_id IN (?)",
+			sq.Select("song_id").From("verses").Where(ILikeAny("verse_text", *f.SongText))))
 	}
 
-	if request.GroupName != nil {
-		sqlQuery = sqlQuery.Where(ILikeAny("name", *request.GroupName))
+	if f.GroupName != nil {
+		where = append(where, ILikeAny("name", *f.GroupName))
 	}
 
-	if request.SongName != nil {
-		sqlQuery = sqlQuery.Where(ILikeAny("song", *request.SongName))
+	if f.SongName != nil {
+		where = append(where, ILikeAny("song", *f.SongName))
 	}
 
-	if request.ReleaseDate != nil {
-		sqlQuery = sqlQuery.Where(sq.Eq{"releaseDate": *request.ReleaseDate})
+	if f.ReleaseDate != nil {
+		where = append(where, sq.Eq{"releaseDate": *f.ReleaseDate})
 	}
 
-	sqlQuery = sqlQuery.OrderBy("releaseDate DESC")
+	if f.UpdatedSince != nil {
+		where = append(where, sq.GtOrEq{"songs.updated_at": *f.UpdatedSince})
+	}
 
-	if request.Limit > 0 && request.Limit <= 100 {
-		sqlQuery = sqlQuery.Limit(request.Limit)
-	} else {
-		sqlQuery = sqlQuery.Limit(10)
+	if f.ReleasedFrom != nil {
+		where = append(where, sq.GtOrEq{"releaseDate": *f.ReleasedFrom})
 	}
 
-	if request.Offset > 0 {
-		sqlQuery = sqlQuery.Offset(request.Offset)
+	if f.ReleasedBefore != nil {
+		where = append(where, sq.Lt{"releaseDate": *f.ReleasedBefore})
 	}
 
-	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if f.Year != nil {
+		where = append(where, releasedBetween(*f.Year, *f.Year+1))
+	}
+
+	if f.Decade != nil {
+		where = append(where, releasedBetween(*f.Decade, *f.Decade+10))
+	}
+
+	return where
+}
+
+// releasedBetween matches release dates from first day of year "from" until year "to",
+// ranges keep releaseDate index usable
+func releasedBetween(from, to int) sq.And {
+	return sq.And{
+		sq.GtOrEq{"releaseDate": time.Date(from, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		sq.Lt{"releaseDate": time.Date(to, time.January, 1, 0, 0, 0, 0, time.UTC)},
+	}
+}
+
+type GetSongsRequest struct {
+	SongFilter
+	Sort   []SortKey   `json:"sort,omitempty"`
+	After  *SongCursor `json:"after,omitempty"`
+	Limit  uint64      `json:"limit" form:"limit"`
+	Offset uint64      `json:"offset" form:"offset"`
+}
+
+func (q *Queries) GetSongs(ctx context.Context, request GetSongsRequest) ([]Song, error) {
+	rows, err := getSongsQuery(request).RunWith(q.db).QueryContext(ctx)
 	if err != nil {
 		if q.debug {
 			q.log.Error("database.GetSongs | QueryContext", "error", err.Error())
@@ -114,13 +217,7 @@ func (q *Queries) GetSongs(ctx context.Context, request GetSongsRequest) ([]Song
 	var songList []Song
 	for rows.Next() {
 		var i Song
-		if err := rows.Scan(
-			&i.SongID,
-			&i.GroupName,
-			&i.SongName,
-			&i.ReleaseDate,
-			&i.Link,
-		); err != nil {
+		if err := rows.Scan(songDest(&i)...); err != nil {
 			if q.debug {
 				q.log.Error("database.GetSongs | row.Scan", "error", err.Error())
 			}
@@ -143,6 +240,56 @@ func (q *Queries) GetSongs(ctx context.Context, request GetSongsRequest) ([]Song
 	return songList, nil
 }
 
+// countSongsQuery counts songs with the same where clause as getSongsQuery
+func countSongsQuery(filter SongFilter) sq.SelectBuilder {
+	return sq.Select("COUNT(song_id)").
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(filter.conditions()).
+		PlaceholderFormat(sq.Dollar)
+}
+
+// getSongsQuery builds page query of GetSongs
+func getSongsQuery(request GetSongsRequest) sq.SelectBuilder {
+	sqlQuery := sq.Select(songColumns...).
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(request.conditions()).
+		PlaceholderFormat(sq.Dollar)
+
+	columns := songSortColumnsFor(request.Sort)
+	sqlQuery = sqlQuery.OrderBy(songOrderBy(columns)...)
+
+	if request.After != nil {
+		sqlQuery = sqlQuery.Where(songsAfter(columns, *request.After))
+	}
+
+	sqlQuery = sqlQuery.Limit(SongsLimit(request.Limit))
+
+	if request.Offset > 0 {
+		sqlQuery = sqlQuery.Offset(request.Offset)
+	}
+
+	return sqlQuery
+}
+
+func (q *Queries) GetSong(ctx context.Context, SongID int) (*Song, error) {
+	sqlQuery := sq.Select(songColumns...).
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(sq.Eq{"song_id": SongID}).
+		PlaceholderFormat(sq.Dollar)
+
+	var i Song
+	if err := sqlQuery.RunWith(q.db).QueryRowContext(ctx).Scan(songDest(&i)...); err != nil {
+		if q.debug {
+			q.log.Error("database.GetSong | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return &i, nil
+}
+
 func (q *Queries) CountVerses(ctx context.Context, SongID int) (int, error) {
 	var verseCount int
 	sqlQuery := sq.Select("COUNT(verse_id)").
@@ -156,6 +303,17 @@ func (q *Queries) CountVerses(ctx context.Context, SongID int) (int, error) {
 	return verseCount, nil
 }
 
+// DefaultVersesLimit is page size of GetVerses when limit is not passed
+const DefaultVersesLimit = 2
+
+// VersesLimit returns page size used by GetVerses for requested limit
+func VersesLimit(limit int) int {
+	if limit > 0 {
+		return limit
+	}
+	return DefaultVersesLimit
+}
+
 type GetVersesRequest struct {
 	SongID int `json:"song_id" form:"song_id"`
 	Limit  int `json:"limit" form:"limit"`
@@ -163,16 +321,12 @@ type GetVersesRequest struct {
 }
 
 func (q *Queries) GetVerses(ctx context.Context, request GetVersesRequest) ([]VerseSmall, error) {
-	sqlQuery := sq.Select("verse_number", "verse_text").
+	sqlQuery := sq.Select("verse_number", "verse_text", "version", "created_at", "updated_at").
 		From("verses").
 		Where(sq.Eq{"song_id": request.SongID}).
 		OrderBy("verse_number").PlaceholderFormat(sq.Dollar)
 
-	if request.Limit > 0 {
-		sqlQuery = sqlQuery.Limit(uint64(request.Limit))
-	} else {
-		sqlQuery = sqlQuery.Limit(2)
-	}
+	sqlQuery = sqlQuery.Limit(uint64(VersesLimit(request.Limit)))
 
 	if request.Offset > 0 {
 		sqlQuery = sqlQuery.Offset(uint64(request.Offset))
@@ -194,6 +348,9 @@ func (q *Queries) GetVerses(ctx context.Context, request GetVersesRequest) ([]Ve
 		if err := rows.Scan(
 			&i.VerseNumber,
 			&i.VerseText,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
 		); err != nil {
 			if q.debug {
 				q.log.Error("database.GetVerses | row.Scan", "error", err.Error())
@@ -220,25 +377,93 @@ func (q *Queries) GetVerses(ctx context.Context, request GetVersesRequest) ([]Ve
 	return verses, nil
 }
 
+// GetSongsVerses returns verses of songs in one query grouped by song id,
+// first > 0 limits verses of each song
+func (q *Queries) GetSongsVerses(ctx context.Context, songIDs []int, first int) (map[int][]VerseSmall, error) {
+	verses := make(map[int][]VerseSmall, len(songIDs))
+	if len(songIDs) == 0 {
+		return verses, nil
+	}
+
+	sqlQuery := songsVersesQuery(songIDs, first)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetSongsVerses | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var songID int
+		var i VerseSmall
+		if err := rows.Scan(
+			&songID,
+			&i.VerseNumber,
+			&i.VerseText,
+			&i.Version,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			if q.debug {
+				q.log.Error("database.GetSongsVerses | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		verses[songID] = append(verses[songID], i)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetSongsVerses | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	return verses, nil
+}
+
+// songsVersesQuery numbers verses inside each song, so limit of verses per song
+// is applied by database
+func songsVersesQuery(songIDs []int, first int) sq.SelectBuilder {
+	columns := []string{"song_id", "verse_number", "verse_text", "version", "created_at", "updated_at"}
+	if first <= 0 {
+		return sq.Select(columns...).
+			From("verses").
+			Where(sq.Eq{"song_id": songIDs}).
+			OrderBy("song_id", "verse_number").PlaceholderFormat(sq.Dollar)
+	}
+
+	ranked := sq.Select(columns...).
+		Column("ROW_NUMBER() OVER (PARTITION BY song_id ORDER BY verse_number) AS row_number").
+		From("verses").
+		Where(sq.Eq{"song_id": songIDs})
+	return sq.Select(columns...).FromSelect(ranked, "ranked").
+		Where(sq.LtOrEq{"row_number": first}).
+		OrderBy("song_id", "verse_number").PlaceholderFormat(sq.Dollar)
+}
+
 type AddSongRequest struct {
-	GroupName   string       `json:"group_name"`
-	SongName    string       `json:"song_name"`
-	ReleaseDate time.Time    `json:"release_date,omitempty"`
-	Link        string       `json:"link,omitempty"`
-	Verses      []VerseSmall `json:"verses"`
+	GroupName            string       `json:"group_name"`
+	SongName             string       `json:"song_name"`
+	ReleaseDate          *time.Time   `json:"release_date,omitempty"`
+	ReleaseDatePrecision string       `json:"release_date_precision,omitempty"`
+	Link                 string       `json:"link,omitempty"`
+	Verses               []VerseSmall `json:"verses"`
 }
 
 type AddSongResponse struct {
-	SongID int64 `json:"song_id"`
+	SongID    int64     `json:"song_id"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 func (q *Queries) AddSong(ctx context.Context, request AddSongRequest) (*AddSongResponse, error) {
-	var groupID int64
-	var songID int64
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	tx, err := q.db.BeginTx(ctxWithTimeout, nil)
+	tx, err := q.begin(ctxWithTimeout)
 	if err != nil {
 		if q.debug {
 			q.log.Error("database.AddSong | BeginTx", "error", err.Error())
@@ -247,12 +472,31 @@ func (q *Queries) AddSong(ctx context.Context, request AddSongRequest) (*AddSong
 	}
 	defer tx.Rollback() //nolint:errcheck
 
+	response, err := q.insertSong(ctxWithTimeout, tx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		if q.debug {
+			q.log.Error("database.AddSong | Commit", "error", err.Error())
+		}
+		return nil, err
+	}
+	return response, nil
+}
+
+// insertSong inserts group if needed, song and verses using tx
+func (q *Queries) insertSong(ctx context.Context, tx DBTX, request AddSongRequest) (*AddSongResponse, error) {
+	var groupID int64
+	var response AddSongResponse
+
 	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
 	insertGroup := psql.Insert("groups").Columns("name").
 		Values(request.GroupName).
 		Suffix("ON CONFLICT (name) DO NOTHING RETURNING group_id")
 
-	err = insertGroup.RunWith(tx).QueryRowContext(ctxWithTimeout).Scan(&groupID)
+	err := insertGroup.RunWith(tx).QueryRowContext(ctx).Scan(&groupID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			err = psql.Select("group_id").From("groups").
@@ -271,11 +515,16 @@ func (q *Queries) AddSong(ctx context.Context, request AddSongRequest) (*AddSong
 		}
 	}
 
-	insertSong := psql.Insert("songs").Columns("group_id", "song", "releaseDate", "link").
-		Values(groupID, request.SongName, request.ReleaseDate, request.Link).
-		Suffix("RETURNING song_id")
+	var precision *string
+	if request.ReleaseDate != nil {
+		precision = &request.ReleaseDatePrecision
+	}
+
+	insertSong := psql.Insert("songs").Columns("group_id", "song", "releaseDate", "release_date_precision", "link").
+		Values(groupID, request.SongName, request.ReleaseDate, precision, request.Link).
+		Suffix("RETURNING song_id, created_at")
 
-	err = insertSong.RunWith(tx).QueryRowContext(ctx).Scan(&songID)
+	err = insertSong.RunWith(tx).QueryRowContext(ctx).Scan(&response.SongID, &response.CreatedAt)
 	if err != nil {
 		if q.debug {
 			q.log.Error("database.AddSong | insertSong.QueryRowContext", "error", err.Error())
@@ -291,34 +540,40 @@ func (q *Queries) AddSong(ctx context.Context, request AddSongRequest) (*AddSong
 
 	insertVerses := psql.Insert("verses").Columns("song_id", "verse_number", "verse_text")
 	for _, verse := range request.Verses {
-		insertVerses = insertVerses.Values(songID, verse.VerseNumber, verse.VerseText)
+		insertVerses = insertVerses.Values(response.SongID, verse.VerseNumber, verse.VerseText)
 	}
-	_, err = insertVerses.RunWith(tx).ExecContext(ctxWithTimeout)
+	_, err = insertVerses.RunWith(tx).ExecContext(ctx)
 	if err != nil {
 		if q.debug {
 			q.log.Error("database.AddSong | insertVerses.ExecContext", "error", err.Error())
 		}
 		return nil, err
 	}
-	if err := tx.Commit(); err != nil {
-		if q.debug {
-			q.log.Error("database.AddSong | Commit", "error", err.Error())
-		}
+
+	if err := q.addOutbox(ctx, tx, EventSongCreated, int(response.SongID), nil); err != nil {
 		return nil, err
 	}
-	return &AddSongResponse{SongID: songID}, nil
+	return &response, nil
 }
 
 type UpdateSongRequest struct {
-	SongID      int        `json:"song_id"`
-	GroupID     *int64     `json:"group_id"`
-	SongName    *string    `json:"song_name,omitempty"`
-	ReleaseDate *time.Time `json:"release_date,omitempty"`
-	Link        *string    `json:"link,omitempty"`
+	SongID               int        `json:"song_id"`
+	GroupID              *int64     `json:"group_id"`
+	SongName             *string    `json:"song_name,omitempty"`
+	ReleaseDate          *time.Time `json:"release_date,omitempty"`
+	ReleaseDatePrecision *string    `json:"release_date_precision,omitempty"`
+	Link                 *string    `json:"link,omitempty"`
+	Version              *int       `json:"version,omitempty"`
 }
 
-func (q *Queries) UpdateSong(ctx context.Context, request UpdateSongRequest) error {
-	sqlQury := sq.Update("songs").PlaceholderFormat(sq.Dollar)
+// UpdateSong changes song fields and increments its version, returns updated song
+// read in the same transaction. When Version is set and differs from stored one
+// ErrVersionMismatch is returned
+func (q *Queries) UpdateSong(ctx context.Context, request UpdateSongRequest) (*Song, error) {
+	sqlQury := sq.Update("songs").
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("now()")).
+		PlaceholderFormat(sq.Dollar)
 
 	if request.GroupID != nil {
 		sqlQury = sqlQury.Set("group_id", *request.GroupID)
@@ -332,106 +587,255 @@ func (q *Queries) UpdateSong(ctx context.Context, request UpdateSongRequest) err
 		sqlQury = sqlQury.Set("releaseDate", *request.ReleaseDate)
 	}
 
+	if request.ReleaseDatePrecision != nil {
+		sqlQury = sqlQury.Set("release_date_precision", *request.ReleaseDatePrecision)
+	}
+
 	if request.Link != nil {
 		sqlQury = sqlQury.Set("link", *request.Link)
 	}
 
-	result, err := sqlQury.Where(sq.Eq{"song_id": request.SongID}).
-		RunWith(q.db).ExecContext(ctx)
+	where := sq.Eq{"song_id": request.SongID}
+	if request.Version != nil {
+		where["version"] = *request.Version
+	}
+
+	tx, err := q.begin(ctx)
 	if err != nil {
 		if q.debug {
-			q.log.Error("database.UpdateSong | ExecContext", "error", err.Error())
+			q.log.Error("database.UpdateSong | BeginTx", "error", err.Error())
 		}
-		return err
+		return nil, err
 	}
-	affected, err := result.RowsAffected()
+	defer tx.Rollback() //nolint:errcheck
+
+	err = sqlQury.Where(where).Suffix("RETURNING song_id").
+		RunWith(tx).QueryRowContext(ctx).Scan(new(int))
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, q.versionMismatch(ctx, "database.UpdateSong", sq.Select("1").From("songs").
+				Where(sq.Eq{"song_id": request.SongID}), request.SongID)
+		}
 		if q.debug {
-			q.log.Error("database.UpdateSong | RowsAffected", "error", err.Error())
+			q.log.Error("database.UpdateSong | QueryRowContext", "error", err.Error())
 		}
-		return err
+		return nil, err
 	}
 
-	if affected == 0 {
+	var song Song
+	err = sq.Select(songColumns...).
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(sq.Eq{"song_id": request.SongID}).
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).QueryRowContext(ctx).Scan(songDest(&song)...)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.UpdateSong | getSong.QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	if err := q.addOutbox(ctx, tx, EventSongUpdated, request.SongID, nil); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
 		if q.debug {
-			q.log.Warn("database.UpdateSong | RowsAffected 0",
+			q.log.Error("database.UpdateSong | Commit", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	return &song, nil
+}
+
+// versionMismatch is called when conditional update changed nothing,
+// returns ErrVersionMismatch when row exists and sql.ErrNoRows otherwise
+func (q *Queries) versionMismatch(ctx context.Context, method string, exists sq.SelectBuilder, songID int) error {
+	var one int
+	err := exists.PlaceholderFormat(sq.Dollar).RunWith(q.db).QueryRowContext(ctx).Scan(&one)
+	switch {
+	case err == nil:
+		if q.debug {
+			q.log.Warn(method+" | version mismatch", "song_id", songID)
+		}
+		return ErrVersionMismatch
+	case errors.Is(err, sql.ErrNoRows):
+		if q.debug {
+			q.log.Warn(method+" | RowsAffected 0",
 				"error", sql.ErrNoRows.Error(),
-				"song_id", request.SongID)
+				"song_id", songID)
 		}
 		return sql.ErrNoRows
+	default:
+		if q.debug {
+			q.log.Error(method+" | exists.QueryRowContext", "error", err.Error())
+		}
+		return err
 	}
+}
 
-	return nil
+func (q *Queries) GetVerse(ctx context.Context, SongID int, verseNumber int) (*VerseSmall, error) {
+	sqlQuery := sq.Select("verse_number", "verse_text", "version", "created_at", "updated_at").
+		From("verses").
+		Where(sq.Eq{
+			"song_id":      SongID,
+			"verse_number": verseNumber,
+		}).PlaceholderFormat(sq.Dollar)
 
+	var i VerseSmall
+	if err := sqlQuery.RunWith(q.db).QueryRowContext(ctx).Scan(&i.VerseNumber, &i.VerseText, &i.Version, &i.CreatedAt, &i.UpdatedAt); err != nil {
+		if q.debug {
+			q.log.Error("database.GetVerse | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return &i, nil
 }
 
 type UpdateVerseRequest struct {
 	SongID      int    `json:"song_id"`
 	VerseNumber int    `json:"verse_number"`
 	VerseText   string `json:"verse_text"`
+	Version     *int   `json:"version,omitempty"`
+	SongVersion *int   `json:"song_version,omitempty"`
 }
 
-func (q *Queries) UpdateVerse(ctx context.Context, request UpdateVerseRequest) error {
-	sqlQuery := sq.Update("verses").
-		Set("verse_text", request.VerseText).
-		Where(sq.Eq{
-			"song_id":      request.SongID,
-			"verse_number": request.VerseNumber,
-		}).PlaceholderFormat(sq.Dollar)
+type UpdateVerseResponse struct {
+	Verse       VerseSmall `json:"verse"`
+	SongVersion int        `json:"song_version"`
+}
 
-	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+// UpdateVerse changes verse text and increments versions of verse and song,
+// returns updated verse and new song version. When Version is set and differs from stored
+// verse version ErrVersionMismatch is returned, when SongVersion differs from stored song
+// version ErrSongVersionMismatch is returned
+func (q *Queries) UpdateVerse(ctx context.Context, request UpdateVerseRequest) (*UpdateVerseResponse, error) {
+	tx, err := q.begin(ctx)
 	if err != nil {
 		if q.debug {
-			q.log.Error("database.UpdateVerse | ExecContext", "error", err.Error())
+			q.log.Error("database.UpdateVerse | BeginTx", "error", err.Error())
 		}
-		return err
+		return nil, err
 	}
+	defer tx.Rollback() //nolint:errcheck
 
-	affected, err := result.RowsAffected()
+	//song is locked before verse like in other song changes
+	songWhere := sq.Eq{"song_id": request.SongID}
+	if request.SongVersion != nil {
+		songWhere["version"] = *request.SongVersion
+	}
+
+	var result UpdateVerseResponse
+	err = sq.Update("songs").
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(songWhere).
+		Suffix("RETURNING version").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).QueryRowContext(ctx).
+		Scan(&result.SongVersion)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = q.versionMismatch(ctx, "database.UpdateVerse", sq.Select("1").From("songs").
+				Where(sq.Eq{"song_id": request.SongID}), request.SongID)
+			if errors.Is(err, ErrVersionMismatch) {
+				return nil, ErrSongVersionMismatch
+			}
+			return nil, err
+		}
 		if q.debug {
-			q.log.Error("database.UpdateVerse | RowsAffected", "error", err.Error())
+			q.log.Error("database.UpdateVerse | updateSong.QueryRowContext", "error", err.Error())
 		}
-		return err
+		return nil, err
+	}
+
+	where := sq.Eq{
+		"song_id":      request.SongID,
+		"verse_number": request.VerseNumber,
+	}
+	if request.Version != nil {
+		where["version"] = *request.Version
+	}
+
+	var verse VerseSmall
+	err = sq.Update("verses").
+		Set("verse_text", request.VerseText).
+		Set("version", sq.Expr("version + 1")).
+		Set("updated_at", sq.Expr("now()")).
+		Where(where).
+		Suffix("RETURNING verse_number, verse_text, version, created_at, updated_at").
+		PlaceholderFormat(sq.Dollar).
+		RunWith(tx).QueryRowContext(ctx).
+		Scan(&verse.VerseNumber, &verse.VerseText, &verse.Version, &verse.CreatedAt, &verse.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, q.versionMismatch(ctx, "database.UpdateVerse", sq.Select("1").From("verses").
+				Where(sq.Eq{
+					"song_id":      request.SongID,
+					"verse_number": request.VerseNumber,
+				}), request.SongID)
+		}
+		if q.debug {
+			q.log.Error("database.UpdateVerse | updateVerse.QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+
+	if err := q.addOutbox(ctx, tx, EventVerseUpdated, request.SongID, &verse); err != nil {
+		return nil, err
 	}
 
-	if affected == 0 {
+	if err := tx.Commit(); err != nil {
 		if q.debug {
-			q.log.Warn("database.UpdateVerse | RowsAffected 0",
-				"error", sql.ErrNoRows.Error(),
-				"song_id", request.SongID)
+			q.log.Error("database.UpdateVerse | Commit", "error", err.Error())
 		}
-		return sql.ErrNoRows
+		return nil, err
 	}
 
-	return nil
+	result.Verse = verse
+	return &result, nil
 }
 
 func (q *Queries) DeleteSong(ctx context.Context, SongID int) error {
-	sqlQuery := sq.Delete("songs").Where(sq.Eq{"song_id": SongID}).PlaceholderFormat(sq.Dollar)
-
-	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	tx, err := q.begin(ctx)
 	if err != nil {
 		if q.debug {
-			q.log.Error("database.DeleteSong | ExecContext", "error", err.Error())
+			q.log.Error("database.DeleteSong | BeginTx", "error", err.Error())
 		}
 		return err
 	}
-	affected, err := result.RowsAffected()
-	if err != nil {
+	defer tx.Rollback() //nolint:errcheck
+
+	//deleted row is returned with group name, event keeps song as it was before deletion
+	sqlQuery := sq.Delete("songs USING groups").
+		Where("songs.group_id = groups.group_id").
+		Where(sq.Eq{"song_id": SongID}).
+		Suffix("RETURNING " + strings.Join(songColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	var song Song
+	if err := sqlQuery.RunWith(tx).QueryRowContext(ctx).Scan(songDest(&song)...); err != nil {
 		if q.debug {
-			q.log.Error("database.DeleteSong | RowsAffected", "error", err.Error())
+			if errors.Is(err, sql.ErrNoRows) {
+				q.log.Warn("database.DeleteSong | QueryRowContext", "error", err.Error(), "song_id", SongID)
+			} else {
+				q.log.Error("database.DeleteSong | QueryRowContext", "error", err.Error())
+			}
 		}
 		return err
 	}
 
-	if affected == 0 {
+	if err := q.insertOutbox(ctx, tx, EventSongDeleted, song, nil); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
 		if q.debug {
-			q.log.Warn("database.DeleteSong | RowsAffected 0",
-				"error", sql.ErrNoRows.Error(),
-				"song_id", SongID)
+			q.log.Error("database.DeleteSong | Commit", "error", err.Error())
 		}
-		return sql.ErrNoRows
+		return err
 	}
 	return nil
 }
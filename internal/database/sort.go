@@ -0,0 +1,153 @@
+package database
+
+import (
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+const (
+	DefaultSongsLimit = 10
+	MaxSongsLimit     = 100
+)
+
+// SortKey is one ordering key of song list
+type SortKey struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// nullReleaseDate replaces missing release date in ordering
+const nullReleaseDate = "0001-01-01"
+
+// songSortColumns maps whitelisted sort fields to song list expressions,
+// null release dates are sorted as the earliest date so keyset paging can compare them
+var songSortColumns = map[string]string{
+	"id":          "song_id",
+	"group":       "name",
+	"song":        "song",
+	"releaseDate": "COALESCE(releaseDate, DATE '" + nullReleaseDate + "')",
+	"createdAt":   "songs.created_at",
+	"updatedAt":   "songs.updated_at",
+}
+
+// DefaultSongSort keeps historical newest first order
+var DefaultSongSort = []SortKey{{Field: "releaseDate", Desc: true}}
+
+// IsSongSortField reports whether song list can be sorted by field
+func IsSongSortField(field string) bool {
+	_, ok := songSortColumns[field]
+	return ok
+}
+
+// SongsLimit returns page size used by GetSongs for requested limit
+func SongsLimit(limit uint64) uint64 {
+	if limit > 0 && limit <= MaxSongsLimit {
+		return limit
+	}
+	return DefaultSongsLimit
+}
+
+// SongCursor points after the last song of previous page,
+// Values hold sort key values of that song in Sort order
+type SongCursor struct {
+	Values []any `json:"values"`
+	SongID int   `json:"song_id"`
+}
+
+// CursorAfter returns cursor pointing after song in list ordered by sort
+func CursorAfter(song Song, sort []SortKey) SongCursor {
+	cursor := SongCursor{SongID: song.SongID}
+	for _, key := range sort {
+		cursor.Values = append(cursor.Values, sortValue(song, key.Field))
+	}
+	return cursor
+}
+
+// sortValue returns song value of sort field comparable with its column
+func sortValue(song Song, field string) any {
+	switch field {
+	case "id":
+		return song.SongID
+	case "group":
+		return song.GroupName
+	case "song":
+		return song.SongName
+	case "releaseDate":
+		if song.ReleaseDate == nil {
+			return nullReleaseDate
+		}
+		return song.ReleaseDate.Format(time.DateOnly)
+	case "createdAt":
+		return song.CreatedAt.Format(time.RFC3339Nano)
+	case "updatedAt":
+		return song.UpdatedAt.Format(time.RFC3339Nano)
+	default:
+		return nil
+	}
+}
+
+type sortColumn struct {
+	expr string
+	desc bool
+}
+
+// songSortColumnsFor returns order columns ending with song_id tiebreaker
+func songSortColumnsFor(sort []SortKey) []sortColumn {
+	if len(sort) == 0 {
+		sort = DefaultSongSort
+	}
+
+	columns := make([]sortColumn, 0, len(sort)+1)
+	hasID := false
+	for _, key := range sort {
+		expr, ok := songSortColumns[key.Field]
+		if !ok {
+			continue
+		}
+		hasID = hasID || key.Field == "id"
+		columns = append(columns, sortColumn{expr: expr, desc: key.Desc})
+	}
+	if !hasID {
+		columns = append(columns, sortColumn{expr: "song_id"})
+	}
+	return columns
+}
+
+func songOrderBy(columns []sortColumn) []string {
+	orderBy := make([]string, 0, len(columns))
+	for _, column := range columns {
+		if column.desc {
+			orderBy = append(orderBy, column.expr+" DESC")
+		} else {
+			orderBy = append(orderBy, column.expr)
+		}
+	}
+	return orderBy
+}
+
+// songsAfter builds keyset condition selecting rows ordered after cursor
+func songsAfter(columns []sortColumn, cursor SongCursor) sq.Sqlizer {
+	values := append([]any{}, cursor.Values...)
+	if len(values) < len(columns) {
+		values = append(values, cursor.SongID)
+	}
+
+	after := sq.Or{}
+	for i, column := range columns {
+		if i >= len(values) {
+			break
+		}
+		cond := sq.And{}
+		for j := 0; j < i; j++ {
+			cond = append(cond, sq.Expr(columns[j].expr+" = ?", values[j]))
+		}
+		op := " > ?"
+		if column.desc {
+			op = " < ?"
+		}
+		cond = append(cond, sq.Expr(column.expr+op, values[i]))
+		after = append(after, cond)
+	}
+	return after
+}
@@ -0,0 +1,187 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+)
+
+type ListSongsForSyncRequest struct {
+	AfterID      int       `json:"after_id"`
+	SyncedBefore time.Time `json:"synced_before"`
+	Limit        uint64    `json:"limit"`
+}
+
+// ListSongsForSync returns songs with id greater than AfterID
+// that were never synced or synced before SyncedBefore
+func (q *Queries) ListSongsForSync(ctx context.Context, request ListSongsForSyncRequest) ([]Song, error) {
+	sqlQuery := sq.Select(songColumns...).
+		From("songs").
+		InnerJoin("groups USING(group_id)").
+		Where(sq.Gt{"song_id": request.AfterID}).
+		Where(sq.Or{
+			sq.Eq{"last_synced_at": nil},
+			sq.Lt{"last_synced_at": request.SyncedBefore},
+		}).
+		OrderBy("song_id").
+		Limit(request.Limit).
+		PlaceholderFormat(sq.Dollar)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.ListSongsForSync | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var songList []Song
+	for rows.Next() {
+		var i Song
+		if err := rows.Scan(songDest(&i)...); err != nil {
+			if q.debug {
+				q.log.Error("database.ListSongsForSync | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		songList = append(songList, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.ListSongsForSync | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+	return songList, nil
+}
+
+// GetAllVerses returns every verse of the song ordered by number
+func (q *Queries) GetAllVerses(ctx context.Context, SongID int) ([]VerseSmall, error) {
+	sqlQuery := sq.Select("verse_number", "verse_text", "version").
+		From("verses").
+		Where(sq.Eq{"song_id": SongID}).
+		OrderBy("verse_number").PlaceholderFormat(sq.Dollar)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetAllVerses | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var verses []VerseSmall
+	for rows.Next() {
+		var i VerseSmall
+		if err := rows.Scan(&i.VerseNumber, &i.VerseText, &i.Version); err != nil {
+			if q.debug {
+				q.log.Error("database.GetAllVerses | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		verses = append(verses, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetAllVerses | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+	return verses, nil
+}
+
+type SyncSongRequest struct {
+	SongID               int          `json:"song_id"`
+	ReleaseDate          *time.Time   `json:"release_date,omitempty"`
+	ReleaseDatePrecision *string      `json:"release_date_precision,omitempty"`
+	Link                 *string      `json:"link,omitempty"`
+	Verses               []VerseSmall `json:"verses,omitempty"`
+}
+
+// SyncSong applies changed fields, replaces verses when Verses is not nil
+// and stores sync time in one transaction
+func (q *Queries) SyncSong(ctx context.Context, request SyncSongRequest) error {
+	tx, err := q.begin(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.SyncSong | BeginTx", "error", err.Error())
+		}
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	psql := sq.StatementBuilder.PlaceholderFormat(sq.Dollar)
+
+	updateSong := psql.Update("songs").
+		Set("last_synced_at", sq.Expr("now()")).
+		Where(sq.Eq{"song_id": request.SongID})
+
+	if request.ReleaseDate != nil {
+		updateSong = updateSong.Set("releaseDate", *request.ReleaseDate)
+	}
+
+	if request.ReleaseDatePrecision != nil {
+		updateSong = updateSong.Set("release_date_precision", *request.ReleaseDatePrecision)
+	}
+
+	if request.Link != nil {
+		updateSong = updateSong.Set("link", *request.Link)
+	}
+
+	changed := request.ReleaseDate != nil || request.ReleaseDatePrecision != nil || request.Link != nil || request.Verses != nil
+	if changed {
+		updateSong = updateSong.Set("version", sq.Expr("version + 1")).
+			Set("updated_at", sq.Expr("now()"))
+	}
+
+	if _, err := updateSong.RunWith(tx).ExecContext(ctx); err != nil {
+		if q.debug {
+			q.log.Error("database.SyncSong | updateSong.ExecContext", "error", err.Error())
+		}
+		return err
+	}
+
+	if request.Verses != nil {
+		_, err := psql.Delete("verses").
+			Where(sq.Eq{"song_id": request.SongID}).
+			RunWith(tx).ExecContext(ctx)
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.SyncSong | deleteVerses.ExecContext", "error", err.Error())
+			}
+			return err
+		}
+
+		if len(request.Verses) > 0 {
+			insertVerses := psql.Insert("verses").Columns("song_id", "verse_number", "verse_text")
+			for _, verse := range request.Verses {
+				insertVerses = insertVerses.Values(request.SongID, verse.VerseNumber, verse.VerseText)
+			}
+			if _, err := insertVerses.RunWith(tx).ExecContext(ctx); err != nil {
+				if q.debug {
+					q.log.Error("database.SyncSong | insertVerses.ExecContext", "error", err.Error())
+				}
+				return err
+			}
+		}
+	}
+
+	if changed {
+		if err := q.addOutbox(ctx, tx, EventSongUpdated, request.SongID, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		if q.debug {
+			q.log.Error("database.SyncSong | Commit", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
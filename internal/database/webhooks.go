@@ -0,0 +1,420 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/lib/pq"
+)
+
+const (
+	DeliveryStatusPending   = "pending"
+	DeliveryStatusRunning   = "running"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusFailed    = "failed"
+)
+
+var webhookColumns = []string{"webhook_id", "url", "secret", "event_types", "active", "created_at"}
+
+var deliveryColumns = []string{"delivery_id", "webhook_id", "event_type", "payload", "status", "attempts",
+	"next_attempt_at", "response_status", "error", "replay_of", "created_at", "updated_at", "delivered_at"}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret"`
+	EventTypes []string `json:"event_types"`
+}
+
+func (q *Queries) CreateWebhook(ctx context.Context, request CreateWebhookRequest) (*Webhook, error) {
+	eventTypes := request.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	sqlQuery := sq.Insert("webhooks").Columns("url", "secret", "event_types").
+		Values(request.URL, request.Secret, pq.Array(eventTypes)).
+		Suffix("RETURNING " + strings.Join(webhookColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	webhook, err := scanWebhook(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.CreateWebhook | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (q *Queries) GetWebhook(ctx context.Context, webhookID int) (*Webhook, error) {
+	sqlQuery := sq.Select(webhookColumns...).
+		From("webhooks").
+		Where(sq.Eq{"webhook_id": webhookID}).
+		PlaceholderFormat(sq.Dollar)
+
+	webhook, err := scanWebhook(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetWebhook | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (q *Queries) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	sqlQuery := sq.Select(webhookColumns...).
+		From("webhooks").
+		OrderBy("webhook_id").
+		PlaceholderFormat(sq.Dollar)
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetWebhooks | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.GetWebhooks | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetWebhooks | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook deletes subscription with its delivery log
+func (q *Queries) DeleteWebhook(ctx context.Context, webhookID int) error {
+	sqlQuery := sq.Delete("webhooks").
+		Where(sq.Eq{"webhook_id": webhookID}).
+		PlaceholderFormat(sq.Dollar)
+
+	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteWebhook | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.DeleteWebhook | RowsAffected", "error", err.Error())
+		}
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+type EnqueueWebhookDeliveriesRequest struct {
+	OutboxID  int64  `json:"outbox_id"`
+	EventType string `json:"event_type"`
+	Payload   []byte `json:"payload"`
+}
+
+// EnqueueWebhookDeliveries creates pending delivery of event for every active
+// webhook subscribed to its type, webhooks without event types get all events.
+// Deliveries of the same outbox event are created once
+func (q *Queries) EnqueueWebhookDeliveries(ctx context.Context, request EnqueueWebhookDeliveriesRequest) (int64, error) {
+	subscribers := sq.Select("webhook_id").
+		Column("?::bigint", request.OutboxID).
+		Column("?", request.EventType).
+		Column("?::jsonb", request.Payload).
+		From("webhooks").
+		Where(sq.Eq{"active": true}).
+		Where(sq.Or{
+			sq.Expr("cardinality(event_types) = 0"),
+			sq.Expr("? = ANY(event_types)", request.EventType),
+		})
+
+	sqlQuery := sq.Insert("webhook_deliveries").
+		Columns("webhook_id", "outbox_id", "event_type", "payload").
+		Select(subscribers).
+		Suffix("ON CONFLICT (webhook_id, outbox_id) WHERE replay_of IS NULL DO NOTHING").
+		PlaceholderFormat(sq.Dollar)
+
+	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.EnqueueWebhookDeliveries | ExecContext", "error", err.Error())
+		}
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// ClaimWebhookDelivery marks the oldest due pending delivery as running and leases it for lease.
+// Running deliveries whose lease expired (worker died) are claimed again.
+// Returns sql.ErrNoRows when there is nothing to send
+func (q *Queries) ClaimWebhookDelivery(ctx context.Context, lease time.Duration) (*WebhookDelivery, error) {
+	pending := sq.Select("delivery_id").
+		From("webhook_deliveries").
+		Where(sq.Or{
+			sq.And{
+				sq.Eq{"status": DeliveryStatusPending},
+				sq.Expr("next_attempt_at <= now()"),
+			},
+			sq.And{
+				sq.Eq{"status": DeliveryStatusRunning},
+				sq.Expr("(locked_until IS NULL OR locked_until < now())"),
+			},
+		}).
+		OrderBy("next_attempt_at", "delivery_id").
+		Limit(1).
+		Suffix("FOR UPDATE SKIP LOCKED")
+
+	sqlQuery := sq.Update("webhook_deliveries").
+		Set("status", DeliveryStatusRunning).
+		Set("locked_until", sq.Expr("now() + make_interval(secs => ?)", lease.Seconds())).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Expr("delivery_id = (?)", pending)).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	delivery, err := scanDelivery(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug && !errors.Is(err, sql.ErrNoRows) {
+			q.log.Error("database.ClaimWebhookDelivery | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+type FinishWebhookDeliveryRequest struct {
+	DeliveryID     int64      `json:"delivery_id"`
+	Status         string     `json:"status"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	Error          *string    `json:"error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+}
+
+// FinishWebhookDelivery records result of one delivery attempt,
+// pending status with NextAttemptAt schedules a retry
+func (q *Queries) FinishWebhookDelivery(ctx context.Context, request FinishWebhookDeliveryRequest) error {
+	sqlQuery := sq.Update("webhook_deliveries").
+		Set("status", request.Status).
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("response_status", request.ResponseStatus).
+		Set("error", request.Error).
+		Set("locked_until", nil).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"delivery_id": request.DeliveryID, "status": DeliveryStatusRunning}).
+		PlaceholderFormat(sq.Dollar)
+
+	if request.NextAttemptAt != nil {
+		sqlQuery = sqlQuery.Set("next_attempt_at", *request.NextAttemptAt)
+	}
+	if request.Status == DeliveryStatusDelivered {
+		sqlQuery = sqlQuery.Set("delivered_at", sq.Expr("now()"))
+	}
+
+	result, err := sqlQuery.RunWith(q.db).ExecContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.FinishWebhookDelivery | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.FinishWebhookDelivery | RowsAffected", "error", err.Error())
+		}
+		return err
+	}
+
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ReleaseWebhookDelivery returns running delivery to the queue without waiting for its lease to expire
+func (q *Queries) ReleaseWebhookDelivery(ctx context.Context, deliveryID int64) error {
+	sqlQuery := sq.Update("webhook_deliveries").
+		Set("status", DeliveryStatusPending).
+		Set("locked_until", nil).
+		Set("updated_at", sq.Expr("now()")).
+		Where(sq.Eq{"delivery_id": deliveryID, "status": DeliveryStatusRunning}).
+		PlaceholderFormat(sq.Dollar)
+
+	if _, err := sqlQuery.RunWith(q.db).ExecContext(ctx); err != nil {
+		if q.debug {
+			q.log.Error("database.ReleaseWebhookDelivery | ExecContext", "error", err.Error())
+		}
+		return err
+	}
+	return nil
+}
+
+type GetWebhookDeliveriesRequest struct {
+	WebhookID int     `json:"webhook_id"`
+	Status    *string `json:"status,omitempty"`
+	Limit     uint64  `json:"limit"`
+	Offset    uint64  `json:"offset"`
+}
+
+// GetWebhookDeliveries returns delivery log of webhook, newest first
+func (q *Queries) GetWebhookDeliveries(ctx context.Context, request GetWebhookDeliveriesRequest) ([]WebhookDelivery, error) {
+	sqlQuery := sq.Select(deliveryColumns...).
+		From("webhook_deliveries").
+		Where(sq.Eq{"webhook_id": request.WebhookID}).
+		OrderBy("delivery_id DESC").
+		Limit(SongsLimit(request.Limit)).
+		PlaceholderFormat(sq.Dollar)
+
+	if request.Status != nil {
+		sqlQuery = sqlQuery.Where(sq.Eq{"status": *request.Status})
+	}
+
+	if request.Offset > 0 {
+		sqlQuery = sqlQuery.Offset(request.Offset)
+	}
+
+	rows, err := sqlQuery.RunWith(q.db).QueryContext(ctx)
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetWebhookDeliveries | QueryContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+		if err != nil {
+			if q.debug {
+				q.log.Error("database.GetWebhookDeliveries | row.Scan", "error", err.Error())
+			}
+			return nil, err
+		}
+		deliveries = append(deliveries, *delivery)
+	}
+
+	if err := rows.Err(); err != nil {
+		if q.debug {
+			q.log.Error("database.GetWebhookDeliveries | rows.Err", "error", err.Error())
+		}
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (q *Queries) GetWebhookDelivery(ctx context.Context, deliveryID int64) (*WebhookDelivery, error) {
+	sqlQuery := sq.Select(deliveryColumns...).
+		From("webhook_deliveries").
+		Where(sq.Eq{"delivery_id": deliveryID}).
+		PlaceholderFormat(sq.Dollar)
+
+	delivery, err := scanDelivery(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.GetWebhookDelivery | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// ReplayWebhookDelivery queues a copy of delivery, original one stays in the log
+func (q *Queries) ReplayWebhookDelivery(ctx context.Context, deliveryID int64) (*WebhookDelivery, error) {
+	original := sq.Select("webhook_id", "event_type", "payload", "delivery_id").
+		From("webhook_deliveries").
+		Where(sq.Eq{"delivery_id": deliveryID})
+
+	sqlQuery := sq.Insert("webhook_deliveries").
+		Columns("webhook_id", "event_type", "payload", "replay_of").
+		Select(original).
+		Suffix("RETURNING " + strings.Join(deliveryColumns, ", ")).
+		PlaceholderFormat(sq.Dollar)
+
+	delivery, err := scanDelivery(sqlQuery.RunWith(q.db).QueryRowContext(ctx))
+	if err != nil {
+		if q.debug {
+			q.log.Error("database.ReplayWebhookDelivery | QueryRowContext", "error", err.Error())
+		}
+		return nil, err
+	}
+	return delivery, nil
+}
+
+func scanWebhook(row sq.RowScanner) (*Webhook, error) {
+	var i Webhook
+	if err := row.Scan(
+		&i.WebhookID,
+		&i.URL,
+		&i.Secret,
+		pq.Array(&i.EventTypes),
+		&i.Active,
+		&i.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func scanDelivery(row sq.RowScanner) (*WebhookDelivery, error) {
+	var i WebhookDelivery
+	var responseStatus sql.NullInt64
+	var deliveryErr sql.NullString
+	var replayOf sql.NullInt64
+	var deliveredAt sql.NullTime
+	if err := row.Scan(
+		&i.DeliveryID,
+		&i.WebhookID,
+		&i.EventType,
+		&i.Payload,
+		&i.Status,
+		&i.Attempts,
+		&i.NextAttemptAt,
+		&responseStatus,
+		&deliveryErr,
+		&replayOf,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&deliveredAt,
+	); err != nil {
+		return nil, err
+	}
+	if responseStatus.Valid {
+		status := int(responseStatus.Int64)
+		i.ResponseStatus = &status
+	}
+	if deliveryErr.Valid {
+		i.Error = &deliveryErr.String
+	}
+	if replayOf.Valid {
+		i.ReplayOf = &replayOf.Int64
+	}
+	if deliveredAt.Valid {
+		i.DeliveredAt = &deliveredAt.Time
+	}
+	return &i, nil
+}
@@ -0,0 +1,137 @@
+package dateparse
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Precision shows which part of the date is known
+type Precision string
+
+const (
+	PrecisionDay   Precision = "day"
+	PrecisionMonth Precision = "month"
+	PrecisionYear  Precision = "year"
+)
+
+var (
+	ErrEmpty     = fmt.Errorf("empty date")
+	ErrBadFormat = fmt.Errorf("unknown date format")
+	ErrPrecision = fmt.Errorf("unknown date precision")
+)
+
+// layouts are checked in order, more precise first
+var layouts = []struct {
+	precision Precision
+	layouts   []string
+}{
+	{PrecisionDay, []string{
+		"02.01.2006", "2.1.2006", "2006-01-02", time.RFC3339, "02/01/2006",
+		"2 January 2006", "January 2, 2006", "2 Jan 2006", "Jan 2, 2006",
+	}},
+	{PrecisionMonth, []string{"01.2006", "2006-01", "01/2006", "January 2006", "Jan 2006"}},
+	{PrecisionYear, []string{"2006"}},
+}
+
+// Date is parsed date, Time is truncated to the first day of month or year
+// for month and year precision
+type Date struct {
+	Time      time.Time `json:"time"`
+	Precision Precision `json:"precision"`
+}
+
+// Parse accepts "02.01.2006", ISO dates and timestamps, "2006-01", "July 2006", "2006" and some similar formats.
+// Empty value returns ErrEmpty
+func Parse(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Date{}, ErrEmpty
+	}
+
+	for _, group := range layouts {
+		for _, layout := range group.layouts {
+			t, err := time.Parse(layout, value)
+			if err != nil {
+				continue
+			}
+			y, m, d := t.Date()
+			return Date{
+				Time:      time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+				Precision: group.precision,
+			}, nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("%w: %q", ErrBadFormat, value)
+}
+
+// isoLayouts are ISO 8601 calendar dates with reduced precision
+var isoLayouts = []struct {
+	precision Precision
+	layout    string
+}{
+	{PrecisionDay, "2006-01-02"},
+	{PrecisionMonth, "2006-01"},
+	{PrecisionYear, "2006"},
+}
+
+// ParseISO accepts only ISO 8601 dates "2006-01-02", "2006-01" and "2006"
+func ParseISO(value string) (Date, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Date{}, ErrEmpty
+	}
+
+	for _, iso := range isoLayouts {
+		if t, err := time.Parse(iso.layout, value); err == nil {
+			return Date{Time: t, Precision: iso.precision}, nil
+		}
+	}
+
+	return Date{}, fmt.Errorf("%w: %q", ErrBadFormat, value)
+}
+
+// ParsePrecision checks stored precision value
+func ParsePrecision(value string) (Precision, error) {
+	switch p := Precision(value); p {
+	case PrecisionDay, PrecisionMonth, PrecisionYear:
+		return p, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrPrecision, value)
+	}
+}
+
+// Format prints date according to precision: "02.01.2006", "01.2006" or "2006"
+func (d Date) Format() string {
+	switch d.Precision {
+	case PrecisionYear:
+		return d.Time.Format("2006")
+	case PrecisionMonth:
+		return d.Time.Format("01.2006")
+	default:
+		return d.Time.Format("02.01.2006")
+	}
+}
+
+// End returns start of the period following the date, for "2006" it is 01.01.2007
+func (d Date) End() time.Time {
+	switch d.Precision {
+	case PrecisionYear:
+		return d.Time.AddDate(1, 0, 0)
+	case PrecisionMonth:
+		return d.Time.AddDate(0, 1, 0)
+	default:
+		return d.Time.AddDate(0, 0, 1)
+	}
+}
+
+// ISO prints date as ISO 8601 according to precision: "2006-01-02", "2006-01" or "2006"
+func (d Date) ISO() string {
+	for _, iso := range isoLayouts {
+		if iso.precision == d.Precision {
+			return d.Time.Format(iso.layout)
+		}
+	}
+	return d.Time.Format("2006-01-02")
+}
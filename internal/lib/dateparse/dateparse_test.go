@@ -0,0 +1,134 @@
+package dateparse_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+)
+
+func TestParsePrecision(t *testing.T) {
+	cases := []struct {
+		value     string
+		precision dateparse.Precision
+		date      time.Time
+	}{
+		{"16.07.2006", dateparse.PrecisionDay, time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{"6.7.2006", dateparse.PrecisionDay, time.Date(2006, 7, 6, 0, 0, 0, 0, time.UTC)},
+		{"2006-07-16", dateparse.PrecisionDay, time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{"2006-07-16T23:30:00+03:00", dateparse.PrecisionDay, time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{"16 July 2006", dateparse.PrecisionDay, time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{"Jul 16, 2006", dateparse.PrecisionDay, time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC)},
+		{"07.2006", dateparse.PrecisionMonth, time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"2006-07", dateparse.PrecisionMonth, time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"July 2006", dateparse.PrecisionMonth, time.Date(2006, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{" 2006 ", dateparse.PrecisionYear, time.Date(2006, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			date, err := dateparse.Parse(tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if date.Precision != tc.precision {
+				t.Errorf("precision = %q, want %q", date.Precision, tc.precision)
+			}
+			if !date.Time.Equal(tc.date) {
+				t.Errorf("time = %v, want %v", date.Time, tc.date)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []struct {
+		value string
+		err   error
+	}{
+		{"", dateparse.ErrEmpty},
+		{"   ", dateparse.ErrEmpty},
+		{"31.02.2006", dateparse.ErrBadFormat},
+		{"2006-13", dateparse.ErrBadFormat},
+		{"13.2006", dateparse.ErrBadFormat},
+		{"summer 2006", dateparse.ErrBadFormat},
+		{"06", dateparse.ErrBadFormat},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			if _, err := dateparse.Parse(tc.value); !errors.Is(err, tc.err) {
+				t.Errorf("err = %v, want %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestParseISO(t *testing.T) {
+	cases := []struct {
+		value     string
+		precision dateparse.Precision
+	}{
+		{"2006-07-16", dateparse.PrecisionDay},
+		{"2006-07", dateparse.PrecisionMonth},
+		{"2006", dateparse.PrecisionYear},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			date, err := dateparse.ParseISO(tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if date.Precision != tc.precision {
+				t.Errorf("precision = %q, want %q", date.Precision, tc.precision)
+			}
+			if date.ISO() != tc.value {
+				t.Errorf("ISO() = %q, want %q", date.ISO(), tc.value)
+			}
+		})
+	}
+
+	for _, value := range []string{"16.07.2006", "July 2006", "2006-07-16T00:00:00Z", "2006-02-30"} {
+		if _, err := dateparse.ParseISO(value); !errors.Is(err, dateparse.ErrBadFormat) {
+			t.Errorf("ParseISO(%q) err = %v, want ErrBadFormat", value, err)
+		}
+	}
+}
+
+func TestFormatAndEnd(t *testing.T) {
+	cases := []struct {
+		value  string
+		format string
+		end    time.Time
+	}{
+		{"16.07.2006", "16.07.2006", time.Date(2006, 7, 17, 0, 0, 0, 0, time.UTC)},
+		{"2006-12", "12.2006", time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"2006", "2006", time.Date(2007, 1, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			date, err := dateparse.Parse(tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if date.Format() != tc.format {
+				t.Errorf("Format() = %q, want %q", date.Format(), tc.format)
+			}
+			if !date.End().Equal(tc.end) {
+				t.Errorf("End() = %v, want %v", date.End(), tc.end)
+			}
+		})
+	}
+}
+
+func TestParseStoredPrecision(t *testing.T) {
+	if p, err := dateparse.ParsePrecision("month"); err != nil || p != dateparse.PrecisionMonth {
+		t.Errorf("ParsePrecision(month) = %q, %v", p, err)
+	}
+	if _, err := dateparse.ParsePrecision("week"); !errors.Is(err, dateparse.ErrPrecision) {
+		t.Errorf("ParsePrecision(week) err = %v, want ErrPrecision", err)
+	}
+}
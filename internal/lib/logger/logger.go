@@ -40,7 +40,7 @@ func (l *Logger) Error(msg string, args ...interface{}) {
 }
 
 func (l *Logger) Fatal(v ...any) {
-	log.Fatal(v)
+	log.Fatal(v...)
 }
 
 func (l *Logger) logMessage(level int, msg string, args ...interface{}) {
@@ -0,0 +1,120 @@
+package problem
+
+import (
+	"errors"
+)
+
+const (
+	ContentType = "application/problem+json"
+	typeBaseURI = "/problems/"
+)
+
+// Code is stable machine-readable error code
+type Code string
+
+const (
+	CodeBadRequest        Code = "bad_request"
+	CodeValidation        Code = "validation_failed"
+	CodeUnauthorized      Code = "unauthorized"
+	CodeForbidden         Code = "forbidden"
+	CodeNotFound          Code = "not_found"
+	CodeSongNotFound      Code = "song_not_found"
+	CodeGroupNotFound     Code = "group_not_found"
+	CodeJobNotFound       Code = "job_not_found"
+	CodeWebhookNotFound   Code = "webhook_not_found"
+	CodeDeliveryNotFound  Code = "delivery_not_found"
+	CodeDeliveryNotFailed Code = "delivery_not_failed"
+	CodeAPIKeyNotFound    Code = "api_key_not_found"
+	CodeIdempotencyReused Code = "idempotency_key_reused"
+	CodeIdempotencyBusy   Code = "idempotency_key_in_progress"
+	CodeNoSongs           Code = "no_songs"
+	CodeSongExists        Code = "song_exists"
+	CodeVersionMismatch   Code = "version_mismatch"
+	CodeBadReleaseDate    Code = "bad_release_date"
+	CodeProviderFailed    Code = "provider_failed"
+	CodeStorageFailed     Code = "storage_failed"
+	CodeTimeout           Code = "timeout"
+	CodeInternal          Code = "internal_error"
+)
+
+// FieldError describes problem with one request field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is application error with code, transports map code to their status.
+// errors.Is matches errors with the same code
+type Error struct {
+	Code    Code
+	Message string
+	Fields  []FieldError
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	var t *Error
+	if !errors.As(target, &t) {
+		return false
+	}
+	return t.Code == e.Code
+}
+
+// WithFields returns copy of error with field details
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = append(append([]FieldError{}, e.Fields...), fields...)
+	return &c
+}
+
+// Validation returns validation error for fields
+func Validation(fields ...FieldError) *Error {
+	return ErrValidation.WithFields(fields...)
+}
+
+var (
+	ErrBadRequest = New(CodeBadRequest, "bad request")
+	ErrValidation = New(CodeValidation, "request validation failed")
+	ErrNotFound   = New(CodeNotFound, "resource is not found")
+	ErrInternal   = New(CodeInternal, "internal server error")
+)
+
+// Problem is RFC 7807 problem details document
+type Problem struct {
+	Type     string       `json:"type" example:"/problems/song_not_found"`
+	Title    string       `json:"title" example:"song is not found"`
+	Status   int          `json:"status" example:"404"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty" example:"/api/v1/songs/1"`
+	Code     Code         `json:"code" example:"song_not_found"`
+	Errors   []FieldError `json:"errors,omitempty"`
+}
+
+// From builds problem document with http status from error, unknown errors become internal_error
+// without leaking details
+func From(err error, status int, instance string) Problem {
+	var appErr *Error
+	if !errors.As(err, &appErr) {
+		appErr = ErrInternal
+	}
+
+	p := Problem{
+		Type:     typeBaseURI + string(appErr.Code),
+		Title:    appErr.Message,
+		Status:   status,
+		Instance: instance,
+		Code:     appErr.Code,
+		Errors:   appErr.Fields,
+	}
+	if appErr != ErrInternal && err.Error() != appErr.Message {
+		p.Detail = err.Error()
+	}
+	return p
+}
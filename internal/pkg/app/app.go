@@ -1,15 +1,24 @@
 package app
 
 import (
+	"context"
 	"database/sql"
 	"fmt" //nolint:gci
+	"io"
+	"net"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Vic07Region/musicLibrary/docs"
 	"github.com/Vic07Region/musicLibrary/internal/app/endpoint"
+	"github.com/Vic07Region/musicLibrary/internal/app/graph"
+	"github.com/Vic07Region/musicLibrary/internal/app/grpcserver"
+	"github.com/Vic07Region/musicLibrary/internal/app/openapi"
+	"github.com/Vic07Region/musicLibrary/internal/connector/sink"
 	"github.com/Vic07Region/musicLibrary/internal/connector/songinfo"
+	"github.com/Vic07Region/musicLibrary/internal/connector/webhook"
 	"github.com/Vic07Region/musicLibrary/internal/database"
 	"github.com/Vic07Region/musicLibrary/internal/database/migrate"
 	"github.com/Vic07Region/musicLibrary/internal/lib/csmaker"
@@ -19,6 +28,7 @@ import (
 	"github.com/joho/godotenv"
 	swaggerfiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"google.golang.org/grpc"
 )
 
 const (
@@ -28,10 +38,32 @@ const (
 type App struct {
 	db  *sql.DB
 	dbq database.Storage
-	s   service.MusicService
+	s   *service.Service
 	e   *endpoint.Endpoint
 	l   *logger.Logger
 	gin *gin.Engine
+
+	grpc     *grpc.Server
+	grpcHost string
+
+	jobWorkers      int
+	jobPollInterval time.Duration
+
+	webhookWorkers      int
+	webhookPollInterval time.Duration
+	webhookMaxAttempts  int
+	webhookTimeout      time.Duration
+	webhookAllowedHosts []string
+
+	eventsRetention time.Duration
+
+	idempotencyTTL time.Duration
+
+	outboxSinks        []sink.Sink
+	outboxPollInterval time.Duration
+
+	syncInterval time.Duration
+	syncRequest  service.SyncSongsRequest
 }
 
 func New() (*App, error) {
@@ -65,7 +97,6 @@ func New() (*App, error) {
 
 	dbdriver := os.Getenv("DB_DRIVER")
 	migrationsDIRS := os.Getenv("MIGRATION_DIRS")
-	apiBaseurl := os.Getenv("API_BASEURL")
 
 	maxConnEnv := os.Getenv("DB_MAX_CONN")
 	macIdleEnv := os.Getenv("DB_MAX_IDLE")
@@ -102,6 +133,117 @@ func New() (*App, error) {
 		maxLifetime = 0
 	}
 
+	a.jobWorkers = 4
+	if workersEnv := os.Getenv("JOB_WORKERS"); workersEnv != "" {
+		a.jobWorkers, err = strconv.Atoi(workersEnv)
+		if err != nil {
+			return nil, fmt.Errorf("JOB_WORKERS param wrong (INT)")
+		}
+	}
+
+	a.jobPollInterval = 5 * time.Second
+	if pollEnv := os.Getenv("JOB_POLL_INTERVAL"); pollEnv != "" {
+		tm, err := strconv.Atoi(pollEnv)
+		if err != nil {
+			return nil, fmt.Errorf("JOB_POLL_INTERVAL param wrong (INT)")
+		}
+		a.jobPollInterval = time.Duration(tm) * time.Second
+	}
+
+	a.webhookWorkers = 2
+	if workersEnv := os.Getenv("WEBHOOK_WORKERS"); workersEnv != "" {
+		a.webhookWorkers, err = strconv.Atoi(workersEnv)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_WORKERS param wrong (INT)")
+		}
+	}
+
+	a.webhookPollInterval = 5 * time.Second
+	if pollEnv := os.Getenv("WEBHOOK_POLL_INTERVAL"); pollEnv != "" {
+		tm, err := strconv.Atoi(pollEnv)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_POLL_INTERVAL param wrong (INT)")
+		}
+		a.webhookPollInterval = time.Duration(tm) * time.Second
+	}
+
+	a.webhookMaxAttempts = service.DefaultWebhookMaxAttempts
+	if attemptsEnv := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); attemptsEnv != "" {
+		a.webhookMaxAttempts, err = strconv.Atoi(attemptsEnv)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_MAX_ATTEMPTS param wrong (INT)")
+		}
+	}
+
+	a.webhookTimeout = 10 * time.Second
+	if timeoutEnv := os.Getenv("WEBHOOK_TIMEOUT"); timeoutEnv != "" {
+		tm, err := strconv.Atoi(timeoutEnv)
+		if err != nil {
+			return nil, fmt.Errorf("WEBHOOK_TIMEOUT param wrong (INT)")
+		}
+		a.webhookTimeout = time.Duration(tm) * time.Second
+	}
+
+	//webhooks to private, loopback and link-local addresses are refused unless host is listed
+	for _, host := range strings.Split(os.Getenv("WEBHOOK_ALLOWED_HOSTS"), ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			a.webhookAllowedHosts = append(a.webhookAllowedHosts, host)
+		}
+	}
+
+	a.eventsRetention = 72 * time.Hour
+	if retentionEnv := os.Getenv("EVENTS_RETENTION"); retentionEnv != "" {
+		tm, err := strconv.Atoi(retentionEnv)
+		if err != nil {
+			return nil, fmt.Errorf("EVENTS_RETENTION param wrong (INT)")
+		}
+		a.eventsRetention = time.Duration(tm) * time.Hour
+	}
+
+	a.idempotencyTTL = service.DefaultIdempotencyTTL
+	if ttlEnv := os.Getenv("IDEMPOTENCY_TTL"); ttlEnv != "" {
+		tm, err := strconv.Atoi(ttlEnv)
+		if err != nil || tm <= 0 {
+			return nil, fmt.Errorf("IDEMPOTENCY_TTL param wrong (positive INT)")
+		}
+		a.idempotencyTTL = time.Duration(tm) * time.Hour
+	}
+
+	a.outboxPollInterval = 5 * time.Second
+	if pollEnv := os.Getenv("OUTBOX_POLL_INTERVAL"); pollEnv != "" {
+		tm, err := strconv.Atoi(pollEnv)
+		if err != nil {
+			return nil, fmt.Errorf("OUTBOX_POLL_INTERVAL param wrong (INT)")
+		}
+		a.outboxPollInterval = time.Duration(tm) * time.Second
+	}
+
+	if syncEnv := os.Getenv("SYNC_INTERVAL"); syncEnv != "" {
+		tm, err := strconv.Atoi(syncEnv)
+		if err != nil {
+			return nil, fmt.Errorf("SYNC_INTERVAL param wrong (INT)")
+		}
+		a.syncInterval = time.Duration(tm) * time.Minute
+	}
+
+	a.syncRequest = service.SyncSongsRequest{
+		Mode:      os.Getenv("SYNC_MODE"),
+		ReportDir: os.Getenv("SYNC_REPORT_DIR"),
+	}
+	if a.syncRequest.Mode == "" {
+		a.syncRequest.Mode = service.SyncModeReport
+	}
+	if a.syncRequest.Mode != service.SyncModeReport && a.syncRequest.Mode != service.SyncModeApply {
+		return nil, fmt.Errorf("SYNC_MODE param wrong (report|apply)")
+	}
+	if batchEnv := os.Getenv("SYNC_BATCH_SIZE"); batchEnv != "" {
+		batch, err := strconv.ParseUint(batchEnv, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("SYNC_BATCH_SIZE param wrong (INT)")
+		}
+		a.syncRequest.BatchSize = batch
+	}
+
 	if dbdriver == "" {
 		dbdriver = POSTGRES
 	}
@@ -140,10 +282,19 @@ func New() (*App, error) {
 
 	//init db queries storage
 	a.dbq = database.NewStorage(a.db, a.l, debug)
-	//init third api service
-	songInfoService := songinfo.New(apiBaseurl, a.l)
+	//init third api services
+	providers, err := songInfoProviders(a.l)
+	if err != nil {
+		return nil, err
+	}
+	songInfoService := songinfo.NewComposite(a.l, providers...)
+	//init change event sinks
+	a.outboxSinks, err = outboxSinks(a.l)
+	if err != nil {
+		return nil, err
+	}
 	//init service layer
-	a.s = service.New(a.dbq, songInfoService, a.l, debug)
+	a.s = service.New(a.dbq, songInfoService, webhook.New(a.webhookTimeout, a.webhookAllowedHosts, a.l), a.outboxSinks, a.idempotencyTTL, a.l, debug)
 	//init endpoint
 	a.e = endpoint.New(a.s, a.l)
 
@@ -151,31 +302,201 @@ func New() (*App, error) {
 	if prod {
 		gin.SetMode(gin.ReleaseMode)
 	}
-	//init gin engine
-	a.gin = gin.Default()
+	//init gin engine, access log shows api key of request
+	a.gin = gin.New()
+	a.gin.Use(gin.LoggerWithFormatter(endpoint.AccessLog), gin.Recovery())
 
 	//set swagger basePath
-	docs.SwaggerInfo.BasePath = "/api/v1"
-
-	//register handlers
-	eg := a.gin.Group("/api/v1")
-	{
-		eg.GET("/songs", a.e.FetchSongsHandler)
-		eg.GET("/songs/:id", a.e.FetchSongTextHandler)
-		eg.DELETE("/songs/:id", a.e.DeleteSongHandler)
-		eg.PATCH("/songs/:id", a.e.UpdateSongHandler)
-		eg.PATCH("/songs/:id/verse", a.e.UpdateSongVerseHandler)
-		eg.POST("/songs/new", a.e.NewSongHandler)
-	}
-	//third route
-	a.gin.GET("/info", a.e.TestHandler)
+	docs.SwaggerInfo.BasePath = "/api"
+
+	//validate requests against swagger document, responses are checked in debug mode
+	if os.Getenv("OPENAPI_VALIDATION") != "off" {
+		doc, err := openapi.Load()
+		if err != nil {
+			return nil, err
+		}
+		//undocumented query params are rejected in api v2, OPENAPI_STRICT_QUERY=all extends it to v1, off only logs them
+		strictQuery := []string{"/api/v2/"}
+		switch os.Getenv("OPENAPI_STRICT_QUERY") {
+		case "", "v2":
+		case "all":
+			strictQuery = []string{"/api/"}
+		case "off":
+			strictQuery = nil
+		default:
+			return nil, fmt.Errorf("OPENAPI_STRICT_QUERY param wrong (v2|all|off)")
+		}
+
+		validator, err := openapi.New(doc, openapi.Options{StrictQuery: strictQuery, Responses: debug}, a.l)
+		if err != nil {
+			return nil, err
+		}
+		a.gin.Use(validator.Middleware())
+	}
+
+	//require api keys, API_AUTH=off disables authentication
+	auth := os.Getenv("API_AUTH") != "off"
+	if !auth {
+		a.l.Warn("app.New | api authentication is disabled")
+	}
+
+	//register handlers, graphql mutations check write scope themselves
+	a.e.Register(a.gin, auth)
+	graphql := []gin.HandlerFunc{gin.WrapH(graph.NewHandler(a.s, a.l))}
+	if auth {
+		graphql = append([]gin.HandlerFunc{a.e.RequireScope(service.ScopeRead)}, graphql...)
+	}
+	a.gin.POST("/api/graphql", graphql...)
 	a.gin.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
+	a.gin.NoRoute(a.e.NotFoundHandler)
+
+	//init grpc server, it is started only when GRPC_HOST is set
+	a.grpcHost = os.Getenv("GRPC_HOST")
+	if a.grpcHost != "" {
+		a.grpc = grpcserver.NewGRPCServer(a.s, a.l, auth)
+	}
 
 	return a, nil
 }
 
+// outboxSinks reads list of additional change event sinks from OUTBOX_SINKS (log, file, http),
+// events log and webhooks always get events
+func outboxSinks(l *logger.Logger) ([]sink.Sink, error) {
+	var sinks []sink.Sink
+	for _, name := range strings.Split(os.Getenv("OUTBOX_SINKS"), ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case "log":
+			sinks = append(sinks, sink.NewLog(l))
+		case "file":
+			path := os.Getenv("OUTBOX_FILE")
+			if path == "" {
+				path = "./events.ndjson"
+			}
+			file, err := sink.NewFile(path)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, file)
+		case "http":
+			url := os.Getenv("OUTBOX_HTTP_URL")
+			if url == "" {
+				return nil, fmt.Errorf("OUTBOX_HTTP_URL param is required")
+			}
+			timeout := 10 * time.Second
+			if timeoutEnv := os.Getenv("OUTBOX_HTTP_TIMEOUT"); timeoutEnv != "" {
+				tm, err := strconv.Atoi(timeoutEnv)
+				if err != nil {
+					return nil, fmt.Errorf("OUTBOX_HTTP_TIMEOUT param wrong (INT)")
+				}
+				timeout = time.Duration(tm) * time.Second
+			}
+			sinks = append(sinks, sink.NewHTTP(url, timeout))
+		default:
+			return nil, fmt.Errorf("OUTBOX_SINKS param wrong (log|file|http): %s", name)
+		}
+	}
+	return sinks, nil
+}
+
+// songInfoProviders reads ordered provider list from API_PROVIDERS,
+// each provider is configured by API_<NAME>_* params.
+// Without API_PROVIDERS single provider from API_BASEURL is used
+func songInfoProviders(l *logger.Logger) ([]songinfo.Provider, error) {
+	names := os.Getenv("API_PROVIDERS")
+	if names == "" {
+		return []songinfo.Provider{
+			songinfo.NewProvider(songinfo.ProviderConfig{BaseURL: os.Getenv("API_BASEURL")}, l),
+		}, nil
+	}
+
+	var providers []songinfo.Provider
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		prefix := "API_" + strings.ToUpper(name) + "_"
+
+		baseURL := os.Getenv(prefix + "BASEURL")
+		if baseURL == "" {
+			return nil, fmt.Errorf("%sBASEURL param is required", prefix)
+		}
+
+		var timeout time.Duration
+		if timeoutEnv := os.Getenv(prefix + "TIMEOUT"); timeoutEnv != "" {
+			tm, err := strconv.Atoi(timeoutEnv)
+			if err != nil {
+				return nil, fmt.Errorf("%sTIMEOUT param wrong (INT)", prefix)
+			}
+			timeout = time.Duration(tm) * time.Second
+		}
+
+		providers = append(providers, songinfo.NewProvider(songinfo.ProviderConfig{
+			Name:     name,
+			BaseURL:  baseURL,
+			Token:    os.Getenv(prefix + "TOKEN"),
+			User:     os.Getenv(prefix + "USER"),
+			Password: os.Getenv(prefix + "PASSWORD"),
+			Timeout:  timeout,
+		}, l))
+	}
+
+	return providers, nil
+}
+
 func (a *App) Run() error {
 	defer a.db.Close()
+	for _, out := range a.outboxSinks {
+		if closer, ok := out.(io.Closer); ok {
+			defer closer.Close()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	//start song creation workers
+	if err := a.s.StartWorkers(ctx, a.jobWorkers, a.jobPollInterval); err != nil {
+		return fmt.Errorf("failed to start job workers: %w", err)
+	}
+
+	//publish change events from outbox
+	a.s.StartOutboxRelay(ctx, a.outboxPollInterval)
+
+	//start webhook delivery workers
+	if err := a.s.StartWebhookWorkers(ctx, a.webhookWorkers, a.webhookPollInterval, a.webhookTimeout, a.webhookMaxAttempts); err != nil {
+		return fmt.Errorf("failed to start webhook workers: %w", err)
+	}
+
+	//keep events log for stream resume, EVENTS_RETENTION=0 keeps all events
+	if a.eventsRetention > 0 {
+		a.s.StartEventsCleanup(ctx, a.eventsRetention)
+	}
+
+	//drop expired idempotency keys
+	a.s.StartIdempotencyCleanup(ctx)
+
+	//schedule song metadata sync
+	if a.syncInterval > 0 {
+		a.s.StartSync(ctx, a.syncInterval, a.syncRequest)
+	}
+
+	//start grpc server next to http
+	if a.grpc != nil {
+		lis, err := net.Listen("tcp", a.grpcHost)
+		if err != nil {
+			return fmt.Errorf("failed to listen grpc host: %w", err)
+		}
+		defer a.grpc.GracefulStop()
+
+		a.l.Info("Start grpc server", "host", a.grpcHost)
+		go func() {
+			if err := a.grpc.Serve(lis); err != nil {
+				a.l.Error("app.Run | grpc server stopped", "error", err.Error())
+			}
+		}()
+	}
 
 	//init host
 	hostOption := os.Getenv("APP_HOST")
@@ -0,0 +1,262 @@
+package app
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// RunCommand runs cli command instead of http server
+func (a *App) RunCommand(args []string) error {
+	defer a.db.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	switch args[0] {
+	case "import":
+		return a.importCommand(ctx, args[1:])
+	case "export":
+		return a.exportCommand(ctx, args[1:])
+	case "apikey":
+		return a.apiKeyCommand(ctx, args[1:])
+	default:
+		return fmt.Errorf("unknown command %q, available: import, export, apikey", args[0])
+	}
+}
+
+func (a *App) importCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	file := fs.String("file", "", "path to ndjson or csv file")
+	format := fs.String("format", "", "ndjson or csv, by default from file extension")
+	dryRun := fs.Bool("dry-run", false, "validate and check duplicates without saving")
+	batchSize := fs.Int("batch-size", 100, "songs per transaction")
+	reportPath := fs.String("report", "-", "report file, - for stdout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return fmt.Errorf("import: -file is required")
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*file)), ".")
+	}
+
+	in, err := os.Open(*file)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer in.Close()
+
+	out, closeOut, err := openOutput(*reportPath)
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+	defer closeOut()
+
+	enc := json.NewEncoder(out)
+	result, err := a.s.ImportSongs(ctx, service.ImportSongsRequest{
+		Reader:    in,
+		Format:    *format,
+		DryRun:    *dryRun,
+		BatchSize: *batchSize,
+	}, func(row service.ImportRowReport) error {
+		return enc.Encode(row)
+	})
+	if err != nil {
+		return fmt.Errorf("import: %w", err)
+	}
+
+	a.l.Info("import finished", "result", result)
+	return nil
+}
+
+func (a *App) exportCommand(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	outPath := fs.String("out", "-", "output file, - for stdout")
+	format := fs.String("format", "", "ndjson or csv, by default from file extension or ndjson")
+	group := fs.String("group", "", "filter by group name")
+	song := fs.String("song", "", "filter by song name")
+	text := fs.String("text", "", "filter by part of song text")
+	releaseDate := fs.String("releaseDate", "", "filter by release date, example 02.01.2006")
+	updatedSince := fs.String("updated-since", "", "filter songs changed since time, example 2024-07-03T10:00:00Z")
+	releasedFrom := fs.String("released-from", "", "filter songs released on or after date, example 2006")
+	releasedTo := fs.String("released-to", "", "filter songs released on or before date, example 12.2009")
+	year := fs.Int("year", 0, "filter by release year")
+	decade := fs.Int("decade", 0, "filter by first year of release decade, example 1990")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*outPath)), ".")
+	}
+	if *format == "" {
+		*format = service.FormatNDJSON
+	}
+
+	//empty string flags are not filtered by, -year and -decade are used when given even if 0
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	optional := func(value *string) *string {
+		if *value == "" {
+			return nil
+		}
+		return value
+	}
+
+	params := service.SongFilterParams{
+		GroupName:    optional(group),
+		SongName:     optional(song),
+		SongText:     optional(text),
+		ReleaseDate:  optional(releaseDate),
+		ReleasedFrom: optional(releasedFrom),
+		ReleasedTo:   optional(releasedTo),
+		Names: service.FilterNames{
+			ReleaseDate:  "releaseDate",
+			ReleasedFrom: "released-from",
+			ReleasedTo:   "released-to",
+			Year:         "year",
+			Decade:       "decade",
+		},
+	}
+	if set["year"] {
+		params.Year = year
+	}
+	if set["decade"] {
+		params.Decade = decade
+	}
+	if *updatedSince != "" {
+		since, err := time.Parse(time.RFC3339, *updatedSince)
+		if err != nil {
+			return fmt.Errorf("export: wrong format updated-since: example 2024-07-03T10:00:00Z")
+		}
+		params.UpdatedSince = &since
+	}
+
+	filter, err := service.NewSongFilter(params)
+	if err != nil {
+		return fmt.Errorf("export: %w", filterError(err))
+	}
+	request := service.ExportSongsRequest{SongFilter: filter, Format: strings.ToLower(*format)}
+
+	if err := service.CheckExportFormat(request.Format); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	out, closeOut, err := openOutput(*outPath)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	defer closeOut()
+
+	bw := bufio.NewWriter(out)
+	count, err := a.s.ExportSongs(ctx, request, bw)
+	if err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("export: %w", err)
+	}
+
+	a.l.Info("export finished", "exported", count)
+	return nil
+}
+
+// apiKeyCommand manages api keys: apikey create|list|revoke
+func (a *App) apiKeyCommand(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("apikey: subcommand is required, available: create, list, revoke")
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	switch args[0] {
+	case "create":
+		fs := flag.NewFlagSet("apikey create", flag.ContinueOnError)
+		name := fs.String("name", "", "name of key owner")
+		scopes := fs.String("scopes", service.ScopeRead, "comma separated scopes: read, write, admin")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		request := service.CreateAPIKeyRequest{Name: *name}
+		for _, scope := range strings.Split(*scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				request.Scopes = append(request.Scopes, scope)
+			}
+		}
+
+		key, err := a.s.CreateAPIKey(ctx, request)
+		if err != nil {
+			return fmt.Errorf("apikey create: %w", err)
+		}
+		a.l.Info("api key created, token is shown only once", "key_id", key.ID)
+		return enc.Encode(key)
+	case "list":
+		keys, err := a.s.FetchAPIKeys(ctx)
+		if err != nil {
+			return fmt.Errorf("apikey list: %w", err)
+		}
+		for _, key := range keys {
+			if err := enc.Encode(key); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "revoke":
+		fs := flag.NewFlagSet("apikey revoke", flag.ContinueOnError)
+		id := fs.String("id", "", "id of key")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *id == "" {
+			return fmt.Errorf("apikey revoke: -id is required")
+		}
+
+		if err := a.s.RevokeAPIKey(ctx, service.RevokeAPIKeyRequest{KeyID: *id}); err != nil {
+			return fmt.Errorf("apikey revoke: %w", err)
+		}
+		a.l.Info("api key revoked", "key_id", *id)
+		return nil
+	default:
+		return fmt.Errorf("apikey: unknown subcommand %q, available: create, list, revoke", args[0])
+	}
+}
+
+// openOutput opens file for writing, "-" means stdout
+func openOutput(path string) (io.Writer, func(), error) {
+	if path == "-" || path == "" {
+		return os.Stdout, func() {}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, func() { _ = f.Close() }, nil
+}
+
+// filterError adds field details of validation error to its message
+func filterError(err error) error {
+	var perr *problem.Error
+	if !errors.As(err, &perr) || len(perr.Fields) == 0 {
+		return err
+	}
+	details := make([]string, 0, len(perr.Fields))
+	for _, field := range perr.Fields {
+		details = append(details, field.Field+": "+field.Message)
+	}
+	return fmt.Errorf("%w: %s", err, strings.Join(details, "; "))
+}
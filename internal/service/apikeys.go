@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+// API key scopes, admin includes write and write includes read
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+const (
+	apiKeyPrefix      = "mlk_"
+	apiKeyIDBytes     = 8
+	apiKeySecretBytes = 32
+
+	apiKeyTouchInterval = time.Minute
+)
+
+var (
+	ErrUnauthorized   = problem.New(problem.CodeUnauthorized, "valid api key is required")
+	ErrForbidden      = problem.New(problem.CodeForbidden, "api key has no scope for this request")
+	ErrAPIKeyNotFound = problem.New(problem.CodeAPIKeyNotFound, "api key is not found or already revoked")
+	ErrAPIKeyName     = problem.Validation(problem.FieldError{Field: "name", Message: "name is required"})
+)
+
+// HasScope reports whether key grants scope
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.Scopes {
+		switch {
+		case granted == scope, granted == ScopeAdmin:
+			return true
+		case granted == ScopeWrite && scope == ScopeRead:
+			return true
+		}
+	}
+	return false
+}
+
+// IsScope reports whether scope is known
+func IsScope(scope string) bool {
+	return slices.Contains(Scopes, scope)
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// CreateAPIKey generates key with given scopes, only hash of its secret is stored
+// and token is returned only here
+func (s *Service) CreateAPIKey(ctx context.Context, request CreateAPIKeyRequest) (*APIKey, error) {
+	if s.debug {
+		s.log.Info("service.CreateAPIKey | request data", "request", request)
+	}
+
+	if strings.TrimSpace(request.Name) == "" {
+		return nil, ErrAPIKeyName
+	}
+	if len(request.Scopes) == 0 {
+		return nil, problem.Validation(problem.FieldError{Field: "scopes", Message: "at least one scope is required"})
+	}
+	for _, scope := range request.Scopes {
+		if !IsScope(scope) {
+			return nil, problem.Validation(problem.FieldError{
+				Field:   "scopes",
+				Message: fmt.Sprintf("unknown scope %q, allowed: %v", scope, Scopes),
+			})
+		}
+	}
+
+	id := make([]byte, apiKeyIDBytes)
+	secret := make([]byte, apiKeySecretBytes)
+	if _, err := rand.Read(id); err != nil {
+		s.log.Error("service.CreateAPIKey | rand.Read", "error", err.Error())
+		return nil, ErrRequest
+	}
+	if _, err := rand.Read(secret); err != nil {
+		s.log.Error("service.CreateAPIKey | rand.Read", "error", err.Error())
+		return nil, ErrRequest
+	}
+	keyID := hex.EncodeToString(id)
+	keySecret := base64.RawURLEncoding.EncodeToString(secret)
+
+	dbKey, err := s.storage.CreateAPIKey(ctx, database.CreateAPIKeyRequest{
+		KeyID:   keyID,
+		Name:    request.Name,
+		KeyHash: hashAPIKeySecret(keySecret),
+		Scopes:  request.Scopes,
+	})
+	if err != nil {
+		s.log.Error("service.CreateAPIKey | CreateAPIKey", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	s.log.Info("service.CreateAPIKey | api key created", "key_id", keyID, "scopes", request.Scopes)
+
+	result := apiKeyFromStorage(*dbKey)
+	result.Token = apiKeyPrefix + keyID + "_" + keySecret
+	return &result, nil
+}
+
+// FetchAPIKeys returns all keys including revoked ones
+func (s *Service) FetchAPIKeys(ctx context.Context) ([]APIKey, error) {
+	keys, err := s.storage.GetAPIKeys(ctx)
+	if err != nil {
+		s.log.Error("service.FetchAPIKeys | GetAPIKeys", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make([]APIKey, 0, len(keys))
+	for _, item := range keys {
+		result = append(result, apiKeyFromStorage(item))
+	}
+	return result, nil
+}
+
+type RevokeAPIKeyRequest struct {
+	KeyID string `json:"key_id"`
+}
+
+// RevokeAPIKey disables key, requests with it are rejected right away
+func (s *Service) RevokeAPIKey(ctx context.Context, request RevokeAPIKeyRequest) error {
+	if err := s.storage.RevokeAPIKey(ctx, request.KeyID); err != nil {
+		s.log.Error("service.RevokeAPIKey | RevokeAPIKey", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrAPIKeyNotFound
+		default:
+			return ErrRequest
+		}
+	}
+
+	s.log.Info("service.RevokeAPIKey | api key revoked", "key_id", request.KeyID)
+	return nil
+}
+
+// Authenticate returns active key of token, unknown, malformed and revoked tokens
+// are answered with ErrUnauthorized
+func (s *Service) Authenticate(ctx context.Context, token string) (*APIKey, error) {
+	keyID, secret, ok := parseAPIKey(token)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	dbKey, err := s.storage.GetAPIKey(ctx, keyID)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			s.log.Warn("service.Authenticate | unknown api key", "key_id", keyID)
+			return nil, ErrUnauthorized
+		case errors.Is(err, context.DeadlineExceeded):
+			s.log.Error("service.Authenticate | GetAPIKey", "error", err.Error())
+			return nil, ErrTimeOut
+		default:
+			s.log.Error("service.Authenticate | GetAPIKey", "error", err.Error())
+			return nil, ErrRequest
+		}
+	}
+
+	if subtle.ConstantTimeCompare([]byte(dbKey.KeyHash), []byte(hashAPIKeySecret(secret))) != 1 {
+		s.log.Warn("service.Authenticate | wrong api key secret", "key_id", keyID)
+		return nil, ErrUnauthorized
+	}
+	if dbKey.RevokedAt != nil {
+		s.log.Warn("service.Authenticate | revoked api key", "key_id", keyID)
+		return nil, ErrUnauthorized
+	}
+
+	//usage time is informational, request is not failed by it and it is written
+	//at most once per apiKeyTouchInterval, not on every request
+	if dbKey.LastUsedAt == nil || time.Since(*dbKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := s.storage.TouchAPIKey(ctx, keyID); err != nil {
+			s.log.Error("service.Authenticate | TouchAPIKey", "error", err.Error(), "key_id", keyID)
+		}
+	}
+
+	key := apiKeyFromStorage(*dbKey)
+	return &key, nil
+}
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns context carrying authenticated key
+func WithAPIKey(ctx context.Context, key *APIKey) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKeyFromContext returns key authenticated for request, nil when authentication is disabled
+func APIKeyFromContext(ctx context.Context) *APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*APIKey)
+	return key
+}
+
+// CheckScope checks scope of key in context for transports authorizing single operations
+// after authentication, context without key passes as authentication is disabled
+func CheckScope(ctx context.Context, scope string) error {
+	key := APIKeyFromContext(ctx)
+	if key != nil && !key.HasScope(scope) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// parseAPIKey splits token mlk_<key id>_<secret>
+func parseAPIKey(token string) (string, string, bool) {
+	rest, ok := strings.CutPrefix(token, apiKeyPrefix)
+	if !ok {
+		return "", "", false
+	}
+	keyID, secret, ok := strings.Cut(rest, "_")
+	if !ok || len(keyID) != apiKeyIDBytes*2 || secret == "" {
+		return "", "", false
+	}
+	return keyID, secret, true
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
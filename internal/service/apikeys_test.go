@@ -0,0 +1,40 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// TestAuthenticateTouchInterval checks that usage time of api key is not written on every request
+func TestAuthenticateTouchInterval(t *testing.T) {
+	recent := time.Now().Add(-10 * time.Second)
+	old := time.Now().Add(-2 * time.Minute)
+
+	cases := []struct {
+		name       string
+		lastUsedAt *time.Time
+		touches    int
+	}{
+		{name: "never used", lastUsedAt: nil, touches: 1},
+		{name: "used recently", lastUsedAt: &recent, touches: 0},
+		{name: "used long ago", lastUsedAt: &old, touches: 1},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			storage := &memStorage{lastUsedAt: tc.lastUsedAt}
+			s := service.New(storage, nil, nil, nil, 0, logger.New(), false)
+
+			if _, err := s.Authenticate(context.Background(), "mlk_"+testKeyID+"_"+testSecret); err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if storage.touches != tc.touches {
+				t.Errorf("TouchAPIKey called %d times, want %d", storage.touches, tc.touches)
+			}
+		})
+	}
+}
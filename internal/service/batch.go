@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+// Batch operations
+const (
+	BatchCreateSong  = "create_song"
+	BatchUpdateSong  = "update_song"
+	BatchUpdateVerse = "update_verse"
+	BatchDeleteSong  = "delete_song"
+)
+
+// Batch operation statuses
+const (
+	BatchStatusDone       = "done"
+	BatchStatusFailed     = "failed"
+	BatchStatusRolledBack = "rolled_back"
+	BatchStatusSkipped    = "skipped"
+)
+
+// MaxBatchOperations limits count of operations in one batch
+const MaxBatchOperations = 100
+
+// BatchOperation is one change of batch, request matching Op is used
+type BatchOperation struct {
+	Op          string              `json:"op"`
+	CreateSong  *CreateSongRequest  `json:"create_song,omitempty"`
+	UpdateSong  *UpdateSongRequest  `json:"update_song,omitempty"`
+	UpdateVerse *UpdateVerseRequest `json:"update_verse,omitempty"`
+	DeleteSong  *DeleteSongRequest  `json:"delete_song,omitempty"`
+}
+
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchResult is outcome of one operation, when batch fails operations done before
+// the failed one are rolled_back and operations after it are skipped.
+// Err of failed operation is converted to Error by transport
+type BatchResult struct {
+	Op      string           `json:"op" example:"update_song"`
+	Status  string           `json:"status" example:"done"`
+	SongID  int              `json:"song_id,omitempty" example:"1"`
+	Version int              `json:"version,omitempty" example:"2"`
+	Song    *Song            `json:"song,omitempty"`
+	Verse   *VerseSmall      `json:"verse,omitempty"`
+	Error   *problem.Problem `json:"error,omitempty"`
+	Err     error            `json:"-"`
+}
+
+type BatchResponse struct {
+	Committed bool          `json:"committed"`
+	Results   []BatchResult `json:"results"`
+}
+
+// Batch runs operations in one transaction, all of them are applied or none.
+// When operation fails response with results of every operation is returned with error of failed one
+func (s *Service) Batch(ctx context.Context, request BatchRequest) (*BatchResponse, error) {
+	if s.debug {
+		s.log.Info("service.Batch | request data", "request", request)
+	}
+
+	if err := validateBatch(request); err != nil {
+		return nil, err
+	}
+
+	response := &BatchResponse{Results: make([]BatchResult, len(request.Operations))}
+	for i, op := range request.Operations {
+		response.Results[i] = BatchResult{Op: op.Op, Status: BatchStatusSkipped}
+	}
+
+	var failed error
+	err := s.storage.InTx(ctx, func(storage database.Storage) error {
+		//operations use service methods running on storage bound to transaction
+		tx := *s
+		tx.storage = storage
+
+		for i, op := range request.Operations {
+			result := &response.Results[i]
+			if err := tx.batchOperation(ctx, op, result); err != nil {
+				result.Status = BatchStatusFailed
+				result.Err = err
+				failed = err
+				return err
+			}
+			result.Status = BatchStatusDone
+		}
+		return nil
+	})
+	if err != nil {
+		//versions and ids given by rolled back operations do not exist
+		for i, result := range response.Results {
+			if result.Status == BatchStatusDone {
+				response.Results[i] = BatchResult{Op: result.Op, Status: BatchStatusRolledBack}
+				if result.Op != BatchCreateSong {
+					response.Results[i].SongID = result.SongID
+				}
+			}
+		}
+		if failed != nil {
+			return response, failed
+		}
+
+		s.log.Error("service.Batch | InTx", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	response.Committed = true
+	//events of operations are visible to relay only after commit
+	s.wakeRelay()
+
+	if s.debug {
+		s.log.Info("service.Batch | response data", "results", response.Results)
+	}
+
+	return response, nil
+}
+
+func (s *Service) batchOperation(ctx context.Context, op BatchOperation, result *BatchResult) error {
+	switch op.Op {
+	case BatchCreateSong:
+		song, err := s.CreateSong(ctx, *op.CreateSong)
+		if err != nil {
+			return err
+		}
+		result.SongID = song.ID
+		result.Version = song.Version
+		result.Song = song
+	case BatchUpdateSong:
+		resp, err := s.UpdateSong(ctx, *op.UpdateSong)
+		if err != nil {
+			return err
+		}
+		result.SongID = op.UpdateSong.SongID
+		result.Version = resp.Version
+	case BatchUpdateVerse:
+		resp, err := s.UpdateVerse(ctx, *op.UpdateVerse)
+		if err != nil {
+			return err
+		}
+		result.SongID = op.UpdateVerse.SongID
+		result.Version = resp.Version
+		result.Verse = &resp.Verse
+	case BatchDeleteSong:
+		if _, err := s.DeleteSong(ctx, *op.DeleteSong); err != nil {
+			return err
+		}
+		result.SongID = op.DeleteSong.SongID
+	}
+	return nil
+}
+
+// validateBatch checks that every operation is known and has its request
+func validateBatch(request BatchRequest) error {
+	if len(request.Operations) == 0 {
+		return problem.Validation(problem.FieldError{Field: "operations", Message: "at least one operation is required"})
+	}
+	if len(request.Operations) > MaxBatchOperations {
+		return problem.Validation(problem.FieldError{Field: "operations",
+			Message: fmt.Sprintf("batch is limited to %d operations", MaxBatchOperations)})
+	}
+
+	var fields []problem.FieldError
+	for i, op := range request.Operations {
+		var missing bool
+		switch op.Op {
+		case BatchCreateSong:
+			missing = op.CreateSong == nil
+		case BatchUpdateSong:
+			missing = op.UpdateSong == nil
+		case BatchUpdateVerse:
+			missing = op.UpdateVerse == nil
+		case BatchDeleteSong:
+			missing = op.DeleteSong == nil
+		default:
+			fields = append(fields, problem.FieldError{
+				Field:   fmt.Sprintf("operations[%d].op", i),
+				Message: fmt.Sprintf("unknown operation %q, allowed: create_song, update_song, update_verse, delete_song", op.Op),
+			})
+			continue
+		}
+		if missing {
+			fields = append(fields, problem.FieldError{
+				Field:   fmt.Sprintf("operations[%d]", i),
+				Message: "operation request is required",
+			})
+		}
+	}
+	if len(fields) > 0 {
+		return problem.Validation(fields...)
+	}
+	return nil
+}
@@ -0,0 +1,42 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// TestBatchRollsBackOnFailure checks that failed operation rolls back operations done before it
+// and operations after it are skipped
+func TestBatchRollsBackOnFailure(t *testing.T) {
+	storage := &memStorage{songs: map[int]bool{1: true, 2: true}}
+	s := service.New(storage, nil, nil, nil, 0, logger.New(), false)
+
+	resp, err := s.Batch(context.Background(), service.BatchRequest{Operations: []service.BatchOperation{
+		{Op: service.BatchDeleteSong, DeleteSong: &service.DeleteSongRequest{SongID: 1}},
+		{Op: service.BatchDeleteSong, DeleteSong: &service.DeleteSongRequest{SongID: 99}},
+		{Op: service.BatchDeleteSong, DeleteSong: &service.DeleteSongRequest{SongID: 2}},
+	}})
+	if !errors.Is(err, service.ErrSongNotFound) {
+		t.Fatalf("err = %v, want %v", err, service.ErrSongNotFound)
+	}
+	if resp == nil || resp.Committed {
+		t.Fatalf("response %+v, want not committed results", resp)
+	}
+
+	want := []string{service.BatchStatusRolledBack, service.BatchStatusFailed, service.BatchStatusSkipped}
+	for i, status := range want {
+		if resp.Results[i].Status != status {
+			t.Errorf("results[%d].status = %s, want %s", i, resp.Results[i].Status, status)
+		}
+	}
+	if !errors.Is(resp.Results[1].Err, service.ErrSongNotFound) {
+		t.Errorf("results[1].err = %v, want %v", resp.Results[1].Err, service.ErrSongNotFound)
+	}
+	if !storage.songs[1] || !storage.songs[2] {
+		t.Errorf("songs %v, deleted song is not restored", storage.songs)
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+)
+
+const (
+	EventSongCreated  = database.EventSongCreated
+	EventSongUpdated  = database.EventSongUpdated
+	EventSongDeleted  = database.EventSongDeleted
+	EventVerseUpdated = database.EventVerseUpdated
+
+	//events logged by other instances are picked up by polling
+	eventsPollInterval = 2 * time.Second
+	eventsKeepAlive    = 15 * time.Second
+	eventsBatch        = 100
+	eventsCleanup      = time.Hour
+)
+
+// EventTypes are library change events available to subscribers
+var EventTypes = []string{EventSongCreated, EventSongUpdated, EventSongDeleted, EventVerseUpdated}
+
+// Event describes committed library change, Song holds state of the song
+// after the change or before it for deleted songs. ID is the same
+// for repeated publish of the event
+type Event struct {
+	ID         int64       `json:"id" example:"42"`
+	Type       string      `json:"type" example:"song.updated"`
+	SongID     int         `json:"song_id" example:"1"`
+	Song       *Song       `json:"song,omitempty"`
+	Verse      *VerseSmall `json:"verse,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at" example:"2024-07-03T10:00:00Z"`
+}
+
+func IsEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// eventSignal wakes up event streams of this instance when event is logged
+type eventSignal struct {
+	mu sync.Mutex
+	ch chan struct{}
+}
+
+func newEventSignal() *eventSignal {
+	return &eventSignal{ch: make(chan struct{})}
+}
+
+// wait returns channel closed on next notify
+func (e *eventSignal) wait() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.ch
+}
+
+func (e *eventSignal) notify() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	close(e.ch)
+	e.ch = make(chan struct{})
+}
+
+type StreamEventsRequest struct {
+	LastEventID *int64  `json:"last_event_id,omitempty"`
+	GroupName   *string `json:"group_name,omitempty"`
+	SongID      *int    `json:"song_id,omitempty"`
+}
+
+// EventStream receives events of StreamEvents, position is id of event
+// in events log to resume stream from
+type EventStream interface {
+	Send(position int64, event Event) error
+	// KeepAlive is called when there were no events for a while
+	KeepAlive() error
+}
+
+// StreamEvents sends logged events to stream until ctx is done or stream fails.
+// Without LastEventID only events logged after the call are sent,
+// otherwise stream resumes after event with given id
+func (s *Service) StreamEvents(ctx context.Context, request StreamEventsRequest, stream EventStream) error {
+	if s.debug {
+		s.log.Info("service.StreamEvents | request data", "request", request)
+	}
+
+	var afterID int64
+	if request.LastEventID != nil {
+		afterID = *request.LastEventID
+	} else {
+		lastID, err := s.storage.GetLastEventID(ctx)
+		if err != nil {
+			s.log.Error("service.StreamEvents | GetLastEventID", "error", err.Error())
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				return ErrTimeOut
+			default:
+				return ErrRequest
+			}
+		}
+		afterID = lastID
+	}
+
+	//stream is opened without waiting for the first event
+	if err := stream.KeepAlive(); err != nil {
+		return err
+	}
+
+	poll := time.NewTicker(eventsPollInterval)
+	defer poll.Stop()
+	keepAlive := time.NewTicker(eventsKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		//signal is taken before reading, so event logged meanwhile is not missed
+		changed := s.changes.wait()
+
+		events, err := s.storage.GetEvents(ctx, database.GetEventsRequest{
+			AfterID:   afterID,
+			GroupName: request.GroupName,
+			SongID:    request.SongID,
+			Limit:     eventsBatch,
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			s.log.Error("service.StreamEvents | GetEvents", "error", err.Error())
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				return ErrTimeOut
+			default:
+				return ErrRequest
+			}
+		}
+
+		for _, item := range events {
+			var event Event
+			if err := json.Unmarshal(item.Payload, &event); err != nil {
+				s.log.Error("service.StreamEvents | Unmarshal", "event_id", item.EventID, "error", err.Error())
+				return ErrRequest
+			}
+			if err := stream.Send(item.EventID, event); err != nil {
+				return err
+			}
+			afterID = item.EventID
+		}
+
+		if len(events) > 0 {
+			keepAlive.Reset(eventsKeepAlive)
+			if len(events) == eventsBatch {
+				continue
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		case <-poll.C:
+		case <-keepAlive.C:
+			if err := stream.KeepAlive(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// StartEventsCleanup removes events older than retention from events log
+// and published events from outbox every hour
+func (s *Service) StartEventsCleanup(ctx context.Context, retention time.Duration) {
+	go func() {
+		ticker := time.NewTicker(eventsCleanup)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				before := time.Now().Add(-retention)
+				deleted, err := s.storage.DeleteEvents(ctx, before)
+				if err != nil {
+					s.log.Error("service.StartEventsCleanup | DeleteEvents", "error", err.Error())
+					continue
+				}
+				published, err := s.storage.DeletePublishedOutbox(ctx, before)
+				if err != nil {
+					s.log.Error("service.StartEventsCleanup | DeletePublishedOutbox", "error", err.Error())
+					continue
+				}
+				if s.debug {
+					s.log.Info("service.StartEventsCleanup | events deleted", "count", deleted, "outbox", published)
+				}
+			}
+		}
+	}()
+
+	s.log.Info("service.StartEventsCleanup | events cleanup scheduled", "retention", retention.String())
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+)
+
+// exportFlushEvery is number of songs written between flushes of writer
+const exportFlushEvery = 100
+
+var exportCSVHeader = []string{"id", "group", "song", "releaseDate", "link", "text"}
+
+type ExportSongsRequest struct {
+	SongFilter
+	Format string `json:"format"`
+}
+
+// ExportRecord is one exported song, it can be imported back with ImportSongs
+type ExportRecord struct {
+	ID          int      `json:"id"`
+	GroupName   string   `json:"group"`
+	SongName    string   `json:"song"`
+	ReleaseDate string   `json:"releaseDate,omitempty"`
+	Link        string   `json:"link,omitempty"`
+	Verses      []string `json:"verses"`
+}
+
+// flusher is implemented by http.ResponseWriter and bufio.Writer
+type flusher interface {
+	Flush()
+}
+
+// CheckExportFormat validates format before response is started
+func CheckExportFormat(format string) error {
+	switch strings.ToLower(format) {
+	case FormatNDJSON, FormatCSV:
+		return nil
+	default:
+		return ErrUnknownFormat
+	}
+}
+
+// ExportSongs writes songs matching filter with verses to w as ndjson or csv.
+// Songs are streamed from database one by one, returns number of exported songs
+func (s *Service) ExportSongs(ctx context.Context, request ExportSongsRequest, w io.Writer) (int, error) {
+	if s.debug {
+		s.log.Info("service.ExportSongs | request data", "request", request)
+	}
+
+	if err := CheckExportFormat(request.Format); err != nil {
+		return 0, err
+	}
+
+	var write func(record ExportRecord) error
+	var flush func() error
+
+	if strings.ToLower(request.Format) == FormatCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportCSVHeader); err != nil {
+			return 0, err
+		}
+		write = func(record ExportRecord) error {
+			return cw.Write([]string{
+				strconv.Itoa(record.ID),
+				record.GroupName,
+				record.SongName,
+				record.ReleaseDate,
+				record.Link,
+				strings.Join(record.Verses, "\n\n"),
+			})
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	} else {
+		enc := json.NewEncoder(w)
+		write = func(record ExportRecord) error {
+			return enc.Encode(record)
+		}
+		flush = func() error {
+			return nil
+		}
+	}
+
+	count := 0
+	text := make([]string, 0)
+	err := s.storage.ExportSongs(ctx, request.storage(), func(song database.Song, verses []database.VerseSmall) error {
+		text = text[:0]
+		for _, v := range verses {
+			text = append(text, v.VerseText)
+		}
+
+		record := ExportRecord{
+			ID:        song.SongID,
+			GroupName: song.GroupName,
+			SongName:  song.SongName,
+			Link:      song.Link,
+			Verses:    text,
+		}
+		if song.ReleaseDate != nil {
+			record.ReleaseDate = dateparse.Date{
+				Time:      *song.ReleaseDate,
+				Precision: dateparse.Precision(song.ReleaseDatePrecision),
+			}.Format()
+		}
+
+		if err := write(record); err != nil {
+			return err
+		}
+
+		count++
+		if count%exportFlushEvery == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			if f, ok := w.(flusher); ok {
+				f.Flush()
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		s.log.Error("service.ExportSongs | ExportSongs", "exported", count, "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return count, ErrTimeOut
+		default:
+			return count, ErrRequest
+		}
+	}
+
+	if f, ok := w.(flusher); ok {
+		f.Flush()
+	}
+
+	if s.debug {
+		s.log.Info("service.ExportSongs | response data", "exported", count)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,89 @@
+package service_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"maps"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+const (
+	testKeyID  = "9f86d081884c7d65"
+	testSecret = "secret"
+)
+
+// memStorage keeps data of tested service methods in memory.
+// Methods not used by tests are nil through embedded interface and panic when called
+type memStorage struct {
+	database.Storage
+
+	//songs by id, InTx works on a copy which replaces songs only when fn succeeds
+	songs map[int]bool
+	//imported songs by group and song name, dry run batches are not kept
+	imported map[string]bool
+	//usage time of the only api key testKeyID and count of its updates
+	lastUsedAt *time.Time
+	touches    int
+}
+
+func (f *memStorage) InTx(ctx context.Context, fn func(s database.Storage) error) error {
+	tx := *f
+	tx.songs = maps.Clone(f.songs)
+	if err := fn(&tx); err != nil {
+		return err
+	}
+	f.songs = tx.songs
+	return nil
+}
+
+func (f *memStorage) DeleteSong(ctx context.Context, songID int) error {
+	if !f.songs[songID] {
+		return sql.ErrNoRows
+	}
+	delete(f.songs, songID)
+	return nil
+}
+
+func (f *memStorage) ImportSongs(ctx context.Context, requests []database.AddSongRequest, dryRun bool) ([]database.ImportSongResult, error) {
+	batch := make(map[string]bool, len(requests))
+	results := make([]database.ImportSongResult, len(requests))
+	for i, request := range requests {
+		key := request.GroupName + "\x00" + request.SongName
+		if f.imported[key] || batch[key] {
+			results[i].Err = database.ErrDuplicateKey
+			continue
+		}
+		batch[key] = true
+		results[i].SongID = int64(len(f.imported) + len(batch))
+	}
+	if !dryRun {
+		for key := range batch {
+			f.imported[key] = true
+		}
+	}
+	return results, nil
+}
+
+func (f *memStorage) GetAPIKey(ctx context.Context, keyID string) (*database.APIKey, error) {
+	if keyID != testKeyID {
+		return nil, sql.ErrNoRows
+	}
+	sum := sha256.Sum256([]byte(testSecret))
+	return &database.APIKey{
+		KeyID:      keyID,
+		Name:       "frontend",
+		KeyHash:    hex.EncodeToString(sum[:]),
+		Scopes:     []string{service.ScopeRead},
+		LastUsedAt: f.lastUsedAt,
+	}, nil
+}
+
+func (f *memStorage) TouchAPIKey(ctx context.Context, keyID string) error {
+	f.touches++
+	return nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+// Validation messages of filter and song params, transports use them
+// for values they fail to parse
+const (
+	ISODateMessage = "Invalid date format. Use ISO 8601 (YYYY-MM-DD), (YYYY-MM) or (YYYY)"
+	DateMessage    = "Invalid date format. Use (DD.MM.YYYY), (YYYY-MM-DD), (MM.YYYY) or (YYYY)"
+	YearMessage    = "year must be a number from 1 to 9999: example 2006"
+	DecadeMessage  = "decade must be the first year of the decade: example 1990"
+)
+
+// FilterNames are names of filter params in transport, they are reported in validation errors
+type FilterNames struct {
+	ReleaseDate  string
+	ReleasedFrom string
+	ReleasedTo   string
+	Year         string
+	Decade       string
+}
+
+// SongFilterParams is song list filter as received by transport, NewSongFilter checks it.
+// With ISO dates are accepted only in ISO 8601 form like in api v2,
+// otherwise releaseDate is DD.MM.YYYY and ranges take any dateparse.Parse format
+type SongFilterParams struct {
+	GroupName    *string
+	SongName     *string
+	SongText     *string
+	ReleaseDate  *string
+	UpdatedSince *time.Time
+	ReleasedFrom *string
+	ReleasedTo   *string
+	Year         *int
+	Decade       *int
+	ISO          bool
+	Names        FilterNames
+}
+
+// NewSongFilter validates filter params shared by all transports
+func NewSongFilter(params SongFilterParams) (SongFilter, error) {
+	filter := SongFilter{
+		GroupName:    params.GroupName,
+		SongName:     params.SongName,
+		SongText:     params.SongText,
+		UpdatedSince: params.UpdatedSince,
+	}
+	names := params.Names
+
+	parseDate, dateMessage, dayLayout := dateparse.Parse, DateMessage, "02.01.2006"
+	if params.ISO {
+		parseDate, dateMessage, dayLayout = dateparse.ParseISO, ISODateMessage, time.DateOnly
+	}
+
+	if params.ReleaseDate != nil {
+		rd, err := time.Parse(dayLayout, *params.ReleaseDate)
+		if err != nil {
+			example := time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC).Format(dayLayout)
+			return filter, invalidFilter(names.ReleaseDate, fmt.Sprintf("wrong format %s: example %s", names.ReleaseDate, example))
+		}
+		filter.ReleaseDate = &rd
+	}
+
+	if params.ReleasedFrom != nil {
+		from, err := parseDate(*params.ReleasedFrom)
+		if err != nil {
+			return filter, invalidFilter(names.ReleasedFrom, dateMessage)
+		}
+		filter.ReleasedFrom = &from
+	}
+
+	if params.ReleasedTo != nil {
+		to, err := parseDate(*params.ReleasedTo)
+		if err != nil {
+			return filter, invalidFilter(names.ReleasedTo, dateMessage)
+		}
+		if filter.ReleasedFrom != nil && !to.End().After(filter.ReleasedFrom.Time) {
+			return filter, invalidFilter(names.ReleasedTo,
+				fmt.Sprintf("%s must not be earlier than %s", names.ReleasedTo, names.ReleasedFrom))
+		}
+		filter.ReleasedTo = &to
+	}
+
+	if params.Year != nil {
+		if *params.Year < 1 || *params.Year > 9999 {
+			return filter, invalidFilter(names.Year, YearMessage)
+		}
+		filter.Year = params.Year
+	}
+
+	if params.Decade != nil {
+		decade := *params.Decade
+		if decade < 0 || decade > 9990 || decade%10 != 0 {
+			return filter, invalidFilter(names.Decade, DecadeMessage)
+		}
+		filter.Decade = params.Decade
+	}
+
+	return filter, nil
+}
+
+func invalidFilter(field, message string) error {
+	return problem.Validation(problem.FieldError{Field: field, Message: message})
+}
+
+// OptionalInt converts optional int32 of grpc and graphql inputs
+func OptionalInt(value *int32) *int {
+	if value == nil {
+		return nil
+	}
+	v := int(*value)
+	return &v
+}
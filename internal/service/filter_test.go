@@ -0,0 +1,71 @@
+package service_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+var names = service.FilterNames{
+	ReleaseDate:  "releaseDate",
+	ReleasedFrom: "releasedFrom",
+	ReleasedTo:   "releasedTo",
+	Year:         "year",
+	Decade:       "decade",
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestNewSongFilterInvalid(t *testing.T) {
+	cases := []struct {
+		name   string
+		params service.SongFilterParams
+		field  string
+	}{
+		{name: "day release date in iso", params: service.SongFilterParams{ISO: true, ReleaseDate: ptr("16.07.2006")}, field: "releaseDate"},
+		{name: "iso release date in v1", params: service.SongFilterParams{ReleaseDate: ptr("2006-07-16")}, field: "releaseDate"},
+		{name: "released from", params: service.SongFilterParams{ISO: true, ReleasedFrom: ptr("July 2006")}, field: "releasedFrom"},
+		{name: "reversed range", params: service.SongFilterParams{ReleasedFrom: ptr("2009"), ReleasedTo: ptr("2006")}, field: "releasedTo"},
+		{name: "year", params: service.SongFilterParams{Year: ptr(0)}, field: "year"},
+		{name: "decade", params: service.SongFilterParams{Decade: ptr(1995)}, field: "decade"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.params.Names = names
+			_, err := service.NewSongFilter(tc.params)
+
+			var appErr *problem.Error
+			if !errors.As(err, &appErr) || appErr.Code != problem.CodeValidation {
+				t.Fatalf("err = %v, want validation error", err)
+			}
+			if len(appErr.Fields) != 1 || appErr.Fields[0].Field != tc.field {
+				t.Errorf("fields %+v, want %s", appErr.Fields, tc.field)
+			}
+		})
+	}
+}
+
+func TestNewSongFilter(t *testing.T) {
+	filter, err := service.NewSongFilter(service.SongFilterParams{
+		GroupName:    ptr("Muse"),
+		ReleaseDate:  ptr("2006-07-16"),
+		ReleasedFrom: ptr("2006"),
+		ReleasedTo:   ptr("2006-12"),
+		Year:         ptr(2006),
+		Decade:       ptr(2000),
+		ISO:          true,
+		Names:        names,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *filter.GroupName != "Muse" || filter.ReleaseDate.Day() != 16 || filter.ReleasedFrom.ISO() != "2006" ||
+		filter.ReleasedTo.ISO() != "2006-12" || *filter.Year != 2006 || *filter.Decade != 2000 {
+		t.Errorf("filter %+v", filter)
+	}
+}
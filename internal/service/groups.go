@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+)
+
+type FetchGroupsRequest struct {
+	Name   *string `json:"name,omitempty"`
+	Limit  uint64  `json:"limit"`
+	Offset uint64  `json:"offset"`
+}
+
+func (s *Service) FetchGroups(ctx context.Context, request FetchGroupsRequest) ([]Group, error) {
+	if s.debug {
+		s.log.Info("service.FetchGroups | request data", "request", request)
+	}
+
+	groups, err := s.storage.GetGroups(ctx, database.GetGroupsRequest{
+		Name:   request.Name,
+		Limit:  request.Limit,
+		Offset: request.Offset,
+	})
+	if err != nil {
+		s.log.Error("service.FetchGroups | GetGroups", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, Group(group))
+	}
+
+	return result, nil
+}
+
+// FetchGroupsByName returns groups with exact names in one request,
+// unknown names are skipped
+func (s *Service) FetchGroupsByName(ctx context.Context, names []string) ([]Group, error) {
+	groups, err := s.storage.GetGroupsByName(ctx, names)
+	if err != nil {
+		s.log.Error("service.FetchGroupsByName | GetGroupsByName", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make([]Group, 0, len(groups))
+	for _, group := range groups {
+		result = append(result, Group(group))
+	}
+
+	return result, nil
+}
+
+// FetchGroupsSongs returns songs of several groups in one request keyed by group id,
+// first > 0 limits songs of each group
+func (s *Service) FetchGroupsSongs(ctx context.Context, groupIDs []int, first int) (map[int][]Song, error) {
+	songs, err := s.storage.GetGroupsSongs(ctx, groupIDs, first)
+	if err != nil {
+		s.log.Error("service.FetchGroupsSongs | GetGroupsSongs", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make(map[int][]Song, len(songs))
+	for groupID, items := range songs {
+		for _, item := range items {
+			result[groupID] = append(result[groupID], songFromStorage(item))
+		}
+	}
+
+	return result, nil
+}
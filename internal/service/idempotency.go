@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+const (
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	//idempotencyLease keeps key of request in progress, key of crashed request is freed after it
+	idempotencyLease   = time.Minute
+	idempotencyCleanup = time.Hour
+)
+
+var (
+	ErrIdempotencyKeyReused = problem.New(problem.CodeIdempotencyReused, "idempotency key was already used with another request")
+	ErrIdempotencyKeyBusy   = problem.New(problem.CodeIdempotencyBusy, "request with this idempotency key is in progress")
+)
+
+// IdempotencyRequest identifies request sent with Idempotency-Key header,
+// Fingerprint is hash of request method, path, query and body.
+// Owner is unique per execution of request, only it stores response or releases the key,
+// so request which lost the key after its lease expired does not change key of retry
+type IdempotencyRequest struct {
+	Scope       string `json:"scope"`
+	Key         string `json:"key"`
+	Fingerprint string `json:"fingerprint"`
+	Owner       string `json:"owner"`
+}
+
+// IdempotentResponse is stored response replayed for retries with the same key
+type IdempotentResponse struct {
+	Status int               `json:"status"`
+	Header map[string]string `json:"header,omitempty"`
+	Body   []byte            `json:"body"`
+}
+
+// StartIdempotent reserves key before request is executed. For key of completed request
+// stored response is returned and request must not be executed again.
+// Key used with another fingerprint or by request in progress is answered with conflict
+func (s *Service) StartIdempotent(ctx context.Context, request IdempotencyRequest) (*IdempotentResponse, error) {
+	key, reserved, err := s.storage.ReserveIdempotencyKey(ctx, database.ReserveIdempotencyKeyRequest{
+		Scope:       request.Scope,
+		Key:         request.Key,
+		Fingerprint: request.Fingerprint,
+		Owner:       request.Owner,
+		Lease:       idempotencyLease,
+	})
+	if err != nil {
+		s.log.Error("service.StartIdempotent | ReserveIdempotencyKey", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	if key.Fingerprint != request.Fingerprint {
+		s.log.Warn("service.StartIdempotent | key reused with another request", "key", request.Key, "scope", request.Scope)
+		return nil, ErrIdempotencyKeyReused
+	}
+	if key.Status == nil {
+		return nil, ErrIdempotencyKeyBusy
+	}
+
+	if s.debug {
+		s.log.Info("service.StartIdempotent | replay response", "key", request.Key, "status", *key.Status)
+	}
+
+	return &IdempotentResponse{
+		Status: *key.Status,
+		Header: key.Header,
+		Body:   key.Body,
+	}, nil
+}
+
+// FinishIdempotent stores response of reserved key for idempotency TTL. Server failures are not stored,
+// key is released so retry executes request again
+func (s *Service) FinishIdempotent(ctx context.Context, request IdempotencyRequest, response IdempotentResponse) error {
+	if response.Status >= http.StatusInternalServerError {
+		return s.ReleaseIdempotent(ctx, request)
+	}
+
+	err := s.storage.SaveIdempotentResponse(ctx, database.SaveIdempotentResponseRequest{
+		Scope:  request.Scope,
+		Key:    request.Key,
+		Owner:  request.Owner,
+		Status: response.Status,
+		Header: response.Header,
+		Body:   response.Body,
+		TTL:    s.idempotencyTTL,
+	})
+	if err != nil {
+		s.log.Error("service.FinishIdempotent | SaveIdempotentResponse", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			//key expired and was removed or taken over by retry while request was executed
+			s.log.Warn("service.FinishIdempotent | idempotency key lease lost", "key", request.Key, "scope", request.Scope)
+			return nil
+		default:
+			return ErrRequest
+		}
+	}
+	return nil
+}
+
+// ReleaseIdempotent deletes reserved key of request which was not completed,
+// retry with the key executes request again
+func (s *Service) ReleaseIdempotent(ctx context.Context, request IdempotencyRequest) error {
+	if err := s.storage.DeleteIdempotencyKey(ctx, request.Scope, request.Key, request.Owner); err != nil {
+		s.log.Error("service.ReleaseIdempotent | DeleteIdempotencyKey", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		default:
+			return ErrRequest
+		}
+	}
+	return nil
+}
+
+// StartIdempotencyCleanup deletes expired idempotency keys every hour
+func (s *Service) StartIdempotencyCleanup(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(idempotencyCleanup)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := s.storage.DeleteExpiredIdempotencyKeys(ctx, time.Now())
+				if err != nil {
+					s.log.Error("service.StartIdempotencyCleanup | DeleteExpiredIdempotencyKeys", "error", err.Error())
+					continue
+				}
+				if s.debug {
+					s.log.Info("service.StartIdempotencyCleanup | keys deleted", "count", deleted)
+				}
+			}
+		}
+	}()
+
+	s.log.Info("service.StartIdempotencyCleanup | idempotency keys cleanup scheduled", "ttl", s.idempotencyTTL.String())
+}
@@ -0,0 +1,383 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+const (
+	FormatNDJSON = "ndjson"
+	FormatCSV    = "csv"
+
+	ImportStatusCreated   = "created"
+	ImportStatusDuplicate = "duplicate"
+	ImportStatusInvalid   = "invalid"
+	ImportStatusFailed    = "failed"
+
+	defaultImportBatch = 100
+	maxImportBatch     = 1000
+	maxImportLine      = 4 * 1024 * 1024
+)
+
+var (
+	ErrUnknownFormat = problem.Validation(problem.FieldError{Field: "format", Message: "unknown format, use ndjson or csv"})
+)
+
+// ImportRecord is one song in import file, csv columns have the same names
+type ImportRecord struct {
+	GroupName   string   `json:"group"`
+	SongName    string   `json:"song"`
+	ReleaseDate string   `json:"releaseDate"`
+	Link        string   `json:"link"`
+	Text        string   `json:"text"`
+	Verses      []string `json:"verses"`
+}
+
+type ImportRowReport struct {
+	Row       int                  `json:"row"`
+	Status    string               `json:"status"`
+	SongID    int64                `json:"song_id,omitempty"`
+	GroupName string               `json:"group,omitempty"`
+	SongName  string               `json:"song,omitempty"`
+	Reason    string               `json:"reason,omitempty"`
+	Errors    []problem.FieldError `json:"errors,omitempty"`
+}
+
+type ImportSongsRequest struct {
+	Reader    io.Reader `json:"-"`
+	Format    string    `json:"format"`
+	DryRun    bool      `json:"dry_run"`
+	BatchSize int       `json:"batch_size"`
+}
+
+type ImportSongsResponse struct {
+	Total     int  `json:"total"`
+	Created   int  `json:"created"`
+	Duplicate int  `json:"duplicate"`
+	Invalid   int  `json:"invalid"`
+	Failed    int  `json:"failed"`
+	DryRun    bool `json:"dry_run"`
+}
+
+// importRow is read row waiting for batch insert
+type importRow struct {
+	report  ImportRowReport
+	request *database.AddSongRequest
+}
+
+// ImportSongs reads songs from ndjson or csv stream and inserts them in batches.
+// report is called for every row in file order, the whole file is never kept in memory.
+// With DryRun batches are rolled back, group and song names of rows passed so far are kept
+// to report duplicates across batches like the real import does
+func (s *Service) ImportSongs(ctx context.Context, request ImportSongsRequest, report func(ImportRowReport) error) (*ImportSongsResponse, error) {
+	if s.debug {
+		s.log.Info("service.ImportSongs | request data", "format", request.Format, "dry_run", request.DryRun)
+	}
+
+	next, err := newRecordReader(request.Format, request.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.BatchSize <= 0 {
+		request.BatchSize = defaultImportBatch
+	}
+	if request.BatchSize > maxImportBatch {
+		request.BatchSize = maxImportBatch
+	}
+
+	result := ImportSongsResponse{DryRun: request.DryRun}
+	batch := make([]importRow, 0, request.BatchSize)
+	valid := 0
+
+	var imported map[songKey]struct{}
+	if request.DryRun {
+		imported = make(map[songKey]struct{})
+	}
+
+	flush := func() error {
+		if err := s.importBatch(ctx, batch, imported); err != nil {
+			return err
+		}
+		for _, row := range batch {
+			switch row.report.Status {
+			case ImportStatusCreated:
+				result.Created++
+			case ImportStatusDuplicate:
+				result.Duplicate++
+			case ImportStatusInvalid:
+				result.Invalid++
+			default:
+				result.Failed++
+			}
+			if err := report(row.report); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		valid = 0
+		return nil
+	}
+
+	for rowNum := 1; ; rowNum++ {
+		record, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var rowErr *importRowError
+		switch {
+		case errors.As(err, &rowErr):
+			batch = append(batch, importRow{report: ImportRowReport{
+				Row:    rowNum,
+				Status: ImportStatusInvalid,
+				Reason: rowErr.Error(),
+			}})
+		case err != nil:
+			s.log.Error("service.ImportSongs | read row", "row", rowNum, "error", err.Error())
+			return &result, fmt.Errorf("%w: row %d: %s", problem.ErrBadRequest, rowNum, err.Error())
+		default:
+			batch = append(batch, buildImportRow(rowNum, record))
+			if batch[len(batch)-1].request != nil {
+				valid++
+			}
+		}
+		result.Total++
+
+		if valid >= request.BatchSize || len(batch) >= maxImportBatch {
+			if err := flush(); err != nil {
+				return &result, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return &result, err
+	}
+
+	s.log.Info("service.ImportSongs | finished", "result", result)
+
+	return &result, nil
+}
+
+func buildImportRow(rowNum int, record ImportRecord) importRow {
+	row := importRow{report: ImportRowReport{
+		Row:       rowNum,
+		GroupName: record.GroupName,
+		SongName:  record.SongName,
+	}}
+
+	createRequest := CreateSongRequest{
+		GroupName: record.GroupName,
+		SongName:  record.SongName,
+		Link:      record.Link,
+		Text:      record.Text,
+		Verses:    record.Verses,
+	}
+
+	if record.ReleaseDate != "" {
+		releaseDate, err := dateparse.Parse(record.ReleaseDate)
+		if err != nil {
+			row.report.Status = ImportStatusInvalid
+			row.report.Reason = ErrBadDataFormat.Error()
+			row.report.Errors = []problem.FieldError{{Field: "releaseDate", Message: err.Error()}}
+			return row
+		}
+		createRequest.ReleaseDate = &releaseDate
+	}
+
+	addRequest, err := buildAddSongRequest(createRequest)
+	if err != nil {
+		row.report.Status = ImportStatusInvalid
+		row.report.Reason = err.Error()
+		var appErr *problem.Error
+		if errors.As(err, &appErr) {
+			row.report.Errors = appErr.Fields
+		}
+		return row
+	}
+
+	row.request = &addRequest
+	return row
+}
+
+// songKey is unique name of song in storage
+type songKey struct {
+	group string
+	song  string
+}
+
+// importBatch inserts valid rows of batch and fills their reports.
+// Non nil imported means dry run: batch is rolled back, rows already seen in imported
+// are duplicates, and rows passed in this batch are added to it
+func (s *Service) importBatch(ctx context.Context, batch []importRow, imported map[songKey]struct{}) error {
+	dryRun := imported != nil
+
+	var requests []database.AddSongRequest
+	var idx []int
+	for i, row := range batch {
+		if row.request == nil {
+			continue
+		}
+		if dryRun {
+			key := songKey{group: row.request.GroupName, song: row.request.SongName}
+			if _, ok := imported[key]; ok {
+				batch[i].report.Status = ImportStatusDuplicate
+				batch[i].report.Reason = ErrSongExist.Error()
+				continue
+			}
+		}
+		requests = append(requests, *row.request)
+		idx = append(idx, i)
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	results, err := s.storage.ImportSongs(ctx, requests, dryRun)
+	if err != nil {
+		s.log.Error("service.ImportSongs | ImportSongs", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		default:
+			return ErrRequest
+		}
+	}
+
+	for i, res := range results {
+		rowReport := &batch[idx[i]].report
+		switch {
+		case res.Err == nil:
+			rowReport.Status = ImportStatusCreated
+			if dryRun {
+				imported[songKey{group: requests[i].GroupName, song: requests[i].SongName}] = struct{}{}
+			} else {
+				rowReport.SongID = res.SongID
+			}
+		case errors.Is(res.Err, database.ErrDuplicateKey):
+			rowReport.Status = ImportStatusDuplicate
+			rowReport.Reason = ErrSongExist.Error()
+		default:
+			s.log.Error("service.ImportSongs | insert row", "row", rowReport.Row, "error", res.Err.Error())
+			rowReport.Status = ImportStatusFailed
+			rowReport.Reason = ErrRequest.Error()
+		}
+	}
+
+	if !dryRun {
+		s.wakeRelay()
+	}
+	return nil
+}
+
+// importRowError is bad row, import continues with next row
+type importRowError struct {
+	reason string
+}
+
+func (e *importRowError) Error() string {
+	return e.reason
+}
+
+// newRecordReader returns function reading next record, io.EOF at the end
+func newRecordReader(format string, r io.Reader) (func() (ImportRecord, error), error) {
+	switch strings.ToLower(format) {
+	case FormatNDJSON:
+		return ndjsonReader(r), nil
+	case FormatCSV:
+		return csvReader(r), nil
+	default:
+		return nil, ErrUnknownFormat
+	}
+}
+
+func ndjsonReader(r io.Reader) func() (ImportRecord, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLine)
+
+	return func() (ImportRecord, error) {
+		var record ImportRecord
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return record, err
+			}
+			return record, io.EOF
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			return record, &importRowError{reason: "empty line"}
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return record, &importRowError{reason: fmt.Sprintf("invalid json: %s", err.Error())}
+		}
+		return record, nil
+	}
+}
+
+func csvReader(r io.Reader) func() (ImportRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+	var columns map[string]int
+
+	column := func(values []string, name string) string {
+		if idx, ok := columns[name]; ok && idx < len(values) {
+			return values[idx]
+		}
+		return ""
+	}
+
+	return func() (ImportRecord, error) {
+		var record ImportRecord
+
+		if columns == nil {
+			header, err := reader.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return record, io.EOF
+				}
+				return record, fmt.Errorf("read csv header: %w", err)
+			}
+			columns = make(map[string]int, len(header))
+			for idx, name := range header {
+				columns[strings.ToLower(strings.TrimSpace(name))] = idx
+			}
+			for _, required := range []string{"group", "song"} {
+				if _, ok := columns[required]; !ok {
+					return record, fmt.Errorf("csv header has no %q column", required)
+				}
+			}
+		}
+
+		values, err := reader.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return record, io.EOF
+			}
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return record, &importRowError{reason: parseErr.Error()}
+			}
+			return record, err
+		}
+
+		record.GroupName = column(values, "group")
+		record.SongName = column(values, "song")
+		record.ReleaseDate = column(values, "releasedate")
+		record.Link = column(values, "link")
+		record.Text = column(values, "text")
+		return record, nil
+	}
+}
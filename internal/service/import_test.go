@@ -0,0 +1,55 @@
+package service_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/service"
+)
+
+// TestImportDuplicatesAcrossBatches checks that dry run reports the same duplicates as real import
+// when repeated song is in another batch
+func TestImportDuplicatesAcrossBatches(t *testing.T) {
+	const file = `{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom"}
+{"group":"Muse","song":"Starlight","text":"Paranoia is in bloom"}
+{"group":"Muse","song":"Uprising","text":"Paranoia is in bloom"}
+{"group":"Muse","song":"uprising","text":"Paranoia is in bloom"}
+`
+
+	for _, dryRun := range []bool{false, true} {
+		storage := &memStorage{imported: make(map[string]bool)}
+		s := service.New(storage, nil, nil, nil, 0, logger.New(), false)
+
+		var statuses []string
+		result, err := s.ImportSongs(context.Background(), service.ImportSongsRequest{
+			Reader:    strings.NewReader(file),
+			Format:    service.FormatNDJSON,
+			DryRun:    dryRun,
+			BatchSize: 1,
+		}, func(row service.ImportRowReport) error {
+			statuses = append(statuses, row.Status)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("dry_run=%v: unexpected error: %v", dryRun, err)
+		}
+
+		want := []string{
+			service.ImportStatusCreated,
+			service.ImportStatusCreated,
+			service.ImportStatusDuplicate,
+			service.ImportStatusCreated,
+		}
+		if strings.Join(statuses, ",") != strings.Join(want, ",") {
+			t.Errorf("dry_run=%v: statuses %v, want %v", dryRun, statuses, want)
+		}
+		if result.Created != 3 || result.Duplicate != 1 {
+			t.Errorf("dry_run=%v: summary %+v, want 3 created and 1 duplicate", dryRun, result)
+		}
+		if dryRun && len(storage.imported) != 0 {
+			t.Errorf("dry run saved %d songs", len(storage.imported))
+		}
+	}
+}
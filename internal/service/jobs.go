@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+const (
+	JobKindNewSong = "new_song"
+
+	jobTimeout = 2 * time.Minute
+	//jobLease outlives jobTimeout, so a job is reclaimed only when its worker is gone
+	jobLease      = jobTimeout + time.Minute
+	finishTimeout = 5 * time.Second
+)
+
+var (
+	ErrJobNotFound = problem.New(problem.CodeJobNotFound, "job is not found")
+)
+
+// NewSongAsync stores NewSong request as a job, it is executed by workers started with StartWorkers
+func (s *Service) NewSongAsync(ctx context.Context, request NewSongRequest) (*Job, error) {
+	if s.debug {
+		s.log.Info("service.NewSongAsync | request data", "request", request)
+	}
+
+	payload, err := json.Marshal(request)
+	if err != nil {
+		s.log.Error("service.NewSongAsync | Marshal", "error", err.Error())
+		return nil, ErrRequest
+	}
+
+	jobID, err := s.storage.CreateJob(ctx, database.CreateJobRequest{
+		Kind:    JobKindNewSong,
+		Payload: payload,
+	})
+	if err != nil {
+		s.log.Error("service.NewSongAsync | CreateJob", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	//wake up idle worker
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	job, err := s.FetchJob(ctx, FetchJobRequest{JobID: jobID})
+	if err != nil {
+		return nil, err
+	}
+
+	if s.debug {
+		s.log.Info("service.NewSongAsync | response data", "job", job)
+	}
+
+	return job, nil
+}
+
+type FetchJobRequest struct {
+	JobID int64 `json:"job_id"`
+}
+
+func (s *Service) FetchJob(ctx context.Context, request FetchJobRequest) (*Job, error) {
+	if s.debug {
+		s.log.Info("service.FetchJob | request data", "request", request)
+	}
+
+	dbJob, err := s.storage.GetJob(ctx, request.JobID)
+	if err != nil {
+		s.log.Error("service.FetchJob | GetJob", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrJobNotFound
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	job := Job{
+		ID:        dbJob.JobID,
+		Status:    dbJob.Status,
+		CreatedAt: dbJob.CreatedAt,
+		UpdatedAt: dbJob.UpdatedAt,
+	}
+	if dbJob.Error != nil {
+		job.Error = *dbJob.Error
+	}
+
+	if dbJob.SongID != nil {
+		song, err := s.storage.GetSong(ctx, *dbJob.SongID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			s.log.Error("service.FetchJob | GetSong", "error", err.Error())
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				return nil, ErrTimeOut
+			default:
+				return nil, ErrRequest
+			}
+		}
+		if song != nil {
+			jobSong := songFromStorage(*song)
+			job.Song = &jobSong
+		}
+	}
+
+	if s.debug {
+		s.log.Info("service.FetchJob | response data", "job", job)
+	}
+
+	return &job, nil
+}
+
+// StartWorkers starts pool of workers executing song creation jobs until ctx is done.
+// Jobs of a dead process are claimed again once their lease expires
+func (s *Service) StartWorkers(ctx context.Context, workers int, pollInterval time.Duration) error {
+	for i := 0; i < workers; i++ {
+		go s.worker(ctx, i, pollInterval)
+	}
+
+	s.log.Info("service.StartWorkers | workers started", "workers", workers)
+	return nil
+}
+
+func (s *Service) worker(ctx context.Context, num int, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := s.storage.ClaimJob(ctx, JobKindNewSong, jobLease)
+		if err == nil {
+			s.runJob(ctx, num, job)
+			continue
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.log.Error("service.worker | ClaimJob", "worker", num, "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) runJob(ctx context.Context, num int, job *database.Job) {
+	if s.debug {
+		s.log.Info("service.runJob | start", "worker", num, "job_id", job.JobID)
+	}
+
+	finish := database.FinishJobRequest{JobID: job.JobID, Attempt: job.Attempt, Status: database.JobStatusDone}
+
+	var request NewSongRequest
+	if err := json.Unmarshal(job.Payload, &request); err != nil {
+		reason := fmt.Sprintf("bad job payload: %s", err.Error())
+		finish.Status = database.JobStatusFailed
+		finish.Error = &reason
+	} else {
+		jobCtx, cancel := context.WithTimeout(ctx, jobTimeout)
+		song, err := s.NewSong(jobCtx, request)
+		cancel()
+		if err != nil && ctx.Err() != nil {
+			//job is interrupted by shutdown, not failed
+			s.releaseJob(num, job)
+			return
+		}
+		if err != nil {
+			reason := err.Error()
+			finish.Status = database.JobStatusFailed
+			finish.Error = &reason
+		} else {
+			finish.SongID = &song.ID
+		}
+	}
+
+	//result is stored even when process is stopping, ctx may be already canceled
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	if err := s.storage.FinishJob(storeCtx, finish); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			//lease expired and job was claimed again, result belongs to the new attempt
+			s.log.Warn("service.runJob | job lease lost", "worker", num, "job_id", job.JobID, "attempt", job.Attempt)
+			return
+		}
+		s.log.Error("service.runJob | FinishJob", "worker", num, "job_id", job.JobID, "error", err.Error())
+		return
+	}
+
+	if s.debug {
+		s.log.Info("service.runJob | finish", "worker", num, "job_id", job.JobID, "status", finish.Status)
+	}
+}
+
+// releaseJob returns job to the queue, it is claimed by next running worker
+func (s *Service) releaseJob(num int, job *database.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if err := s.storage.ReleaseJob(ctx, job.JobID, job.Attempt); err != nil {
+		s.log.Error("service.releaseJob | ReleaseJob", "worker", num, "job_id", job.JobID, "error", err.Error())
+	}
+}
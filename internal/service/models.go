@@ -1,16 +1,169 @@
 package service
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+)
+
+type Group struct {
+	ID   int    `json:"id" example:"1"`
+	Name string `json:"name" example:"Muse"`
+}
 
 type Song struct {
-	ID          int       `json:"id" example:"1"`
-	GroupName   string    `json:"group_name" example:"Muse"`
-	SongName    string    `json:"song_name" example:"Supermassive Black Hole"`
-	ReleaseDate time.Time `json:"release_date" example:"1987-07-03T00:00:00Z"`
-	Link        string    `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	ID                   int          `json:"id" example:"1"`
+	GroupName            string       `json:"group_name" example:"Muse"`
+	SongName             string       `json:"song_name" example:"Supermassive Black Hole"`
+	ReleaseDate          *time.Time   `json:"release_date" example:"1987-07-03T00:00:00Z" extensions:"x-nullable"`
+	ReleaseDatePrecision string       `json:"release_date_precision,omitempty" example:"day"`
+	Link                 string       `json:"link" example:"https://www.youtube.com/watch?v=Xsp3_a-PMTw"`
+	Version              int          `json:"version" example:"1"`
+	CreatedAt            time.Time    `json:"created_at" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt            time.Time    `json:"updated_at" example:"2024-07-03T10:00:00Z"`
+	Verses               []VerseSmall `json:"verses,omitempty"`
+	Links                *SongLinks   `json:"links,omitempty"`
+}
+
+// SongLinks are hypermedia links of song list item
+type SongLinks struct {
+	Lyrics string `json:"lyrics" example:"/api/v1/songs/1"`
+}
+
+// Links are pagination links of list response, the same links are sent in Link header (RFC 8288)
+type Links struct {
+	Self  string `json:"self" example:"/api/v1/songs?limit=10&offset=10"`
+	First string `json:"first" example:"/api/v1/songs?limit=10"`
+	Prev  string `json:"prev,omitempty" example:"/api/v1/songs?limit=10"`
+	Next  string `json:"next,omitempty" example:"/api/v1/songs?limit=10&offset=20"`
+	Last  string `json:"last,omitempty" example:"/api/v1/songs?limit=10&offset=40"`
+}
+
+func songFromStorage(item database.Song) Song {
+	return Song{
+		ID:                   item.SongID,
+		GroupName:            item.GroupName,
+		SongName:             item.SongName,
+		ReleaseDate:          item.ReleaseDate,
+		ReleaseDatePrecision: item.ReleaseDatePrecision,
+		Link:                 item.Link,
+		Version:              item.Version,
+		CreatedAt:            item.CreatedAt,
+		UpdatedAt:            item.UpdatedAt,
+	}
+}
+
+func verseFromStorage(item database.VerseSmall) VerseSmall {
+	return VerseSmall{
+		VerseNumber: item.VerseNumber,
+		VerseText:   item.VerseText,
+		Version:     item.Version,
+		CreatedAt:   item.CreatedAt,
+		UpdatedAt:   item.UpdatedAt,
+	}
 }
 
 type VerseSmall struct {
-	VerseNumber int    `json:"verse_number"`
-	VerseText   string `json:"verse_text"`
+	VerseNumber int       `json:"verse_number"`
+	VerseText   string    `json:"verse_text"`
+	Version     int       `json:"version,omitempty" example:"1"`
+	CreatedAt   time.Time `json:"created_at" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt   time.Time `json:"updated_at" example:"2024-07-03T10:00:00Z"`
+}
+
+type Job struct {
+	ID        int64     `json:"id" example:"1"`
+	Status    string    `json:"status" example:"done"`
+	Error     string    `json:"error,omitempty" example:"song is not found"`
+	Song      *Song     `json:"song,omitempty"`
+	CreatedAt time.Time `json:"created_at" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt time.Time `json:"updated_at" example:"2024-07-03T10:00:05Z"`
+}
+
+// Webhook is subscription to library change events, Secret is returned only on creation
+type Webhook struct {
+	ID         int       `json:"id" example:"1"`
+	URL        string    `json:"url" example:"https://example.com/hooks/music"`
+	EventTypes []string  `json:"event_types" example:"song.created,song.deleted"`
+	Active     bool      `json:"active" example:"true"`
+	Secret     string    `json:"secret,omitempty" example:"4f1c2b..."`
+	CreatedAt  time.Time `json:"created_at" example:"2024-07-03T10:00:00Z"`
+}
+
+func webhookFromStorage(item database.Webhook) Webhook {
+	eventTypes := item.EventTypes
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return Webhook{
+		ID:         item.WebhookID,
+		URL:        item.URL,
+		EventTypes: eventTypes,
+		Active:     item.Active,
+		CreatedAt:  item.CreatedAt,
+	}
+}
+
+// APIKey is key of api client, Token is returned only on creation
+type APIKey struct {
+	ID         string     `json:"id" example:"9f86d081884c7d65"`
+	Name       string     `json:"name" example:"frontend"`
+	Scopes     []string   `json:"scopes" example:"read,write"`
+	Token      string     `json:"token,omitempty" example:"mlk_9f86d081884c7d65_..."`
+	CreatedAt  time.Time  `json:"created_at" example:"2024-07-03T10:00:00Z"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+func apiKeyFromStorage(item database.APIKey) APIKey {
+	scopes := item.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return APIKey{
+		ID:         item.KeyID,
+		Name:       item.Name,
+		Scopes:     scopes,
+		CreatedAt:  item.CreatedAt,
+		LastUsedAt: item.LastUsedAt,
+		RevokedAt:  item.RevokedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id" example:"1"`
+	WebhookID      int             `json:"webhook_id" example:"1"`
+	EventType      string          `json:"event_type" example:"song.updated"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"failed"`
+	Attempts       int             `json:"attempts" example:"8"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at" example:"2024-07-03T10:00:00Z"`
+	ResponseStatus *int            `json:"response_status,omitempty" example:"500"`
+	Error          string          `json:"error,omitempty" example:"webhook receiver answered 500: "`
+	ReplayOf       *int64          `json:"replay_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at" example:"2024-07-03T10:00:00Z"`
+	UpdatedAt      time.Time       `json:"updated_at" example:"2024-07-03T10:00:05Z"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
+
+func deliveryFromStorage(item database.WebhookDelivery) WebhookDelivery {
+	delivery := WebhookDelivery{
+		ID:             item.DeliveryID,
+		WebhookID:      item.WebhookID,
+		EventType:      item.EventType,
+		Payload:        item.Payload,
+		Status:         item.Status,
+		Attempts:       item.Attempts,
+		NextAttemptAt:  item.NextAttemptAt,
+		ResponseStatus: item.ResponseStatus,
+		ReplayOf:       item.ReplayOf,
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+		DeliveredAt:    item.DeliveredAt,
+	}
+	if item.Error != nil {
+		delivery.Error = *item.Error
+	}
+	return delivery
 }
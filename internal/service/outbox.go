@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/connector/sink"
+	"github.com/Vic07Region/musicLibrary/internal/database"
+)
+
+const (
+	outboxBatch       = 100
+	outboxBackoffBase = 5 * time.Second
+	outboxBackoffMax  = 10 * time.Minute
+	//outboxLease covers publishing of a claimed batch to all sinks
+	outboxLease = 5 * time.Minute
+)
+
+// wakeRelay is called after committed change to publish its event without waiting for poll
+func (s *Service) wakeRelay() {
+	select {
+	case s.relayWake <- struct{}{}:
+	default:
+	}
+}
+
+// StartOutboxRelay starts publishing of outbox events to sinks until ctx is done.
+// Event is marked published only when every sink accepted it, otherwise it is
+// retried with backoff and later events of the same song wait for it
+func (s *Service) StartOutboxRelay(ctx context.Context, pollInterval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+
+		for {
+			published, err := s.relayOutbox(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.log.Error("service.StartOutboxRelay | relayOutbox", "error", err.Error())
+			}
+			if s.debug && published > 0 {
+				s.log.Info("service.StartOutboxRelay | events published", "count", published)
+			}
+			//published event may unblock the next event of its song
+			if err == nil && published > 0 {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.relayWake:
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	names := make([]string, 0, len(s.sinks))
+	for _, out := range s.sinks {
+		names = append(names, out.Name())
+	}
+	s.log.Info("service.StartOutboxRelay | relay started", "sinks", names)
+}
+
+// relayOutbox claims a batch of events, publishes them without holding a transaction
+// and records results. Returns count of published events
+func (s *Service) relayOutbox(ctx context.Context) (int, error) {
+	events, err := s.storage.ClaimOutbox(ctx, database.ClaimOutboxRequest{Limit: outboxBatch, Lease: outboxLease})
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for i, event := range events {
+		if ctx.Err() != nil {
+			//relay is stopping, not published events are claimed by next relay
+			s.releaseOutbox(events[i:])
+			return published, ctx.Err()
+		}
+
+		finish := database.FinishOutboxRequest{OutboxID: event.OutboxID}
+		if err := s.publish(ctx, event); err != nil && ctx.Err() != nil {
+			s.releaseOutbox(events[i:])
+			return published, ctx.Err()
+		} else if err != nil {
+			reason := err.Error()
+			next := time.Now().Add(outboxBackoff(event.Attempts + 1))
+			finish.Error = &reason
+			finish.NextAttemptAt = &next
+		} else {
+			published++
+		}
+
+		if err := s.finishOutbox(ctx, finish); err != nil {
+			s.releaseOutbox(events[i+1:])
+			return published, err
+		}
+	}
+	return published, nil
+}
+
+// finishOutbox stores publish result even when process is stopping, ctx may be already canceled
+func (s *Service) finishOutbox(ctx context.Context, finish database.FinishOutboxRequest) error {
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	return s.storage.FinishOutbox(storeCtx, finish)
+}
+
+// releaseOutbox returns claimed events to the outbox, they are published by next running relay
+func (s *Service) releaseOutbox(events []database.OutboxEvent) {
+	if len(events) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	ids := make([]int64, 0, len(events))
+	for _, event := range events {
+		ids = append(ids, event.OutboxID)
+	}
+	if err := s.storage.ReleaseOutbox(ctx, ids); err != nil {
+		s.log.Error("service.releaseOutbox | ReleaseOutbox", "count", len(ids), "error", err.Error())
+	}
+}
+
+// publish sends outbox event to every sink, stops on the first failed sink
+func (s *Service) publish(ctx context.Context, item database.OutboxEvent) error {
+	var payload database.OutboxPayload
+	if err := json.Unmarshal(item.Payload, &payload); err != nil {
+		s.log.Error("service.publish | Unmarshal", "outbox_id", item.OutboxID, "error", err.Error())
+		return err
+	}
+
+	event := Event{
+		ID:         item.OutboxID,
+		Type:       item.EventType,
+		SongID:     item.SongID,
+		OccurredAt: item.CreatedAt.UTC(),
+	}
+	if payload.Song != nil {
+		song := songFromStorage(*payload.Song)
+		event.Song = &song
+	}
+	if payload.Verse != nil {
+		verse := verseFromStorage(*payload.Verse)
+		event.Verse = &verse
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		s.log.Error("service.publish | Marshal", "outbox_id", item.OutboxID, "error", err.Error())
+		return err
+	}
+
+	msg := sink.Message{ID: item.OutboxID, Type: item.EventType, SongID: item.SongID, Body: body}
+	for _, out := range s.sinks {
+		if err := out.Publish(ctx, msg); err != nil {
+			s.log.Warn("service.publish | sink failed",
+				"sink", out.Name(),
+				"outbox_id", item.OutboxID,
+				"attempt", item.Attempts+1,
+				"error", err.Error())
+			return fmt.Errorf("sink %s: %w", out.Name(), err)
+		}
+	}
+	return nil
+}
+
+// outboxBackoff returns delay before next publish: 5s doubled for every
+// failed attempt up to 10 minutes, events are retried until published
+func outboxBackoff(attempt int) time.Duration {
+	return retryBackoff(attempt, outboxBackoffBase, outboxBackoffMax)
+}
+
+// eventLogSink adds events to events log read by StreamEvents
+type eventLogSink struct {
+	s *Service
+}
+
+func (e *eventLogSink) Name() string {
+	return "events"
+}
+
+func (e *eventLogSink) Publish(ctx context.Context, msg sink.Message) error {
+	var event Event
+	if err := json.Unmarshal(msg.Body, &event); err != nil {
+		return err
+	}
+
+	var groupName *string
+	if event.Song != nil {
+		groupName = &event.Song.GroupName
+	}
+
+	_, err := e.s.storage.AddEvent(ctx, database.AddEventRequest{
+		OutboxID:  msg.ID,
+		EventType: msg.Type,
+		SongID:    msg.SongID,
+		GroupName: groupName,
+		Payload:   msg.Body,
+	})
+	if err != nil {
+		//event is already in log after previous publish
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	e.s.changes.notify()
+	return nil
+}
+
+// webhookSink queues webhook deliveries of events
+type webhookSink struct {
+	s *Service
+}
+
+func (w *webhookSink) Name() string {
+	return "webhooks"
+}
+
+func (w *webhookSink) Publish(ctx context.Context, msg sink.Message) error {
+	queued, err := w.s.storage.EnqueueWebhookDeliveries(ctx, database.EnqueueWebhookDeliveriesRequest{
+		OutboxID:  msg.ID,
+		EventType: msg.Type,
+		Payload:   msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	if queued > 0 {
+		//wake up idle webhook worker
+		select {
+		case w.s.hookWake <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
@@ -5,55 +5,171 @@ import (
 	"database/sql"
 	"errors"
 	"fmt" //nolint:gci
+	"github.com/Vic07Region/musicLibrary/internal/connector/sink"
 	"github.com/Vic07Region/musicLibrary/internal/connector/songinfo"
+	"github.com/Vic07Region/musicLibrary/internal/connector/webhook"
 	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
 	"github.com/Vic07Region/musicLibrary/internal/lib/logger"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+	"io"
 	"strings" //nolint:gci
 	"time"
 )
 
 var (
-	ErrSongNotFound  = fmt.Errorf("song is not found")
-	ErrGroupNotFound = fmt.Errorf("group is not found")
-	ErrNoSongs       = fmt.Errorf("there are no songs that meet the request")
-	ErrSongExist     = fmt.Errorf("A song with this group and name already exists")
-	ErrBadDataFormat = fmt.Errorf("wrong release date format")
-	ErrRequest       = fmt.Errorf("request execution error")
-	ErrTimeOut       = fmt.Errorf("request timeout exceeded")
+	ErrSongNotFound    = problem.New(problem.CodeSongNotFound, "song is not found")
+	ErrGroupNotFound   = problem.New(problem.CodeGroupNotFound, "group is not found")
+	ErrNoSongs         = problem.New(problem.CodeNoSongs, "there are no songs that meet the request")
+	ErrSongExist       = problem.New(problem.CodeSongExists, "A song with this group and name already exists")
+	ErrVersionMismatch = problem.New(problem.CodeVersionMismatch, "resource was modified, version does not match")
+	ErrBadDataFormat   = problem.New(problem.CodeBadReleaseDate, "wrong release date format")
+	ErrInfoProvider    = problem.New(problem.CodeProviderFailed, "song info provider error")
+	ErrRequest         = problem.New(problem.CodeStorageFailed, "request execution error")
+	ErrTimeOut         = problem.New(problem.CodeTimeout, "request timeout exceeded")
 )
 
+// VersionMismatchError is returned by updates with stale version,
+// it holds current representation of the song or verse
+type VersionMismatchError struct {
+	Song  *Song
+	Verse *VerseSmall
+}
+
+func (e *VersionMismatchError) Error() string {
+	return ErrVersionMismatch.Error()
+}
+
+func (e *VersionMismatchError) Unwrap() error {
+	return ErrVersionMismatch
+}
+
 type MusicService interface {
 	FetchSongs(ctx context.Context, request FetchSongsRequest) (*FetchSongsResponse, error)
 	FetchVerses(ctx context.Context, request FetchVersesRequest) (*FetchVersesResponse, error)
+	FetchSong(ctx context.Context, request FetchSongRequest) (*Song, error)
+	FetchSongsVerses(ctx context.Context, songIDs []int, first int) (map[int][]VerseSmall, error)
+	FetchGroups(ctx context.Context, request FetchGroupsRequest) ([]Group, error)
+	FetchGroupsByName(ctx context.Context, names []string) ([]Group, error)
+	FetchGroupsSongs(ctx context.Context, groupIDs []int, first int) (map[int][]Song, error)
 	DeleteSong(ctx context.Context, request DeleteSongRequest) (*DeleteSongResponse, error)
 	UpdateSong(ctx context.Context, request UpdateSongRequest) (UpdateSongResponse, error)
 	UpdateVerse(ctx context.Context, request UpdateVerseRequest) (UpdateVerseResponse, error)
+	Batch(ctx context.Context, request BatchRequest) (*BatchResponse, error)
 	NewSong(ctx context.Context, request NewSongRequest) (*Song, error)
+	CreateSong(ctx context.Context, request CreateSongRequest) (*Song, error)
+	ImportSongs(ctx context.Context, request ImportSongsRequest, report func(ImportRowReport) error) (*ImportSongsResponse, error)
+	ExportSongs(ctx context.Context, request ExportSongsRequest, w io.Writer) (int, error)
+	NewSongAsync(ctx context.Context, request NewSongRequest) (*Job, error)
+	FetchJob(ctx context.Context, request FetchJobRequest) (*Job, error)
+	CreateWebhook(ctx context.Context, request CreateWebhookRequest) (*Webhook, error)
+	FetchWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, request DeleteWebhookRequest) error
+	FetchWebhookDeliveries(ctx context.Context, request FetchWebhookDeliveriesRequest) ([]WebhookDelivery, error)
+	ReplayWebhookDelivery(ctx context.Context, request ReplayWebhookDeliveryRequest) (*WebhookDelivery, error)
+	StreamEvents(ctx context.Context, request StreamEventsRequest, stream EventStream) error
+	StartIdempotent(ctx context.Context, request IdempotencyRequest) (*IdempotentResponse, error)
+	FinishIdempotent(ctx context.Context, request IdempotencyRequest, response IdempotentResponse) error
+	ReleaseIdempotent(ctx context.Context, request IdempotencyRequest) error
+	Authenticate(ctx context.Context, token string) (*APIKey, error)
 }
 
 type Service struct {
-	storage database.Storage
-	songSrv songinfo.InfoSerice
-	log     *logger.Logger
-	debug   bool
+	storage   database.Storage
+	songSrv   songinfo.InfoSerice
+	hooks     webhook.Sender
+	sinks     []sink.Sink
+	log       *logger.Logger
+	debug     bool
+	wake      chan struct{}
+	hookWake  chan struct{}
+	relayWake chan struct{}
+	changes   *eventSignal
+
+	idempotencyTTL time.Duration
 }
 
-func New(s database.Storage, t songinfo.InfoSerice, log *logger.Logger, debug bool) *Service {
-	return &Service{storage: s, songSrv: t, log: log, debug: debug}
+// New creates service layer, change events are published to events log,
+// webhooks and given sinks. Responses of idempotent requests are kept for idempotencyTTL,
+// DefaultIdempotencyTTL is used when it is not positive
+func New(s database.Storage, t songinfo.InfoSerice, w webhook.Sender, sinks []sink.Sink, idempotencyTTL time.Duration,
+	log *logger.Logger, debug bool) *Service {
+	if idempotencyTTL <= 0 {
+		idempotencyTTL = DefaultIdempotencyTTL
+	}
+	srv := &Service{
+		storage:   s,
+		songSrv:   t,
+		hooks:     w,
+		log:       log,
+		debug:     debug,
+		wake:      make(chan struct{}, 1),
+		hookWake:  make(chan struct{}, 1),
+		relayWake: make(chan struct{}, 1),
+		changes:   newEventSignal(),
+
+		idempotencyTTL: idempotencyTTL,
+	}
+	srv.sinks = append([]sink.Sink{&eventLogSink{s: srv}, &webhookSink{s: srv}}, sinks...)
+	return srv
+}
+
+// SongFilter is song list filter shared by FetchSongs and ExportSongs
+type SongFilter struct {
+	GroupName    *string         `json:"group_name,omitempty" form:"group_name"`
+	SongName     *string         `json:"song_name,omitempty" form:"song_name"`
+	ReleaseDate  *time.Time      `json:"release_date,omitempty" form:"release_date"`
+	SongText     *string         `json:"song_text,omitempty" form:"song_text"`
+	UpdatedSince *time.Time      `json:"updated_since,omitempty" form:"updated_since"`
+	ReleasedFrom *dateparse.Date `json:"released_from,omitempty" form:"released_from"`
+	ReleasedTo   *dateparse.Date `json:"released_to,omitempty" form:"released_to"`
+	Year         *int            `json:"year,omitempty" form:"year"`
+	Decade       *int            `json:"decade,omitempty" form:"decade"`
+}
+
+func (f SongFilter) storage() database.SongFilter {
+	filter := database.SongFilter{
+		GroupName:    f.GroupName,
+		SongName:     f.SongName,
+		SongText:     f.SongText,
+		ReleaseDate:  f.ReleaseDate,
+		UpdatedSince: f.UpdatedSince,
+		Year:         f.Year,
+		Decade:       f.Decade,
+	}
+
+	if f.ReleasedFrom != nil {
+		filter.ReleasedFrom = &f.ReleasedFrom.Time
+	}
+
+	//released_to includes whole month or year when only they are known
+	if f.ReleasedTo != nil {
+		before := f.ReleasedTo.End()
+		filter.ReleasedBefore = &before
+	}
+
+	return filter
 }
 
 type FetchSongsRequest struct {
-	GroupName   *string    `json:"group_name,omitempty" form:"group_name"`
-	SongName    *string    `json:"song_name,omitempty" form:"song_name"`
-	ReleaseDate *time.Time `json:"release_date,omitempty" form:"release_date"`
-	SongText    *string    `json:"song_text,omitempty" form:"song_text"`
-	Limit       uint64     `json:"limit" form:"limit"`
-	Offset      uint64     `json:"offset" form:"offset"`
+	SongFilter
+	Sort          []SortKey `json:"sort,omitempty"`
+	Cursor        string    `json:"cursor,omitempty"`
+	IncludeVerses bool      `json:"include_verses,omitempty"`
+	Limit         uint64    `json:"limit" form:"limit"`
+	Offset        uint64    `json:"offset" form:"offset"`
 }
 
 type FetchSongsResponse struct {
 	Songs      []Song `json:"songs"`
 	TotalCount int    `json:"total_count"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Links      *Links `json:"links,omitempty"`
+}
+
+// SongsLimit returns page size of FetchSongs for requested limit
+func SongsLimit(limit uint64) int {
+	return int(database.SongsLimit(limit))
 }
 
 func (s *Service) FetchSongs(ctx context.Context, request FetchSongsRequest) (*FetchSongsResponse, error) {
@@ -61,14 +177,23 @@ func (s *Service) FetchSongs(ctx context.Context, request FetchSongsRequest) (*F
 		s.log.Info("service.FetchSongs | request data", "request", request)
 	}
 
-	songList, err := s.storage.GetSongs(ctx, database.GetSongsRequest{
-		GroupName:   request.GroupName,
-		SongName:    request.SongName,
-		SongText:    request.SongText,
-		ReleaseDate: request.ReleaseDate,
-		Limit:       request.Limit,
-		Offset:      request.Offset,
-	})
+	sort := sortStorage(request.Sort)
+	getParams := database.GetSongsRequest{
+		SongFilter: request.storage(),
+		Sort:       sort,
+		Limit:      request.Limit,
+		Offset:     request.Offset,
+	}
+
+	if request.Cursor != "" {
+		after, err := decodeCursor(request.Cursor, sort)
+		if err != nil {
+			return nil, err
+		}
+		getParams.After = after
+	}
+
+	songList, err := s.storage.GetSongs(ctx, getParams)
 
 	if err != nil {
 		s.log.Error("service.FetchSongs: GetSongs", "error", err.Error())
@@ -82,7 +207,7 @@ func (s *Service) FetchSongs(ctx context.Context, request FetchSongsRequest) (*F
 		}
 	}
 
-	totalCount, err := s.storage.CountSongs(ctx)
+	totalCount, err := s.storage.CountSongs(ctx, getParams.SongFilter)
 	if err != nil {
 		s.log.Error("service.FetchSongs: CountSongs", "error", err.Error())
 		switch {
@@ -96,23 +221,65 @@ func (s *Service) FetchSongs(ctx context.Context, request FetchSongsRequest) (*F
 	var songs []Song
 
 	for _, item := range songList {
-		songs = append(songs, Song{
-			ID:          item.SongID,
-			GroupName:   item.GroupName,
-			SongName:    item.SongName,
-			ReleaseDate: item.ReleaseDate,
-			Link:        item.Link,
-		})
+		songs = append(songs, songFromStorage(item))
+	}
+
+	if request.IncludeVerses && len(songs) > 0 {
+		songIDs := make([]int, 0, len(songs))
+		for _, song := range songs {
+			songIDs = append(songIDs, song.ID)
+		}
+
+		verses, err := s.FetchSongsVerses(ctx, songIDs, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range songs {
+			songs[i].Verses = verses[songs[i].ID]
+			if songs[i].Verses == nil {
+				songs[i].Verses = []VerseSmall{}
+			}
+		}
 	}
 
 	if s.debug {
 		s.log.Info("service.FetchSongs | response data", "songs", songs, "totalCount", totalCount)
 	}
 
-	return &FetchSongsResponse{
+	response := &FetchSongsResponse{
 		Songs:      songs,
 		TotalCount: totalCount,
-	}, err
+	}
+	if uint64(len(songList)) == database.SongsLimit(request.Limit) {
+		response.NextCursor = encodeCursor(sort, songList[len(songList)-1])
+	}
+
+	return response, err
+}
+
+// FetchSongsVerses returns verses of several songs in one request keyed by song id,
+// first > 0 limits verses of each song
+func (s *Service) FetchSongsVerses(ctx context.Context, songIDs []int, first int) (map[int][]VerseSmall, error) {
+	verses, err := s.storage.GetSongsVerses(ctx, songIDs, first)
+	if err != nil {
+		s.log.Error("service.FetchSongsVerses | GetSongsVerses", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make(map[int][]VerseSmall, len(verses))
+	for songID, items := range verses {
+		for _, v := range items {
+			result[songID] = append(result[songID], verseFromStorage(v))
+		}
+	}
+
+	return result, nil
 }
 
 type FetchVersesRequest struct {
@@ -124,6 +291,14 @@ type FetchVersesRequest struct {
 type FetchVersesResponse struct {
 	Verses     []VerseSmall `json:"verses"`
 	TotalCount int          `json:"total_count"`
+	Version    int          `json:"version"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+	Links      *Links       `json:"links,omitempty"`
+}
+
+// VersesLimit returns page size of FetchVerses for requested limit
+func VersesLimit(limit int) int {
+	return database.VersesLimit(limit)
 }
 
 func (s *Service) FetchVerses(ctx context.Context, request FetchVersesRequest) (*FetchVersesResponse, error) {
@@ -131,6 +306,19 @@ func (s *Service) FetchVerses(ctx context.Context, request FetchVersesRequest) (
 		s.log.Info("service.FetchVerses | request data", "request", request)
 	}
 
+	song, err := s.storage.GetSong(ctx, request.SongID)
+	if err != nil {
+		s.log.Error("service.FetchVerses: GetSong", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrSongNotFound
+		default:
+			return nil, ErrRequest
+		}
+	}
+
 	verses, err := s.storage.GetVerses(ctx, database.GetVersesRequest{
 		SongID: request.SongID,
 		Limit:  request.Limit,
@@ -159,10 +347,7 @@ func (s *Service) FetchVerses(ctx context.Context, request FetchVersesRequest) (
 
 	var vs []VerseSmall
 	for _, v := range verses {
-		vs = append(vs, VerseSmall{
-			VerseNumber: v.VerseNumber,
-			VerseText:   v.VerseText,
-		})
+		vs = append(vs, verseFromStorage(v))
 	}
 
 	if s.debug {
@@ -172,9 +357,60 @@ func (s *Service) FetchVerses(ctx context.Context, request FetchVersesRequest) (
 	return &FetchVersesResponse{
 		Verses:     vs,
 		TotalCount: songVersesCount,
+		Version:    song.Version,
+		UpdatedAt:  song.UpdatedAt,
 	}, nil
 }
 
+type FetchSongRequest struct {
+	SongID        int  `json:"song_id"`
+	IncludeVerses bool `json:"include_verses"`
+}
+
+func (s *Service) FetchSong(ctx context.Context, request FetchSongRequest) (*Song, error) {
+	if s.debug {
+		s.log.Info("service.FetchSong | request data", "request", request)
+	}
+
+	dbSong, err := s.storage.GetSong(ctx, request.SongID)
+	if err != nil {
+		s.log.Error("service.FetchSong: GetSong", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrSongNotFound
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	song := songFromStorage(*dbSong)
+
+	if request.IncludeVerses {
+		verses, err := s.storage.GetSongsVerses(ctx, []int{song.ID}, 0)
+		if err != nil {
+			s.log.Error("service.FetchSong: GetSongsVerses", "error", err.Error())
+			switch {
+			case errors.Is(err, context.DeadlineExceeded):
+				return nil, ErrTimeOut
+			default:
+				return nil, ErrRequest
+			}
+		}
+		song.Verses = make([]VerseSmall, 0, len(verses[song.ID]))
+		for _, v := range verses[song.ID] {
+			song.Verses = append(song.Verses, verseFromStorage(v))
+		}
+	}
+
+	if s.debug {
+		s.log.Info("service.FetchSong | response data", "song", song)
+	}
+
+	return &song, nil
+}
+
 type DeleteSongRequest struct {
 	SongID int `json:"song_id"`
 }
@@ -206,6 +442,8 @@ func (s *Service) DeleteSong(ctx context.Context, request DeleteSongRequest) (*D
 
 	reponse.Success = true
 
+	s.wakeRelay()
+
 	if s.debug {
 		s.log.Info("service.DeleteSong | response data", "success", reponse.Success)
 	}
@@ -214,15 +452,19 @@ func (s *Service) DeleteSong(ctx context.Context, request DeleteSongRequest) (*D
 }
 
 type UpdateSongRequest struct {
-	SongID      int        `json:"song_id"`
-	GroupName   *string    `json:"group_id"`
-	SongName    *string    `json:"song_name,omitempty"`
-	ReleaseDate *time.Time `json:"release_date,omitempty"`
-	Link        *string    `json:"link,omitempty"`
+	SongID      int             `json:"song_id"`
+	GroupName   *string         `json:"group_name,omitempty"`
+	SongName    *string         `json:"song_name,omitempty"`
+	ReleaseDate *dateparse.Date `json:"release_date,omitempty"`
+	Link        *string         `json:"link,omitempty"`
+	Version     *int            `json:"version,omitempty"`
 }
 
+// UpdateSongResponse holds new version, Song is changed song read with the update
 type UpdateSongResponse struct {
 	Success bool `json:"success"`
+	Version int  `json:"version"`
+	Song    Song `json:"-"`
 }
 
 func (s *Service) UpdateSong(ctx context.Context, request UpdateSongRequest) (UpdateSongResponse, error) {
@@ -231,10 +473,16 @@ func (s *Service) UpdateSong(ctx context.Context, request UpdateSongRequest) (Up
 	}
 	var result UpdateSongResponse
 	songParam := database.UpdateSongRequest{
-		SongID:      request.SongID,
-		SongName:    request.SongName,
-		ReleaseDate: request.ReleaseDate,
-		Link:        request.Link,
+		SongID:   request.SongID,
+		SongName: request.SongName,
+		Link:     request.Link,
+		Version:  request.Version,
+	}
+
+	if request.ReleaseDate != nil {
+		precision := string(request.ReleaseDate.Precision)
+		songParam.ReleaseDate = &request.ReleaseDate.Time
+		songParam.ReleaseDatePrecision = &precision
 	}
 
 	if request.GroupName != nil {
@@ -253,7 +501,7 @@ func (s *Service) UpdateSong(ctx context.Context, request UpdateSongRequest) (Up
 
 	}
 
-	err := s.storage.UpdateSong(ctx, songParam)
+	song, err := s.storage.UpdateSong(ctx, songParam)
 	if err != nil {
 		s.log.Error("service.UpdateSong | UpdateSong", "error", err.Error())
 		switch {
@@ -261,11 +509,17 @@ func (s *Service) UpdateSong(ctx context.Context, request UpdateSongRequest) (Up
 			return result, ErrTimeOut
 		case errors.Is(err, sql.ErrNoRows):
 			return result, ErrSongNotFound
+		case errors.Is(err, database.ErrVersionMismatch):
+			return result, s.songVersionMismatch(ctx, request.SongID)
 		default:
 			return result, ErrRequest
 		}
 	}
 	result.Success = true
+	result.Version = song.Version
+	result.Song = songFromStorage(*song)
+
+	s.wakeRelay()
 
 	if s.debug {
 		s.log.Info("service.UpdateSong | response data", "success", result.Success)
@@ -274,14 +528,21 @@ func (s *Service) UpdateSong(ctx context.Context, request UpdateSongRequest) (Up
 	return result, err
 }
 
+// UpdateVerseRequest changes verse only when Version equals verse version
+// and SongVersion equals song version, nil versions are not checked
 type UpdateVerseRequest struct {
 	SongID      int    `json:"song_id"`
 	VerseNumber int    `json:"verse_number"`
 	VerseText   string `json:"verse_text"`
+	Version     *int   `json:"version,omitempty"`
+	SongVersion *int   `json:"song_version,omitempty"`
 }
 
 type UpdateVerseResponse struct {
-	Success bool `json:"success"`
+	Success     bool       `json:"success"`
+	Version     int        `json:"version"`
+	SongVersion int        `json:"song_version"`
+	Verse       VerseSmall `json:"verse"`
 }
 
 func (s *Service) UpdateVerse(ctx context.Context, request UpdateVerseRequest) (UpdateVerseResponse, error) {
@@ -289,10 +550,12 @@ func (s *Service) UpdateVerse(ctx context.Context, request UpdateVerseRequest) (
 		s.log.Info("service.UpdateVerse | request data", "request", request)
 	}
 	var result UpdateVerseResponse
-	err := s.storage.UpdateVerse(ctx, database.UpdateVerseRequest{
+	resp, err := s.storage.UpdateVerse(ctx, database.UpdateVerseRequest{
 		SongID:      request.SongID,
 		VerseNumber: request.VerseNumber,
 		VerseText:   request.VerseText,
+		Version:     request.Version,
+		SongVersion: request.SongVersion,
 	})
 	if err != nil {
 		s.log.Error("service.UpdateVerse | UpdateVerse", "error", err.Error())
@@ -302,11 +565,20 @@ func (s *Service) UpdateVerse(ctx context.Context, request UpdateVerseRequest) (
 			return result, ErrTimeOut
 		case errors.Is(err, sql.ErrNoRows):
 			return result, ErrSongNotFound
+		case errors.Is(err, database.ErrSongVersionMismatch):
+			return result, s.songVersionMismatch(ctx, request.SongID)
+		case errors.Is(err, database.ErrVersionMismatch):
+			return result, s.verseVersionMismatch(ctx, request.SongID, request.VerseNumber)
 		default:
 			return result, ErrRequest
 		}
 	}
 	result.Success = true
+	result.Version = resp.Verse.Version
+	result.SongVersion = resp.SongVersion
+	result.Verse = verseFromStorage(resp.Verse)
+
+	s.wakeRelay()
 
 	if s.debug {
 		s.log.Info("service.UpdateVerse | response data", "success", result.Success)
@@ -315,6 +587,42 @@ func (s *Service) UpdateVerse(ctx context.Context, request UpdateVerseRequest) (
 	return result, err
 }
 
+// songVersionMismatch loads current song for VersionMismatchError
+func (s *Service) songVersionMismatch(ctx context.Context, songID int) error {
+	song, err := s.storage.GetSong(ctx, songID)
+	if err != nil {
+		s.log.Error("service.songVersionMismatch | GetSong", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrSongNotFound
+		default:
+			return ErrRequest
+		}
+	}
+	current := songFromStorage(*song)
+	return &VersionMismatchError{Song: &current}
+}
+
+// verseVersionMismatch loads current verse for VersionMismatchError
+func (s *Service) verseVersionMismatch(ctx context.Context, songID int, verseNumber int) error {
+	verse, err := s.storage.GetVerse(ctx, songID, verseNumber)
+	if err != nil {
+		s.log.Error("service.verseVersionMismatch | GetVerse", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrSongNotFound
+		default:
+			return ErrRequest
+		}
+	}
+	current := verseFromStorage(*verse)
+	return &VersionMismatchError{Verse: &current}
+}
+
 type NewSongRequest struct {
 	GroupName string `json:"group"`
 	SongName  string `json:"song"`
@@ -325,47 +633,157 @@ func (s *Service) NewSong(ctx context.Context, request NewSongRequest) (*Song, e
 		s.log.Info("service.NewSong | request data", "request", request)
 	}
 
-	songInfo, err := s.songSrv.FetchSongInfo(songinfo.FetchSongInfoParam{
+	songInfo, err := s.songSrv.FetchSongInfo(ctx, songinfo.FetchSongInfoParam{
 		GroupName: request.GroupName,
 		SongName:  request.SongName,
 	})
 	if err != nil {
 		s.log.Error("service.NewSong | FetchSongInfo", "error", err.Error())
-		return nil, err
+		switch {
+		case errors.Is(err, context.DeadlineExceeded), errors.Is(err, songinfo.ErrTimeOut):
+			return nil, ErrTimeOut
+		case errors.Is(err, songinfo.ErrGroupNameRequired):
+			return nil, problem.Validation(problem.FieldError{Field: "group", Message: err.Error()})
+		case errors.Is(err, songinfo.ErrSongNameRequired):
+			return nil, problem.Validation(problem.FieldError{Field: "song", Message: err.Error()})
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrInfoProvider, err.Error())
+		}
 	}
 
-	releaseDate, err := time.Parse("02.01.2006", songInfo.ReleaseDate)
-	if err != nil {
+	var releaseDate *time.Time
+	var precision string
+	date, err := dateparse.Parse(songInfo.ReleaseDate)
+	switch {
+	case errors.Is(err, dateparse.ErrEmpty):
+		s.log.Warn("service.NewSong | empty release date",
+			"group", request.GroupName,
+			"song", request.SongName)
+	case err != nil:
 		s.log.Warn("service.NewSong | parse release date", "Error", err.Error())
 		return nil, ErrBadDataFormat
+	default:
+		releaseDate = &date.Time
+		precision = string(date.Precision)
 	}
-	fullText := strings.ReplaceAll(songInfo.Text, "\\n", "\n")
-	verseSplit := strings.Split(fullText, "\n\n")
+	verses := splitVerses(songInfo.Text)
 
-	var verses []database.VerseSmall
+	if s.debug {
+		s.log.Info("service.NewSong | Verses info", "verses", verses)
+	}
+
+	song, err := s.addSong(ctx, database.AddSongRequest{
+		GroupName:            request.GroupName,
+		SongName:             request.SongName,
+		ReleaseDate:          releaseDate,
+		ReleaseDatePrecision: precision,
+		Verses:               verses,
+		Link:                 songInfo.Link,
+	})
+	if err != nil {
+		s.log.Error("service.NewSong | CreateSong", "error", err.Error())
+		return nil, err
+	}
+
+	if s.debug {
+		s.log.Info("service.NewSong | response data", "song", song)
+	}
+
+	return song, nil
+}
+
+// maxNameLength is size of group, song and link columns
+const maxNameLength = 255
+
+type CreateSongRequest struct {
+	GroupName   string          `json:"group"`
+	SongName    string          `json:"song"`
+	ReleaseDate *dateparse.Date `json:"release_date,omitempty"`
+	Link        string          `json:"link,omitempty"`
+	Text        string          `json:"text,omitempty"`
+	Verses      []string        `json:"verses,omitempty"`
+}
 
-	for idx, verse := range verseSplit {
-		if s.debug {
-			s.log.Info("service.NewSong | Verse info",
-				"verseIDX", idx,
-				"verseText", verse)
+// CreateSong stores song with lyrics from request without song info api.
+// Lyrics are taken from Verses or split from Text
+func (s *Service) CreateSong(ctx context.Context, request CreateSongRequest) (*Song, error) {
+	if s.debug {
+		s.log.Info("service.CreateSong | request data", "request", request)
+	}
+
+	addRequest, err := buildAddSongRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	song, err := s.addSong(ctx, addRequest)
+	if err != nil {
+		s.log.Error("service.CreateSong | CreateSong", "error", err.Error())
+		return nil, err
+	}
+
+	if s.debug {
+		s.log.Info("service.CreateSong | response data", "song", song)
+	}
+
+	return song, nil
+}
+
+// buildAddSongRequest validates song with lyrics and splits lyrics to verses
+func buildAddSongRequest(request CreateSongRequest) (database.AddSongRequest, error) {
+	var fields []problem.FieldError
+	if strings.TrimSpace(request.GroupName) == "" {
+		fields = append(fields, problem.FieldError{Field: "group", Message: "group is required"})
+	} else if len(request.GroupName) > maxNameLength {
+		fields = append(fields, problem.FieldError{Field: "group", Message: fmt.Sprintf("group is longer than %d", maxNameLength)})
+	}
+	if strings.TrimSpace(request.SongName) == "" {
+		fields = append(fields, problem.FieldError{Field: "song", Message: "song is required"})
+	} else if len(request.SongName) > maxNameLength {
+		fields = append(fields, problem.FieldError{Field: "song", Message: fmt.Sprintf("song is longer than %d", maxNameLength)})
+	}
+	if len(request.Link) > maxNameLength {
+		fields = append(fields, problem.FieldError{Field: "link", Message: fmt.Sprintf("link is longer than %d", maxNameLength)})
+	}
+	if len(request.Verses) > 0 && request.Text != "" {
+		fields = append(fields, problem.FieldError{Field: "text", Message: "use text or verses, not both"})
+	}
+	if len(request.Verses) == 0 && strings.TrimSpace(request.Text) == "" {
+		fields = append(fields, problem.FieldError{Field: "text", Message: "lyrics are required"})
+	}
+	if len(fields) > 0 {
+		return database.AddSongRequest{}, problem.Validation(fields...)
+	}
+
+	var verses []database.VerseSmall
+	if len(request.Verses) > 0 {
+		for idx, verse := range request.Verses {
+			verses = append(verses, database.VerseSmall{
+				VerseNumber: idx + 1,
+				VerseText:   verse,
+			})
 		}
+	} else {
+		verses = splitVerses(request.Text)
+	}
 
-		verses = append(verses, database.VerseSmall{
-			VerseNumber: idx + 1,
-			VerseText:   verse,
-		})
+	addRequest := database.AddSongRequest{
+		GroupName: request.GroupName,
+		SongName:  request.SongName,
+		Link:      request.Link,
+		Verses:    verses,
 	}
+	if request.ReleaseDate != nil {
+		addRequest.ReleaseDate = &request.ReleaseDate.Time
+		addRequest.ReleaseDatePrecision = string(request.ReleaseDate.Precision)
+	}
+	return addRequest, nil
+}
 
-	newSong, err := s.storage.AddSong(ctx, database.AddSongRequest{
-		GroupName:   request.GroupName,
-		SongName:    request.SongName,
-		ReleaseDate: releaseDate,
-		Verses:      verses,
-		Link:        songInfo.Link,
-	})
+// addSong stores song with verses and maps storage errors
+func (s *Service) addSong(ctx context.Context, request database.AddSongRequest) (*Song, error) {
+	newSong, err := s.storage.AddSong(ctx, request)
 	if err != nil {
-		s.log.Error("service.NewSong | CreateSong", "error", err.Error())
 		switch {
 		case errors.Is(err, context.DeadlineExceeded):
 			return nil, ErrTimeOut
@@ -376,17 +794,36 @@ func (s *Service) NewSong(ctx context.Context, request NewSongRequest) (*Song, e
 		}
 	}
 
-	song := Song{
-		ID:          int(newSong.SongID),
-		GroupName:   request.GroupName,
-		SongName:    request.SongName,
-		ReleaseDate: releaseDate,
-		Link:        songInfo.Link,
+	song := &Song{
+		ID:                   int(newSong.SongID),
+		GroupName:            request.GroupName,
+		SongName:             request.SongName,
+		ReleaseDate:          request.ReleaseDate,
+		ReleaseDatePrecision: request.ReleaseDatePrecision,
+		Link:                 request.Link,
+		Version:              1,
+		CreatedAt:            newSong.CreatedAt,
+		UpdatedAt:            newSong.CreatedAt,
 	}
 
-	if s.debug {
-		s.log.Info("service.NewSong | response data", "song", song)
-	}
+	s.wakeRelay()
+	return song, nil
+}
 
-	return &song, nil
+// normalizeText replaces escaped line breaks sent by song info api
+func normalizeText(text string) string {
+	return strings.ReplaceAll(text, "\\n", "\n")
+}
+
+// splitVerses splits song text to verses by empty line
+func splitVerses(text string) []database.VerseSmall {
+	var verses []database.VerseSmall
+
+	for idx, verse := range strings.Split(normalizeText(text), "\n\n") {
+		verses = append(verses, database.VerseSmall{
+			VerseNumber: idx + 1,
+			VerseText:   verse,
+		})
+	}
+	return verses
 }
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+// SortFields are song list fields allowed in sort parameter
+var SortFields = []string{"id", "group", "song", "releaseDate", "createdAt", "updatedAt"}
+
+var ErrBadCursor = problem.Validation(problem.FieldError{
+	Field:   "cursor",
+	Message: "cursor is malformed or was issued for another sort",
+})
+
+// SortKey is one song list ordering key
+type SortKey struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// ParseSort parses comma separated sort fields, "-" prefix means descending order,
+// for example group,-releaseDate,song
+func ParseSort(value string) ([]SortKey, error) {
+	var keys []SortKey
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		key := SortKey{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !database.IsSongSortField(key.Field) {
+			return nil, problem.Validation(problem.FieldError{
+				Field:   "sort",
+				Message: fmt.Sprintf("unknown sort field %q, allowed: %s", key.Field, strings.Join(SortFields, ", ")),
+			})
+		}
+		if seen[key.Field] {
+			return nil, problem.Validation(problem.FieldError{
+				Field:   "sort",
+				Message: fmt.Sprintf("sort field %q is repeated", key.Field),
+			})
+		}
+		seen[key.Field] = true
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func sortStorage(keys []SortKey) []database.SortKey {
+	if len(keys) == 0 {
+		return database.DefaultSongSort
+	}
+	result := make([]database.SortKey, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, database.SortKey{Field: key.Field, Desc: key.Desc})
+	}
+	return result
+}
+
+func formatSort(keys []database.SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Desc {
+			parts = append(parts, "-"+key.Field)
+		} else {
+			parts = append(parts, key.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// songCursor is opaque cursor payload, Sort binds cursor to the order it was issued for
+type songCursor struct {
+	Sort   string `json:"s"`
+	Values []any  `json:"v"`
+	SongID int    `json:"id"`
+}
+
+// encodeCursor returns cursor pointing after song
+func encodeCursor(keys []database.SortKey, song database.Song) string {
+	after := database.CursorAfter(song, keys)
+	data, _ := json.Marshal(songCursor{Sort: formatSort(keys), Values: after.Values, SongID: after.SongID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string, keys []database.SortKey) (*database.SongCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrBadCursor
+	}
+	var cursor songCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, ErrBadCursor
+	}
+	if cursor.Sort != formatSort(keys) || len(cursor.Values) != len(keys) {
+		return nil, ErrBadCursor
+	}
+	return &database.SongCursor{Values: cursor.Values, SongID: cursor.SongID}, nil
+}
@@ -0,0 +1,238 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/connector/songinfo"
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/dateparse"
+)
+
+const (
+	SyncModeReport = "report"
+	SyncModeApply  = "apply"
+)
+
+type SyncSongsRequest struct {
+	Mode      string `json:"mode"`
+	BatchSize uint64 `json:"batch_size"`
+	ReportDir string `json:"report_dir"`
+}
+
+type SyncSongsResponse struct {
+	Checked    int    `json:"checked"`
+	Changed    int    `json:"changed"`
+	Applied    int    `json:"applied"`
+	Failed     int    `json:"failed"`
+	ReportFile string `json:"report_file,omitempty"`
+}
+
+type FieldChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// SongDiff is one line of sync report
+type SongDiff struct {
+	SongID    int                    `json:"song_id"`
+	GroupName string                 `json:"group"`
+	SongName  string                 `json:"song"`
+	Changes   map[string]FieldChange `json:"changes"`
+}
+
+// SyncSongs re-queries song info api for every song in batches and compares link, releaseDate and text.
+// In apply mode changes are saved, in report mode they are written to ndjson file in ReportDir
+func (s *Service) SyncSongs(ctx context.Context, request SyncSongsRequest) (*SyncSongsResponse, error) {
+	if s.debug {
+		s.log.Info("service.SyncSongs | request data", "request", request)
+	}
+
+	if request.Mode != SyncModeApply && request.Mode != SyncModeReport {
+		return nil, fmt.Errorf("unknown sync mode %q", request.Mode)
+	}
+	if request.BatchSize == 0 {
+		request.BatchSize = 50
+	}
+
+	var result SyncSongsResponse
+	var report *json.Encoder
+	startedAt := time.Now()
+	afterID := 0
+
+	for {
+		songs, err := s.storage.ListSongsForSync(ctx, database.ListSongsForSyncRequest{
+			AfterID:      afterID,
+			SyncedBefore: startedAt,
+			Limit:        request.BatchSize,
+		})
+		if err != nil {
+			s.log.Error("service.SyncSongs | ListSongsForSync", "error", err.Error())
+			return &result, ErrRequest
+		}
+
+		for _, song := range songs {
+			afterID = song.SongID
+			result.Checked++
+
+			diff, update, err := s.diffSong(ctx, song)
+			if err != nil {
+				s.log.Warn("service.SyncSongs | diffSong",
+					"song_id", song.SongID,
+					"error", err.Error())
+				result.Failed++
+				continue
+			}
+
+			if len(diff.Changes) > 0 {
+				result.Changed++
+				s.log.Info("service.SyncSongs | song changed", "diff", diff)
+
+				if request.Mode == SyncModeReport {
+					if report == nil {
+						file, err := createReportFile(request.ReportDir, startedAt)
+						if err != nil {
+							s.log.Error("service.SyncSongs | createReportFile", "error", err.Error())
+							return &result, err
+						}
+						defer file.Close()
+						result.ReportFile = file.Name()
+						report = json.NewEncoder(file)
+					}
+					if err := report.Encode(diff); err != nil {
+						s.log.Error("service.SyncSongs | report.Encode", "error", err.Error())
+						return &result, err
+					}
+					//store sync time only
+					update = database.SyncSongRequest{SongID: song.SongID}
+				}
+			}
+
+			if err := s.storage.SyncSong(ctx, update); err != nil {
+				s.log.Error("service.SyncSongs | SyncSong",
+					"song_id", song.SongID,
+					"error", err.Error())
+				result.Failed++
+				continue
+			}
+
+			if request.Mode == SyncModeApply && len(diff.Changes) > 0 {
+				result.Applied++
+				s.wakeRelay()
+			}
+		}
+
+		if uint64(len(songs)) < request.BatchSize {
+			break
+		}
+	}
+
+	s.log.Info("service.SyncSongs | finished", "result", result)
+
+	return &result, nil
+}
+
+// diffSong compares stored song with song info api response
+func (s *Service) diffSong(ctx context.Context, song database.Song) (SongDiff, database.SyncSongRequest, error) {
+	diff := SongDiff{
+		SongID:    song.SongID,
+		GroupName: song.GroupName,
+		SongName:  song.SongName,
+		Changes:   make(map[string]FieldChange),
+	}
+	update := database.SyncSongRequest{SongID: song.SongID}
+
+	info, err := s.songSrv.FetchSongInfo(ctx, songinfo.FetchSongInfoParam{
+		GroupName: song.GroupName,
+		SongName:  song.SongName,
+	})
+	if err != nil {
+		return diff, update, err
+	}
+
+	if info.Link != "" && info.Link != song.Link {
+		diff.Changes["link"] = FieldChange{Old: song.Link, New: info.Link}
+		update.Link = &info.Link
+	}
+
+	if info.ReleaseDate != "" {
+		date, err := dateparse.Parse(info.ReleaseDate)
+		if err != nil {
+			s.log.Warn("service.diffSong | parse release date",
+				"song_id", song.SongID,
+				"error", err.Error())
+		} else if song.ReleaseDate == nil ||
+			!date.Time.Equal(*song.ReleaseDate) ||
+			string(date.Precision) != song.ReleaseDatePrecision {
+			var old string
+			if song.ReleaseDate != nil {
+				old = dateparse.Date{
+					Time:      *song.ReleaseDate,
+					Precision: dateparse.Precision(song.ReleaseDatePrecision),
+				}.Format()
+			}
+			diff.Changes["releaseDate"] = FieldChange{Old: old, New: date.Format()}
+			precision := string(date.Precision)
+			update.ReleaseDate = &date.Time
+			update.ReleaseDatePrecision = &precision
+		}
+	}
+
+	if info.Text != "" {
+		verses, err := s.storage.GetAllVerses(ctx, song.SongID)
+		if err != nil {
+			return diff, update, err
+		}
+
+		stored := make([]string, 0, len(verses))
+		for _, v := range verses {
+			stored = append(stored, v.VerseText)
+		}
+		oldText := strings.Join(stored, "\n\n")
+		newText := normalizeText(info.Text)
+
+		if oldText != newText {
+			diff.Changes["text"] = FieldChange{Old: oldText, New: newText}
+			update.Verses = splitVerses(info.Text)
+		}
+	}
+
+	return diff, update, nil
+}
+
+func createReportFile(dir string, startedAt time.Time) (*os.File, error) {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("sync-%s.ndjson", startedAt.UTC().Format("20060102-150405"))
+	return os.Create(filepath.Join(dir, name))
+}
+
+// StartSync runs SyncSongs every interval until ctx is done
+func (s *Service) StartSync(ctx context.Context, interval time.Duration, request SyncSongsRequest) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.SyncSongs(ctx, request); err != nil {
+					s.log.Error("service.StartSync | SyncSongs", "error", err.Error())
+				}
+			}
+		}
+	}()
+
+	s.log.Info("service.StartSync | song sync scheduled", "interval", interval.String(), "mode", request.Mode)
+}
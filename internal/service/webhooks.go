@@ -0,0 +1,382 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	mrand "math/rand/v2"
+	"net/url"
+	"time"
+
+	"github.com/Vic07Region/musicLibrary/internal/connector/webhook"
+	"github.com/Vic07Region/musicLibrary/internal/database"
+	"github.com/Vic07Region/musicLibrary/internal/lib/problem"
+)
+
+const (
+	webhookSecretBytes = 32
+	webhookBackoffBase = 30 * time.Second
+	webhookBackoffMax  = time.Hour
+	//webhookLeaseMargin is added to send timeout, so a delivery is reclaimed only when its worker is gone
+	webhookLeaseMargin = time.Minute
+
+	DefaultWebhookMaxAttempts = 8
+)
+
+var (
+	ErrWebhookNotFound       = problem.New(problem.CodeWebhookNotFound, "webhook is not found")
+	ErrDeliveryNotFound      = problem.New(problem.CodeDeliveryNotFound, "webhook delivery is not found")
+	ErrDeliveryNotFailed     = problem.New(problem.CodeDeliveryNotFailed, "only failed deliveries can be replayed")
+	ErrWebhookURL            = problem.Validation(problem.FieldError{Field: "url", Message: "url must be absolute http or https url"})
+	ErrWebhookPrivateURL     = problem.Validation(problem.FieldError{Field: "url", Message: "url must not point to private, loopback or link-local address"})
+	ErrUnknownDeliveryStatus = problem.Validation(problem.FieldError{Field: "status", Message: "unknown status, use pending, running, delivered or failed"})
+)
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	EventTypes []string `json:"event_types,omitempty"`
+}
+
+// CreateWebhook stores subscription, secret is generated when not given
+// and is returned only here
+func (s *Service) CreateWebhook(ctx context.Context, request CreateWebhookRequest) (*Webhook, error) {
+	if s.debug {
+		s.log.Info("service.CreateWebhook | request data", "url", request.URL, "event_types", request.EventTypes)
+	}
+
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, ErrWebhookURL
+	}
+	if err := s.hooks.CheckURL(request.URL); err != nil {
+		return nil, ErrWebhookPrivateURL
+	}
+
+	for _, eventType := range request.EventTypes {
+		if !IsEventType(eventType) {
+			return nil, problem.Validation(problem.FieldError{
+				Field:   "event_types",
+				Message: fmt.Sprintf("unknown event type %q, allowed: %v", eventType, EventTypes),
+			})
+		}
+	}
+
+	if request.Secret == "" {
+		secret := make([]byte, webhookSecretBytes)
+		if _, err := rand.Read(secret); err != nil {
+			s.log.Error("service.CreateWebhook | rand.Read", "error", err.Error())
+			return nil, ErrRequest
+		}
+		request.Secret = hex.EncodeToString(secret)
+	}
+
+	dbWebhook, err := s.storage.CreateWebhook(ctx, database.CreateWebhookRequest{
+		URL:        request.URL,
+		Secret:     request.Secret,
+		EventTypes: request.EventTypes,
+	})
+	if err != nil {
+		s.log.Error("service.CreateWebhook | CreateWebhook", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := webhookFromStorage(*dbWebhook)
+	result.Secret = dbWebhook.Secret
+	return &result, nil
+}
+
+func (s *Service) FetchWebhooks(ctx context.Context) ([]Webhook, error) {
+	webhooks, err := s.storage.GetWebhooks(ctx)
+	if err != nil {
+		s.log.Error("service.FetchWebhooks | GetWebhooks", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make([]Webhook, 0, len(webhooks))
+	for _, item := range webhooks {
+		result = append(result, webhookFromStorage(item))
+	}
+	return result, nil
+}
+
+type DeleteWebhookRequest struct {
+	WebhookID int `json:"webhook_id"`
+}
+
+func (s *Service) DeleteWebhook(ctx context.Context, request DeleteWebhookRequest) error {
+	if s.debug {
+		s.log.Info("service.DeleteWebhook | request data", "request", request)
+	}
+
+	if err := s.storage.DeleteWebhook(ctx, request.WebhookID); err != nil {
+		s.log.Error("service.DeleteWebhook | DeleteWebhook", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrWebhookNotFound
+		default:
+			return ErrRequest
+		}
+	}
+	return nil
+}
+
+type FetchWebhookDeliveriesRequest struct {
+	WebhookID int     `json:"webhook_id"`
+	Status    *string `json:"status,omitempty"`
+	Limit     uint64  `json:"limit"`
+	Offset    uint64  `json:"offset"`
+}
+
+// FetchWebhookDeliveries returns delivery log of webhook, newest first
+func (s *Service) FetchWebhookDeliveries(ctx context.Context, request FetchWebhookDeliveriesRequest) ([]WebhookDelivery, error) {
+	if s.debug {
+		s.log.Info("service.FetchWebhookDeliveries | request data", "request", request)
+	}
+
+	if request.Status != nil {
+		switch *request.Status {
+		case database.DeliveryStatusPending, database.DeliveryStatusRunning,
+			database.DeliveryStatusDelivered, database.DeliveryStatusFailed:
+		default:
+			return nil, ErrUnknownDeliveryStatus
+		}
+	}
+
+	if _, err := s.storage.GetWebhook(ctx, request.WebhookID); err != nil {
+		s.log.Error("service.FetchWebhookDeliveries | GetWebhook", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrWebhookNotFound
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	deliveries, err := s.storage.GetWebhookDeliveries(ctx, database.GetWebhookDeliveriesRequest{
+		WebhookID: request.WebhookID,
+		Status:    request.Status,
+		Limit:     request.Limit,
+		Offset:    request.Offset,
+	})
+	if err != nil {
+		s.log.Error("service.FetchWebhookDeliveries | GetWebhookDeliveries", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	result := make([]WebhookDelivery, 0, len(deliveries))
+	for _, item := range deliveries {
+		result = append(result, deliveryFromStorage(item))
+	}
+	return result, nil
+}
+
+type ReplayWebhookDeliveryRequest struct {
+	WebhookID  int   `json:"webhook_id"`
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+// ReplayWebhookDelivery queues a new delivery with payload of failed one
+func (s *Service) ReplayWebhookDelivery(ctx context.Context, request ReplayWebhookDeliveryRequest) (*WebhookDelivery, error) {
+	if s.debug {
+		s.log.Info("service.ReplayWebhookDelivery | request data", "request", request)
+	}
+
+	delivery, err := s.storage.GetWebhookDelivery(ctx, request.DeliveryID)
+	if err != nil {
+		s.log.Error("service.ReplayWebhookDelivery | GetWebhookDelivery", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrDeliveryNotFound
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	if delivery.WebhookID != request.WebhookID {
+		return nil, ErrDeliveryNotFound
+	}
+	if delivery.Status != database.DeliveryStatusFailed {
+		return nil, ErrDeliveryNotFailed
+	}
+
+	replay, err := s.storage.ReplayWebhookDelivery(ctx, request.DeliveryID)
+	if err != nil {
+		s.log.Error("service.ReplayWebhookDelivery | ReplayWebhookDelivery", "error", err.Error())
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, ErrTimeOut
+		default:
+			return nil, ErrRequest
+		}
+	}
+
+	select {
+	case s.hookWake <- struct{}{}:
+	default:
+	}
+
+	result := deliveryFromStorage(*replay)
+	return &result, nil
+}
+
+// StartWebhookWorkers starts pool of workers sending queued webhook deliveries until ctx is done.
+// Failed attempts are retried with exponential backoff, after maxAttempts delivery is failed.
+// Claimed delivery is leased for sendTimeout with a margin, deliveries of dead workers are sent again
+func (s *Service) StartWebhookWorkers(ctx context.Context, workers int, pollInterval, sendTimeout time.Duration, maxAttempts int) error {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultWebhookMaxAttempts
+	}
+	lease := sendTimeout + webhookLeaseMargin
+
+	for i := 0; i < workers; i++ {
+		go s.webhookWorker(ctx, i, pollInterval, lease, maxAttempts)
+	}
+
+	s.log.Info("service.StartWebhookWorkers | workers started", "workers", workers)
+	return nil
+}
+
+func (s *Service) webhookWorker(ctx context.Context, num int, pollInterval, lease time.Duration, maxAttempts int) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		delivery, err := s.storage.ClaimWebhookDelivery(ctx, lease)
+		if err == nil {
+			s.deliver(ctx, num, delivery, maxAttempts)
+			continue
+		}
+
+		if !errors.Is(err, sql.ErrNoRows) {
+			s.log.Error("service.webhookWorker | ClaimWebhookDelivery", "worker", num, "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.hookWake:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) deliver(ctx context.Context, num int, delivery *database.WebhookDelivery, maxAttempts int) {
+	finish := database.FinishWebhookDeliveryRequest{
+		DeliveryID: delivery.DeliveryID,
+		Status:     database.DeliveryStatusDelivered,
+	}
+
+	hook, err := s.storage.GetWebhook(ctx, delivery.WebhookID)
+	switch {
+	case err != nil && ctx.Err() != nil:
+		//delivery is interrupted by shutdown, not failed
+		s.releaseWebhookDelivery(num, delivery.DeliveryID)
+		return
+	case err != nil:
+		reason := err.Error()
+		finish.Status = database.DeliveryStatusFailed
+		finish.Error = &reason
+	case !hook.Active:
+		reason := "webhook is disabled"
+		finish.Status = database.DeliveryStatusFailed
+		finish.Error = &reason
+	default:
+		result, err := s.hooks.Send(ctx, webhook.SendRequest{
+			URL:        hook.URL,
+			Secret:     hook.Secret,
+			EventType:  delivery.EventType,
+			DeliveryID: delivery.DeliveryID,
+			Body:       delivery.Payload,
+		})
+		if err != nil && result == nil && ctx.Err() != nil {
+			//delivery is interrupted by shutdown, not failed
+			s.releaseWebhookDelivery(num, delivery.DeliveryID)
+			return
+		}
+		if result != nil {
+			finish.ResponseStatus = &result.StatusCode
+		}
+		if err != nil {
+			reason := err.Error()
+			finish.Error = &reason
+			finish.Status = database.DeliveryStatusFailed
+
+			attempt := delivery.Attempts + 1
+			if attempt < maxAttempts {
+				next := time.Now().Add(webhookBackoff(attempt))
+				finish.Status = database.DeliveryStatusPending
+				finish.NextAttemptAt = &next
+			}
+		}
+	}
+
+	//result is stored even when process is stopping, ctx may be already canceled
+	storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+	defer cancel()
+
+	if err := s.storage.FinishWebhookDelivery(storeCtx, finish); err != nil {
+		s.log.Error("service.deliver | FinishWebhookDelivery", "worker", num, "delivery_id", delivery.DeliveryID, "error", err.Error())
+		return
+	}
+
+	if finish.Status == database.DeliveryStatusFailed {
+		s.log.Warn("service.deliver | delivery failed", "worker", num, "delivery_id", delivery.DeliveryID, "error", *finish.Error)
+	}
+
+	if s.debug {
+		s.log.Info("service.deliver | finish", "worker", num, "delivery_id", delivery.DeliveryID, "status", finish.Status)
+	}
+}
+
+// releaseWebhookDelivery returns delivery to the queue, it is sent by next running worker
+func (s *Service) releaseWebhookDelivery(num int, deliveryID int64) {
+	ctx, cancel := context.WithTimeout(context.Background(), finishTimeout)
+	defer cancel()
+
+	if err := s.storage.ReleaseWebhookDelivery(ctx, deliveryID); err != nil {
+		s.log.Error("service.releaseWebhookDelivery | ReleaseWebhookDelivery", "worker", num, "delivery_id", deliveryID, "error", err.Error())
+	}
+}
+
+// webhookBackoff returns delay before next attempt: 30s doubled for every
+// failed attempt up to an hour, with up to 10% jitter
+func webhookBackoff(attempt int) time.Duration {
+	return retryBackoff(attempt, webhookBackoffBase, webhookBackoffMax)
+}
+
+// retryBackoff returns base delay doubled for every failed attempt up to limit, with up to 10% jitter
+func retryBackoff(attempt int, base, limit time.Duration) time.Duration {
+	delay := limit
+	if attempt < 1 {
+		attempt = 1
+	}
+	if shifted := base << (attempt - 1); attempt < 32 && shifted > 0 && shifted < limit {
+		delay = shifted
+	}
+	return delay + time.Duration(mrand.Int64N(int64(delay/10)+1))
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE songs ADD COLUMN release_date_precision VARCHAR(5);
+UPDATE songs SET release_date_precision = 'day' WHERE releaseDate IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE songs DROP COLUMN release_date_precision;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE songs ADD COLUMN version INT NOT NULL DEFAULT 1;
+ALTER TABLE verses ADD COLUMN version INT NOT NULL DEFAULT 1;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE verses DROP COLUMN version;
+ALTER TABLE songs DROP COLUMN version;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE songs ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE songs ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE verses ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT now();
+ALTER TABLE verses ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
+
+-- Indexes
+CREATE INDEX idx_songs_updated_at ON songs(updated_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_songs_updated_at;
+ALTER TABLE verses DROP COLUMN updated_at;
+ALTER TABLE verses DROP COLUMN created_at;
+ALTER TABLE songs DROP COLUMN updated_at;
+ALTER TABLE songs DROP COLUMN created_at;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Table: events
+CREATE TABLE events (
+    event_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    song_id INT NOT NULL,
+    group_name VARCHAR(255),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Indexes
+CREATE INDEX idx_events_song_id ON events(song_id, event_id);
+CREATE INDEX idx_events_group_name ON events(group_name, event_id);
+CREATE INDEX idx_events_created_at ON events(created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE events;
+-- +goose StatementEnd
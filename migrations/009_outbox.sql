@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+
+-- Table: outbox
+-- change events written in the same transaction as the change, published by relay
+CREATE TABLE outbox (
+    outbox_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL,
+    song_id INT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ
+);
+
+-- Indexes
+CREATE INDEX idx_outbox_pending ON outbox(song_id, outbox_id) WHERE published_at IS NULL;
+CREATE INDEX idx_outbox_published_at ON outbox(published_at) WHERE published_at IS NOT NULL;
+
+-- outbox event is published at least once, repeated publish must not duplicate rows
+ALTER TABLE events ADD COLUMN outbox_id BIGINT;
+CREATE UNIQUE INDEX idx_events_outbox_id ON events(outbox_id);
+
+ALTER TABLE webhook_deliveries ADD COLUMN outbox_id BIGINT;
+CREATE UNIQUE INDEX idx_webhook_deliveries_outbox_id ON webhook_deliveries(webhook_id, outbox_id) WHERE replay_of IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_webhook_deliveries_outbox_id;
+ALTER TABLE webhook_deliveries DROP COLUMN outbox_id;
+DROP INDEX idx_events_outbox_id;
+ALTER TABLE events DROP COLUMN outbox_id;
+DROP TABLE outbox;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- running job is owned by a worker until locked_until, expired jobs are claimed again
+ALTER TABLE jobs ADD COLUMN locked_until TIMESTAMPTZ;
+UPDATE jobs SET locked_until = now() WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs DROP COLUMN locked_until;
+-- +goose StatementEnd
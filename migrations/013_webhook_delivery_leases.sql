@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- running delivery is owned by a worker until locked_until, expired deliveries are claimed again
+ALTER TABLE webhook_deliveries ADD COLUMN locked_until TIMESTAMPTZ;
+UPDATE webhook_deliveries SET locked_until = now() WHERE status = 'running';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE webhook_deliveries DROP COLUMN locked_until;
+-- +goose StatementEnd
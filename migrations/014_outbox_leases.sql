@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- claimed event is owned by a relay until locked_until, it is published outside of claiming transaction
+ALTER TABLE outbox ADD COLUMN locked_until TIMESTAMPTZ;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE outbox DROP COLUMN locked_until;
+-- +goose StatementEnd
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- every claim of a job is a new attempt, only the worker holding the current attempt can finish or release it
+ALTER TABLE jobs ADD COLUMN attempt INT NOT NULL DEFAULT 0;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE jobs DROP COLUMN attempt;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- owner identifies execution of request holding the key, response of request whose lease was taken over
+-- by retry does not overwrite or release key of the retry
+ALTER TABLE idempotency_keys ADD COLUMN owner VARCHAR(64) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys DROP COLUMN owner;
+-- +goose StatementEnd